@@ -1,10 +1,57 @@
 package customerror
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 )
 
+// Redis error codes, the code is the first word of an error reply and lets
+// clients tell errors apart without parsing the message
+//
+// https://redis.io/docs/latest/develop/reference/protocol-spec/#simple-errors
+const (
+	ERR         = "ERR"
+	WRONGTYPE   = "WRONGTYPE"
+	NOSCRIPT    = "NOSCRIPT"
+	BUSY        = "BUSY"
+	BUSYKEY     = "BUSYKEY"
+	LOADING     = "LOADING"
+	READONLY    = "READONLY"
+	MOVED       = "MOVED"
+	ASK         = "ASK"
+	TRYAGAIN    = "TRYAGAIN"
+	CROSSSLOT   = "CROSSSLOT"
+	CLUSTERDOWN = "CLUSTERDOWN"
+	MASTERDOWN  = "MASTERDOWN"
+	NOREPLICAS  = "NOREPLICAS"
+	MISCONF     = "MISCONF"
+	OOM         = "OOM"
+	EXECABORT   = "EXECABORT"
+	NOAUTH      = "NOAUTH"
+	WRONGPASS   = "WRONGPASS"
+	NOPERM      = "NOPERM"
+	NOPROTO     = "NOPROTO"
+	NOGROUP     = "NOGROUP"
+	UNBLOCKED   = "UNBLOCKED"
+)
+
+type coder interface {
+	Code() string
+}
+
+// Code returns the Redis error code for err, errors that do not declare a
+// code are generic errors
+func Code(err error) string {
+	var c coder
+	if errors.As(err, &c) {
+		return c.Code()
+	}
+
+	return ERR
+}
+
 type InvalidNumberOfArgumentsError struct{}
 
 func (e InvalidNumberOfArgumentsError) Error() string {
@@ -23,6 +70,26 @@ func (e InvalidCharacterError) Error() string {
 	return "invalid character"
 }
 
+type ProtocolError struct {
+	Reason string
+}
+
+func (e ProtocolError) Error() string {
+	return fmt.Sprintf("Protocol error: %s", e.Reason)
+}
+
+// RDBError describes why a dump file could not be loaded, Offset is the
+// position in the file and Opcode the last opcode read before the failure
+type RDBError struct {
+	Offset int
+	Opcode byte
+	Reason string
+}
+
+func (e RDBError) Error() string {
+	return fmt.Sprintf("bad RDB file: %s at offset %d (opcode 0x%02X)", e.Reason, e.Offset, e.Opcode)
+}
+
 type InvalidRDBValueTypeError struct{}
 
 func (e InvalidRDBValueTypeError) Error() string {
@@ -41,6 +108,594 @@ func (e InvalidRedisCommandError) Error() string {
 	return "invalid redis command"
 }
 
+type InvalidProtocolVersionError struct{}
+
+func (e InvalidProtocolVersionError) Error() string {
+	return "Protocol version is not an integer or out of range"
+}
+
+type UnsupportedProtocolError struct{}
+
+func (e UnsupportedProtocolError) Error() string {
+	return "unsupported protocol version"
+}
+
+func (e UnsupportedProtocolError) Code() string {
+	return NOPROTO
+}
+
+type InvalidCredentialsError struct{}
+
+func (e InvalidCredentialsError) Error() string {
+	return "invalid username-password pair or user is disabled."
+}
+
+func (e InvalidCredentialsError) Code() string {
+	return WRONGPASS
+}
+
+type WrongTypeError struct{}
+
+func (e WrongTypeError) Error() string {
+	return "Operation against a key holding the wrong kind of value"
+}
+
+func (e WrongTypeError) Code() string {
+	return WRONGTYPE
+}
+
+type NoScriptError struct{}
+
+func (e NoScriptError) Error() string {
+	return "No matching script. Please use EVAL."
+}
+
+func (e NoScriptError) Code() string {
+	return NOSCRIPT
+}
+
+type BusyError struct{}
+
+func (e BusyError) Error() string {
+	return "Redis is busy running a script. You can only call SCRIPT KILL or SHUTDOWN NOSAVE."
+}
+
+func (e BusyError) Code() string {
+	return BUSY
+}
+
+type BusyKeyError struct{}
+
+func (e BusyKeyError) Error() string {
+	return "Target key name already exists."
+}
+
+func (e BusyKeyError) Code() string {
+	return BUSYKEY
+}
+
+type LoadingError struct{}
+
+func (e LoadingError) Error() string {
+	return "Redis is loading the dataset in memory"
+}
+
+func (e LoadingError) Code() string {
+	return LOADING
+}
+
+type ReadOnlyError struct{}
+
+func (e ReadOnlyError) Error() string {
+	return "You can't write against a read only replica."
+}
+
+func (e ReadOnlyError) Code() string {
+	return READONLY
+}
+
+type MovedError struct {
+	Slot int
+	Addr string
+}
+
+func (e MovedError) Error() string {
+	return fmt.Sprintf("%d %s", e.Slot, e.Addr)
+}
+
+func (e MovedError) Code() string {
+	return MOVED
+}
+
+type AskError struct {
+	Slot int
+	Addr string
+}
+
+func (e AskError) Error() string {
+	return fmt.Sprintf("%d %s", e.Slot, e.Addr)
+}
+
+func (e AskError) Code() string {
+	return ASK
+}
+
+type CrossSlotError struct{}
+
+func (e CrossSlotError) Error() string {
+	return "Keys in request don't hash to the same slot"
+}
+
+func (e CrossSlotError) Code() string {
+	return CROSSSLOT
+}
+
+type MisconfError struct{}
+
+func (e MisconfError) Error() string {
+	return "Errors writing to the AOF file or RDB snapshot are disabled."
+}
+
+func (e MisconfError) Code() string {
+	return MISCONF
+}
+
+type OutOfMemoryError struct{}
+
+func (e OutOfMemoryError) Error() string {
+	return "command not allowed when used memory > 'maxmemory'."
+}
+
+func (e OutOfMemoryError) Code() string {
+	return OOM
+}
+
+type ExecAbortError struct{}
+
+func (e ExecAbortError) Error() string {
+	return "Transaction discarded because of previous errors."
+}
+
+func (e ExecAbortError) Code() string {
+	return EXECABORT
+}
+
+type NoAuthError struct{}
+
+func (e NoAuthError) Error() string {
+	return "Authentication required."
+}
+
+func (e NoAuthError) Code() string {
+	return NOAUTH
+}
+
+type NoPermError struct {
+	Cmd string
+}
+
+func (e NoPermError) Error() string {
+	return fmt.Sprintf("User default has no permissions to run the '%s' command", e.Cmd)
+}
+
+func (e NoPermError) Code() string {
+	return NOPERM
+}
+
+type UnblockedError struct{}
+
+func (e UnblockedError) Error() string {
+	return "client unblocked via CLIENT UNBLOCK"
+}
+
+func (e UnblockedError) Code() string {
+	return UNBLOCKED
+}
+
+type InvalidClientNameError struct{}
+
+func (e InvalidClientNameError) Error() string {
+	return "Client names cannot contain spaces, newlines or special characters."
+}
+
+type NotIntegerError struct{}
+
+func (e NotIntegerError) Error() string {
+	return "value is not an integer or out of range"
+}
+
+type OffsetOutOfRangeError struct{}
+
+func (e OffsetOutOfRangeError) Error() string {
+	return "offset is out of range"
+}
+
+type StringTooLongError struct{}
+
+func (e StringTooLongError) Error() string {
+	return "string exceeds maximum allowed size (proto-max-bulk-len)"
+}
+
+type NoSuchKeyError struct{}
+
+func (e NoSuchKeyError) Error() string {
+	return "no such key"
+}
+
+type SameObjectError struct{}
+
+func (e SameObjectError) Error() string {
+	return "source and destination objects are the same"
+}
+
+type DBIndexOutOfRangeError struct{}
+
+func (e DBIndexOutOfRangeError) Error() string {
+	return "DB index is out of range"
+}
+
+type BitOffsetError struct{}
+
+func (e BitOffsetError) Error() string {
+	return "bit offset is not an integer or out of range"
+}
+
+type BitValueError struct{}
+
+func (e BitValueError) Error() string {
+	return "bit is not an integer or out of range"
+}
+
+type BitArgumentError struct{}
+
+func (e BitArgumentError) Error() string {
+	return "The bit argument must be 1 or 0."
+}
+
+type BitOpNotError struct{}
+
+func (e BitOpNotError) Error() string {
+	return "BITOP NOT must be called with a single source key."
+}
+
+type BitOpSourceKeysError struct{}
+
+func (e BitOpSourceKeysError) Error() string {
+	return "BITOP DIFF, DIFF1 and ANDOR must be called with at least two source keys."
+}
+
+type BitfieldTypeError struct{}
+
+func (e BitfieldTypeError) Error() string {
+	return "Invalid bitfield type. Use something like i16 u8. Note that u64 is not supported but i64 is."
+}
+
+type BitfieldOverflowError struct{}
+
+func (e BitfieldOverflowError) Error() string {
+	return "Invalid OVERFLOW type specified"
+}
+
+type BitfieldReadOnlyError struct{}
+
+func (e BitfieldReadOnlyError) Error() string {
+	return "BITFIELD_RO only supports the GET subcommand"
+}
+
+type LCSNotStringError struct{}
+
+func (e LCSNotStringError) Error() string {
+	return "The specified keys must contain string values"
+}
+
+type LCSLenAndIdxError struct{}
+
+func (e LCSLenAndIdxError) Error() string {
+	return "If you want both the length and indexes, please just use IDX."
+}
+
+type LCSTooLongError struct{}
+
+func (e LCSTooLongError) Error() string {
+	return "String too long for LCS"
+}
+
+type NotFloatError struct{}
+
+func (e NotFloatError) Error() string {
+	return "value is not a valid float"
+}
+
+type IncrOverflowError struct{}
+
+func (e IncrOverflowError) Error() string {
+	return "increment or decrement would overflow"
+}
+
+type DecrOverflowError struct{}
+
+func (e DecrOverflowError) Error() string {
+	return "decrement would overflow"
+}
+
+type NaNOrInfinityError struct{}
+
+func (e NaNOrInfinityError) Error() string {
+	return "increment would produce NaN or Infinity"
+}
+
+type InvalidTrackingOptionError struct {
+	Reason string
+}
+
+func (e InvalidTrackingOptionError) Error() string {
+	return e.Reason
+}
+
+type PubSubContextError struct {
+	Cmd string
+}
+
+func (e PubSubContextError) Error() string {
+	return fmt.Sprintf("Can't execute '%s': only (P|S)SUBSCRIBE / (P|S)UNSUBSCRIBE / PING / QUIT / RESET are allowed in this context", e.Cmd)
+}
+
+type InvalidExpireTimeError struct {
+	Cmd string
+}
+
+func (e InvalidExpireTimeError) Error() string {
+	return fmt.Sprintf("invalid expire time in '%s' command", e.Cmd)
+}
+
+type InvalidExpireOptionError struct {
+	Reason string
+}
+
+func (e InvalidExpireOptionError) Error() string {
+	return e.Reason
+}
+
+type InvalidCursorError struct{}
+
+func (e InvalidCursorError) Error() string {
+	return "invalid cursor"
+}
+
+type UnknownTypeNameError struct {
+	Name string
+}
+
+func (e UnknownTypeNameError) Error() string {
+	return fmt.Sprintf("unknown type name '%s'", e.Name)
+}
+
+type PositiveValueError struct{}
+
+func (e PositiveValueError) Error() string {
+	return "value is out of range, must be positive"
+}
+
+type IndexOutOfRangeError struct{}
+
+func (e IndexOutOfRangeError) Error() string {
+	return "index out of range"
+}
+
+type LPosRankError struct{}
+
+func (e LPosRankError) Error() string {
+	return "RANK can't be zero: use 1 to start from the first match, 2 from the second ... or use negative to start from the end of the list"
+}
+
+type NegativeOptionError struct {
+	Option string
+}
+
+func (e NegativeOptionError) Error() string {
+	return fmt.Sprintf("%s can't be negative", e.Option)
+}
+
+type NumKeysError struct{}
+
+func (e NumKeysError) Error() string {
+	return "numkeys should be greater than 0"
+}
+
+type NumKeysTooManyError struct{}
+
+func (e NumKeysTooManyError) Error() string {
+	return "Number of keys can't be greater than number of args"
+}
+
+type PositiveCountError struct{}
+
+func (e PositiveCountError) Error() string {
+	return "count should be greater than 0"
+}
+
+type ValueOutOfRangeError struct{}
+
+func (e ValueOutOfRangeError) Error() string {
+	return "value is out of range"
+}
+
+type HashValueNotIntegerError struct{}
+
+func (e HashValueNotIntegerError) Error() string {
+	return "hash value is not an integer"
+}
+
+type HashValueNotFloatError struct{}
+
+func (e HashValueNotFloatError) Error() string {
+	return "hash value is not a float"
+}
+
+type FieldsArgumentError struct{}
+
+func (e FieldsArgumentError) Error() string {
+	return "Mandatory argument FIELDS is missing or not at the right position"
+}
+
+type NumFieldsError struct{}
+
+func (e NumFieldsError) Error() string {
+	return "Parameter `numFields` should be greater than 0"
+}
+
+type NumFieldsMismatchError struct{}
+
+func (e NumFieldsMismatchError) Error() string {
+	return "The `numfields` parameter must match the number of arguments"
+}
+
+type NegativeExpireTimeError struct{}
+
+func (e NegativeExpireTimeError) Error() string {
+	return "invalid expire time, must be >= 0"
+}
+
+type ExclusiveOptionsError struct {
+	Options string
+}
+
+func (e ExclusiveOptionsError) Error() string {
+	return fmt.Sprintf("Only one of %s arguments can be specified", e.Options)
+}
+
+type XXAndNXError struct{}
+
+func (e XXAndNXError) Error() string {
+	return "XX and NX options at the same time are not compatible"
+}
+
+type GTLTAndNXError struct{}
+
+func (e GTLTAndNXError) Error() string {
+	return "GT, LT, and/or NX options at the same time are not compatible"
+}
+
+type IncrPairError struct{}
+
+func (e IncrPairError) Error() string {
+	return "INCR option supports a single increment-element pair"
+}
+
+type ScoreNaNError struct{}
+
+func (e ScoreNaNError) Error() string {
+	return "resulting score is not a number (NaN)"
+}
+
+type MinMaxNotFloatError struct{}
+
+func (e MinMaxNotFloatError) Error() string {
+	return "min or max is not a float"
+}
+
+type MinMaxNotLexError struct{}
+
+func (e MinMaxNotLexError) Error() string {
+	return "min or max not valid string range item"
+}
+
+type ZRangeLimitError struct{}
+
+func (e ZRangeLimitError) Error() string {
+	return "syntax error, LIMIT is only supported in combination with either BYSCORE or BYLEX"
+}
+
+type ZRangeWithScoresError struct{}
+
+func (e ZRangeWithScoresError) Error() string {
+	return "syntax error, WITHSCORES not supported in combination with BYLEX"
+}
+
+type TimeoutNotFloatError struct{}
+
+func (e TimeoutNotFloatError) Error() string {
+	return "timeout is not a float or out of range"
+}
+
+type TimeoutNegativeError struct{}
+
+func (e TimeoutNegativeError) Error() string {
+	return "timeout is negative"
+}
+
+type TimeoutOutOfRangeError struct{}
+
+func (e TimeoutOutOfRangeError) Error() string {
+	return "timeout is out of range"
+}
+
+type RDBSaveError struct {
+	Err error
+}
+
+func (e RDBSaveError) Error() string {
+	return fmt.Sprintf("error saving the RDB file: %v", e.Err)
+}
+
+type SyntaxError struct{}
+
+func (e SyntaxError) Error() string {
+	return "syntax error"
+}
+
+type UnknownCommandError struct {
+	Name string
+	Args [][]byte
+}
+
+func (e UnknownCommandError) Error() string {
+	var sb strings.Builder
+	for _, a := range e.Args {
+		if sb.Len()+len(a) > 128 {
+			break
+		}
+		sb.WriteString(fmt.Sprintf("'%s' ", a))
+	}
+
+	return fmt.Sprintf("unknown command '%s', with args beginning with: %s", e.Name, sb.String())
+}
+
+type UnknownSubcommandError struct {
+	Cmd        string
+	Subcommand string
+}
+
+func (e UnknownSubcommandError) Error() string {
+	return fmt.Sprintf("unknown subcommand '%s'. Try %s HELP.", e.Subcommand, strings.ToUpper(e.Cmd))
+}
+
+type WrongNumberOfArgumentsError struct {
+	Cmd string
+}
+
+func (e WrongNumberOfArgumentsError) Error() string {
+	return fmt.Sprintf("wrong number of arguments for '%s' command", strings.ToLower(e.Cmd))
+}
+
+type InvalidCommandError struct{}
+
+func (e InvalidCommandError) Error() string {
+	return "Invalid command specified"
+}
+
+type InvalidCommandArgumentsError struct{}
+
+func (e InvalidCommandArgumentsError) Error() string {
+	return "Invalid number of arguments specified for command"
+}
+
+type NoKeyArgumentsError struct{}
+
+func (e NoKeyArgumentsError) Error() string {
+	return "The command has no key arguments"
+}
+
 type NoLeaderAvailableError struct{}
 
 func (e NoLeaderAvailableError) Error() string {
@@ -0,0 +1,142 @@
+package data
+
+import "sync"
+
+// BlockedClient is a client waiting in a blocking command, such as BLPOP,
+// until one of its keys can serve it or its timeout expires
+type BlockedClient struct {
+	Client *RedisClient
+	keys   []string
+	// serve retries the command, it reports false when the keys still
+	// can't serve the client
+	serve func() ([]byte, bool)
+	reply chan []byte
+}
+
+// Reply receives the reply once another command served the client
+func (bc *BlockedClient) Reply() <-chan []byte {
+	return bc.reply
+}
+
+// BlockingTable keeps, for every key, the clients blocked on it in the
+// order they blocked so they are served first come first served
+//
+// https://github.com/redis/redis/blob/unstable/src/blocked.c
+type BlockingTable struct {
+	mu       sync.Mutex
+	keys     map[string][]*BlockedClient
+	ready    []string
+	readySet map[string]struct{}
+}
+
+func NewBlockingTable() *BlockingTable {
+	return &BlockingTable{
+		keys:     map[string][]*BlockedClient{},
+		readySet: map[string]struct{}{},
+	}
+}
+
+// Block queues the client on every key, serve is called when one of the
+// keys is signalled as ready
+func (t *BlockingTable) Block(c *RedisClient, keys [][]byte, serve func() ([]byte, bool)) *BlockedClient {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	bc := &BlockedClient{c, nil, serve, make(chan []byte, 1)}
+	for _, k := range keys {
+		bc.keys = append(bc.keys, string(k))
+		t.keys[string(k)] = append(t.keys[string(k)], bc)
+	}
+
+	return bc
+}
+
+// Unblock removes the client from every key it waits on, it reports false
+// when the client was already served or unblocked
+func (t *BlockingTable) Unblock(bc *BlockedClient) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if bc.keys == nil {
+		return false
+	}
+
+	for _, k := range bc.keys {
+		q := t.keys[k]
+		for i, o := range q {
+			if o == bc {
+				q = append(q[:i:i], q[i+1:]...)
+				break
+			}
+		}
+
+		if len(q) == 0 {
+			delete(t.keys, k)
+		} else {
+			t.keys[k] = q
+		}
+	}
+	bc.keys = nil
+
+	return true
+}
+
+// SignalReady marks the key as ready to serve the clients blocked on it,
+// keys nobody waits on are ignored
+func (t *BlockingTable) SignalReady(key []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	k := string(key)
+	if _, ok := t.keys[k]; !ok {
+		return
+	}
+
+	if _, ok := t.readySet[k]; !ok {
+		t.readySet[k] = struct{}{}
+		t.ready = append(t.ready, k)
+	}
+}
+
+// ServeReady tries to serve the clients blocked on the keys signalled as
+// ready, in the order they blocked, until the keys run out of elements.
+// Serving a client may signal other keys, they are served in turn. It must
+// be called with the execution lock held
+func (t *BlockingTable) ServeReady() {
+	for {
+		t.mu.Lock()
+		ready := t.ready
+		t.ready = nil
+		clear(t.readySet)
+		t.mu.Unlock()
+
+		if len(ready) == 0 {
+			return
+		}
+
+		for _, k := range ready {
+			t.mu.Lock()
+			waiting := append([]*BlockedClient{}, t.keys[k]...)
+			t.mu.Unlock()
+
+			for _, bc := range waiting {
+				// a client blocked on several ready keys is served once
+				t.mu.Lock()
+				blocked := bc.keys != nil
+				t.mu.Unlock()
+				if !blocked {
+					continue
+				}
+
+				// serve runs commands that lock the table themselves
+				b, ok := bc.serve()
+				if !ok {
+					continue
+				}
+
+				t.Unblock(bc)
+				bc.reply <- b
+			}
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package data
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	RESP2 = 2
+	RESP3 = 3
+)
+
+var nextClientId atomic.Int64
+
+// TrackingOptions are the CLIENT TRACKING settings of a client
+//
+// https://redis.io/docs/latest/develop/reference/client-side-caching/
+type TrackingOptions struct {
+	Enabled  bool
+	Redirect int64
+	Bcast    bool
+	OptIn    bool
+	OptOut   bool
+	NoLoop   bool
+	Prefixes [][]byte
+}
+
+// RedisClient holds the state of a single connection, it is shared by
+// every command executed on that connection. Other connections read it when
+// delivering push messages so the mutable state is guarded by a mutex
+type RedisClient struct {
+	mu       sync.Mutex
+	id       int64
+	name     []byte
+	protocol int
+
+	tracking       TrackingOptions
+	caching        bool
+	redirectBroken bool
+	subscriptions  map[string]struct{}
+
+	pushes [][]byte
+	notify chan struct{}
+
+	closeOnce sync.Once
+	closed    chan struct{}
+
+	// run by the connection's own goroutine before a blocking command
+	// waits, it is set once when the connection starts
+	beforeBlock func()
+}
+
+func NewRedisClient() *RedisClient {
+	return &RedisClient{
+		id:            nextClientId.Add(1),
+		protocol:      RESP2,
+		subscriptions: map[string]struct{}{},
+		notify:        make(chan struct{}, 1),
+		closed:        make(chan struct{}),
+	}
+}
+
+func (c *RedisClient) Id() int64 {
+	return c.id
+}
+
+func (c *RedisClient) Name() []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.name
+}
+
+func (c *RedisClient) SetName(name []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.name = name
+}
+
+func (c *RedisClient) Protocol() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.protocol
+}
+
+func (c *RedisClient) SetProtocol(p int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.protocol = p
+}
+
+func (c *RedisClient) Tracking() TrackingOptions {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tracking
+}
+
+func (c *RedisClient) SetTracking(t TrackingOptions) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tracking = t
+	c.caching = false
+	c.redirectBroken = false
+}
+
+// Caching reports whether CLIENT CACHING was called right before the
+// current command, it overrides the OPTIN/OPTOUT default for one command
+func (c *RedisClient) Caching() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.caching
+}
+
+func (c *RedisClient) SetCaching(caching bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.caching = caching
+}
+
+func (c *RedisClient) RedirectBroken() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.redirectBroken
+}
+
+func (c *RedisClient) SetRedirectBroken() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.redirectBroken = true
+}
+
+// Subscribe adds the channel to the client's subscriptions and returns the
+// number of channels the client is subscribed to
+func (c *RedisClient) Subscribe(channel []byte) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.subscriptions[string(channel)] = struct{}{}
+	return len(c.subscriptions)
+}
+
+func (c *RedisClient) Unsubscribe(channel []byte) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.subscriptions, string(channel))
+	return len(c.subscriptions)
+}
+
+func (c *RedisClient) IsSubscribed(channel []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.subscriptions[string(channel)]
+	return ok
+}
+
+func (c *RedisClient) SubscriptionCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.subscriptions)
+}
+
+func (c *RedisClient) Subscriptions() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	chs := make([][]byte, 0, len(c.subscriptions))
+	for ch := range c.subscriptions {
+		chs = append(chs, []byte(ch))
+	}
+
+	return chs
+}
+
+// Push queues an encoded out of band message, such as an invalidation, to
+// be written to the client's connection. It never blocks the caller
+func (c *RedisClient) Push(b []byte) {
+	c.mu.Lock()
+	c.pushes = append(c.pushes, b)
+	c.mu.Unlock()
+
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Pushed is signalled whenever messages are queued by Push
+func (c *RedisClient) Pushed() <-chan struct{} {
+	return c.notify
+}
+
+// TakePushes returns the queued messages in the order they were pushed
+func (c *RedisClient) TakePushes() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p := c.pushes
+	c.pushes = nil
+	return p
+}
+
+// SetBeforeBlock registers what runs before the client waits in a
+// blocking command, the connection uses it to send the replies it buffered
+func (c *RedisClient) SetBeforeBlock(fn func()) {
+	c.beforeBlock = fn
+}
+
+// BeforeBlock runs the function registered with SetBeforeBlock, if any
+func (c *RedisClient) BeforeBlock() {
+	if c.beforeBlock != nil {
+		c.beforeBlock()
+	}
+}
+
+// Close marks the client as disconnected, it is safe to call more than once
+func (c *RedisClient) Close() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+}
+
+// Closed is closed once the client disconnects or the server shuts down,
+// a client waiting in a blocking command stops waiting
+func (c *RedisClient) Closed() <-chan struct{} {
+	return c.closed
+}
+
+// ClientRegistry keeps every connected client by id
+type ClientRegistry struct {
+	mu      sync.RWMutex
+	clients map[int64]*RedisClient
+}
+
+func NewClientRegistry() *ClientRegistry {
+	return &ClientRegistry{
+		clients: map[int64]*RedisClient{},
+	}
+}
+
+func (cr *ClientRegistry) Add(c *RedisClient) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	cr.clients[c.id] = c
+}
+
+func (cr *ClientRegistry) Remove(c *RedisClient) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	delete(cr.clients, c.id)
+}
+
+func (cr *ClientRegistry) Get(id int64) (*RedisClient, bool) {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	c, ok := cr.clients[id]
+	return c, ok
+}
+
+func (cr *ClientRegistry) All() []*RedisClient {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+
+	cs := make([]*RedisClient, 0, len(cr.clients))
+	for _, c := range cr.clients {
+		cs = append(cs, c)
+	}
+
+	return cs
+}
@@ -0,0 +1,198 @@
+package data
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/JanitSri/codecrafters-build-your-own-redis/customerror"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/util"
+)
+
+// https://raw.githubusercontent.com/redis/redis/unstable/redis.conf
+const (
+	DEFAULT_PROTO_MAX_BULK_LEN        = 512 * 1024 * 1024
+	DEFAULT_PROTO_MAX_MULTIBULK_LEN   = 1024 * 1024
+	DEFAULT_CLIENT_QUERY_BUFFER_LIMIT = 1024 * 1024 * 1024
+
+	// how many times per second background tasks such as the active expire
+	// cycle run
+	DEFAULT_HZ = 10
+	MIN_HZ     = 1
+	MAX_HZ     = 500
+
+	// a hash is kept compact while it has at most this many fields and
+	// every field and value is at most this many bytes long
+	DEFAULT_HASH_MAX_LISTPACK_ENTRIES = 128
+	DEFAULT_HASH_MAX_LISTPACK_VALUE   = 64
+
+	// a set of integers is kept as an intset while it has at most
+	// set-max-intset-entries members, other sets are kept compact under the
+	// same kind of limits as hashes
+	DEFAULT_SET_MAX_INTSET_ENTRIES   = 512
+	DEFAULT_SET_MAX_LISTPACK_ENTRIES = 128
+	DEFAULT_SET_MAX_LISTPACK_VALUE   = 64
+
+	// what to do when the RDB file exists but can't be loaded, exit refuses
+	// to start while skip starts with an empty dataset
+	RDB_ERROR_POLICY_EXIT = "exit"
+	RDB_ERROR_POLICY_SKIP = "skip"
+)
+
+type RedisConfig struct {
+	dir                    string
+	dbFileName             string
+	protoMaxBulkLen        int64
+	protoMaxMultibulkLen   int64
+	clientQueryBufferLimit int64
+	rdbErrorPolicy         string
+	hz                     int
+	hashMaxListpackEntries int64
+	hashMaxListpackValue   int64
+	setMaxIntsetEntries    int64
+	setMaxListpackEntries  int64
+	setMaxListpackValue    int64
+}
+
+func NewRedisConfig(dir, dbFileName string) *RedisConfig {
+	return &RedisConfig{
+		dir,
+		dbFileName,
+		DEFAULT_PROTO_MAX_BULK_LEN,
+		DEFAULT_PROTO_MAX_MULTIBULK_LEN,
+		DEFAULT_CLIENT_QUERY_BUFFER_LIMIT,
+		RDB_ERROR_POLICY_EXIT,
+		DEFAULT_HZ,
+		DEFAULT_HASH_MAX_LISTPACK_ENTRIES,
+		DEFAULT_HASH_MAX_LISTPACK_VALUE,
+		DEFAULT_SET_MAX_INTSET_ENTRIES,
+		DEFAULT_SET_MAX_LISTPACK_ENTRIES,
+		DEFAULT_SET_MAX_LISTPACK_VALUE,
+	}
+}
+
+func (rc *RedisConfig) Get(name string) (string, bool) {
+	var c string
+	switch strings.ToLower(name) {
+	case "dir":
+		c = rc.dir
+	case "dbfilename":
+		c = rc.dbFileName
+	case "proto-max-bulk-len":
+		c = strconv.FormatInt(rc.protoMaxBulkLen, 10)
+	case "proto-max-multibulk-len":
+		c = strconv.FormatInt(rc.protoMaxMultibulkLen, 10)
+	case "client-query-buffer-limit":
+		c = strconv.FormatInt(rc.clientQueryBufferLimit, 10)
+	case "rdb-error-policy":
+		c = rc.rdbErrorPolicy
+	case "hz":
+		c = strconv.Itoa(rc.hz)
+	case "hash-max-listpack-entries", "hash-max-ziplist-entries":
+		c = strconv.FormatInt(rc.hashMaxListpackEntries, 10)
+	case "hash-max-listpack-value", "hash-max-ziplist-value":
+		c = strconv.FormatInt(rc.hashMaxListpackValue, 10)
+	case "set-max-intset-entries":
+		c = strconv.FormatInt(rc.setMaxIntsetEntries, 10)
+	case "set-max-listpack-entries":
+		c = strconv.FormatInt(rc.setMaxListpackEntries, 10)
+	case "set-max-listpack-value":
+		c = strconv.FormatInt(rc.setMaxListpackValue, 10)
+	default:
+		return "", false
+	}
+
+	return c, true
+}
+
+// Set updates a config by name, sizes accept the same units as redis.conf
+// such as 512mb or 1gb
+func (rc *RedisConfig) Set(name, value string) error {
+	switch strings.ToLower(name) {
+	case "dir":
+		rc.dir = value
+	case "dbfilename":
+		rc.dbFileName = value
+	case "proto-max-bulk-len":
+		return setMemory(&rc.protoMaxBulkLen, name, value, 1)
+	case "proto-max-multibulk-len":
+		return setMemory(&rc.protoMaxMultibulkLen, name, value, 1)
+	case "client-query-buffer-limit":
+		return setMemory(&rc.clientQueryBufferLimit, name, value, 1024*1024)
+	case "rdb-error-policy":
+		v := strings.ToLower(value)
+		if v != RDB_ERROR_POLICY_EXIT && v != RDB_ERROR_POLICY_SKIP {
+			return customerror.InvalidServerConfigError{Name: name}
+		}
+		rc.rdbErrorPolicy = v
+	case "hz":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < MIN_HZ || n > MAX_HZ {
+			return customerror.InvalidServerConfigError{Name: name}
+		}
+		rc.hz = n
+	case "hash-max-listpack-entries", "hash-max-ziplist-entries":
+		return setMemory(&rc.hashMaxListpackEntries, name, value, 0)
+	case "hash-max-listpack-value", "hash-max-ziplist-value":
+		return setMemory(&rc.hashMaxListpackValue, name, value, 0)
+	case "set-max-intset-entries":
+		return setMemory(&rc.setMaxIntsetEntries, name, value, 0)
+	case "set-max-listpack-entries":
+		return setMemory(&rc.setMaxListpackEntries, name, value, 0)
+	case "set-max-listpack-value":
+		return setMemory(&rc.setMaxListpackValue, name, value, 0)
+	default:
+		return customerror.InvalidServerConfigError{Name: name}
+	}
+
+	return nil
+}
+
+func setMemory(c *int64, name, value string, min int64) error {
+	n, err := util.ParseMemory(value)
+	if err != nil || n < min {
+		return customerror.InvalidServerConfigError{Name: name}
+	}
+
+	*c = n
+	return nil
+}
+
+func (rc *RedisConfig) ProtoMaxBulkLen() int64 {
+	return rc.protoMaxBulkLen
+}
+
+func (rc *RedisConfig) ProtoMaxMultibulkLen() int64 {
+	return rc.protoMaxMultibulkLen
+}
+
+func (rc *RedisConfig) ClientQueryBufferLimit() int64 {
+	return rc.clientQueryBufferLimit
+}
+
+func (rc *RedisConfig) RDBErrorPolicy() string {
+	return rc.rdbErrorPolicy
+}
+
+func (rc *RedisConfig) Hz() int {
+	return rc.hz
+}
+
+func (rc *RedisConfig) HashMaxListpackEntries() int64 {
+	return rc.hashMaxListpackEntries
+}
+
+func (rc *RedisConfig) HashMaxListpackValue() int64 {
+	return rc.hashMaxListpackValue
+}
+
+func (rc *RedisConfig) SetMaxIntsetEntries() int64 {
+	return rc.setMaxIntsetEntries
+}
+
+func (rc *RedisConfig) SetMaxListpackEntries() int64 {
+	return rc.setMaxListpackEntries
+}
+
+func (rc *RedisConfig) SetMaxListpackValue() int64 {
+	return rc.setMaxListpackValue
+}
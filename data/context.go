@@ -1,23 +1,74 @@
 package data
 
+import "sync"
+
+// RedisContext is what a command sees while it executes. Commands run one
+// at a time under the execution lock, the same way Redis runs them on a
+// single thread, so a command that reads a key and writes it back is atomic
 type RedisContext struct {
 	RedisInfo *RedisInfo
 	DataStore DataStore
+	Clients   *ClientRegistry
+	Tracking  *TrackingTable
+	Blocking  *BlockingTable
+	Client    *RedisClient
+	mu        *sync.Mutex
 }
 
 func NewRedisContext(ri *RedisInfo, ds *RedisStore) *RedisContext {
 	return &RedisContext{
 		ri,
 		ds,
+		NewClientRegistry(),
+		NewTrackingTable(),
+		NewBlockingTable(),
+		nil,
+		&sync.Mutex{},
 	}
 }
 
+// Lock acquires the execution lock, it is held for the whole execution
+// of a command
+func (rc *RedisContext) Lock() {
+	rc.mu.Lock()
+}
+
+func (rc *RedisContext) Unlock() {
+	rc.mu.Unlock()
+}
+
+// ForClient returns a copy of the context bound to the given connection,
+// everything else stays shared with the server
+func (rc *RedisContext) ForClient(c *RedisClient) *RedisContext {
+	return &RedisContext{
+		rc.RedisInfo,
+		rc.DataStore,
+		rc.Clients,
+		rc.Tracking,
+		rc.Blocking,
+		c,
+		rc.mu,
+	}
+}
+
+// RemoveClient forgets everything kept for a client once it disconnects,
+// keys it was tracking are cleaned up lazily when they are invalidated
+func (rc *RedisContext) RemoveClient(c *RedisClient) {
+	t := c.Tracking()
+	for _, p := range t.Prefixes {
+		rc.Tracking.RemovePrefix(p, c.Id())
+	}
+
+	rc.Clients.Remove(c)
+}
+
 // https://redis.io/docs/latest/commands/info/
 type RedisInfo struct {
 	Server      *Server
 	Clients     *Clients
 	Memory      *Memory
 	Persistence *Persistence
+	Stats       *Stats
 	Replication *Replication
 	CPU         *CPU
 	Sentinel    *Sentinel
@@ -154,6 +205,16 @@ type Persistence struct {
 	RdbSaves                 int64  `label:"rdb_saves"`
 }
 
+type Stats struct {
+	ExpiredSubkeys             int64  `label:"expired_subkeys"`
+	ExpiredKeys                int64  `label:"expired_keys"`
+	ExpiredStalePerc           string `label:"expired_stale_perc"`
+	ExpiredTimeCapReachedCount int64  `label:"expired_time_cap_reached_count"`
+	// time the expire cycle spent holding the execution lock, it is wall
+	// time and not the cpu time redis reports
+	ExpireCycleLockedMilliseconds int64 `label:"expire_cycle_locked_milliseconds"`
+}
+
 type Replication struct {
 	Role                       string `label:"role"`
 	ConnectedSlaves            int    `label:"connected_slaves"`
@@ -0,0 +1,189 @@
+package data
+
+import (
+	"hash/maphash"
+	"math/bits"
+	"math/rand/v2"
+)
+
+const (
+	DICT_INITIAL_SIZE = 4
+	// the table shrinks once less than 1/DICT_MIN_FILL of it is used
+	DICT_MIN_FILL = 8
+)
+
+// Dict is a chained hash table whose size is always a power of two, unlike
+// a map it can be iterated with a cursor that survives modifications and
+// resizes between calls, which is what SCAN needs
+//
+// https://github.com/redis/redis/blob/unstable/src/dict.c
+type Dict[V any] struct {
+	table []*dictEntry[V]
+	used  int
+	seed  maphash.Seed
+}
+
+type dictEntry[V any] struct {
+	key  string
+	val  V
+	next *dictEntry[V]
+}
+
+func NewDict[V any]() *Dict[V] {
+	return &Dict[V]{
+		seed: maphash.MakeSeed(),
+	}
+}
+
+func (d *Dict[V]) Len() int {
+	return d.used
+}
+
+func (d *Dict[V]) bucket(key string) uint64 {
+	return maphash.String(d.seed, key) & uint64(len(d.table)-1)
+}
+
+func (d *Dict[V]) find(key string) *dictEntry[V] {
+	if d.used == 0 {
+		return nil
+	}
+
+	for e := d.table[d.bucket(key)]; e != nil; e = e.next {
+		if e.key == key {
+			return e
+		}
+	}
+
+	return nil
+}
+
+func (d *Dict[V]) Get(key string) (V, bool) {
+	if e := d.find(key); e != nil {
+		return e.val, true
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Set stores the value under the key and reports whether the key is new
+func (d *Dict[V]) Set(key string, val V) bool {
+	if e := d.find(key); e != nil {
+		e.val = val
+		return false
+	}
+
+	if d.used >= len(d.table) {
+		d.resize(max(d.used*2, DICT_INITIAL_SIZE))
+	}
+
+	i := d.bucket(key)
+	d.table[i] = &dictEntry[V]{key, val, d.table[i]}
+	d.used++
+	return true
+}
+
+// Delete removes the key and returns the value it held
+func (d *Dict[V]) Delete(key string) (V, bool) {
+	var zero V
+	if d.used == 0 {
+		return zero, false
+	}
+
+	i := d.bucket(key)
+	for p := &d.table[i]; *p != nil; p = &(*p).next {
+		if e := *p; e.key == key {
+			*p = e.next
+			d.used--
+			if len(d.table) > DICT_INITIAL_SIZE && d.used*DICT_MIN_FILL < len(d.table) {
+				d.resize(d.used * 2)
+			}
+			return e.val, true
+		}
+	}
+
+	return zero, false
+}
+
+// resize moves every entry to a table of the smallest power of two that
+// holds size entries, all at once since there is no incremental rehashing
+func (d *Dict[V]) resize(size int) {
+	n := DICT_INITIAL_SIZE
+	for n < size {
+		n *= 2
+	}
+
+	old := d.table
+	d.table = make([]*dictEntry[V], n)
+	for _, e := range old {
+		for e != nil {
+			next := e.next
+			i := d.bucket(e.key)
+			e.next = d.table[i]
+			d.table[i] = e
+			e = next
+		}
+	}
+}
+
+// Range calls fn for every entry until it returns false, the dict must not
+// be modified by fn
+func (d *Dict[V]) Range(fn func(key string, val V) bool) {
+	for _, e := range d.table {
+		for ; e != nil; e = e.next {
+			if !fn(e.key, e.val) {
+				return
+			}
+		}
+	}
+}
+
+// Random returns a random entry, it picks a random non empty bucket and
+// then a random entry of its chain
+func (d *Dict[V]) Random() (string, V, bool) {
+	if d.used == 0 {
+		var zero V
+		return "", zero, false
+	}
+
+	var head *dictEntry[V]
+	for head == nil {
+		head = d.table[rand.IntN(len(d.table))]
+	}
+
+	n := 0
+	for e := head; e != nil; e = e.next {
+		n++
+	}
+
+	e := head
+	for range rand.IntN(n) {
+		e = e.next
+	}
+
+	return e.key, e.val, true
+}
+
+// Scan calls fn for every entry of the bucket the cursor points to and
+// returns the cursor of the next call, 0 once the iteration is complete.
+// The cursor is incremented on its reversed bits so the buckets already
+// visited stay visited when the table grows or shrinks in between, every
+// key present for the whole iteration is returned at least once but some
+// may be returned more than once
+func (d *Dict[V]) Scan(cursor uint64, fn func(key string, val V)) uint64 {
+	if d.used == 0 {
+		return 0
+	}
+
+	mask := uint64(len(d.table) - 1)
+	for e := d.table[cursor&mask]; e != nil; e = e.next {
+		fn(e.key, e.val)
+	}
+
+	// set the unmasked bits so incrementing the reversed cursor carries
+	// into the masked bits
+	cursor |= ^mask
+	cursor = bits.Reverse64(cursor)
+	cursor++
+	return bits.Reverse64(cursor)
+}
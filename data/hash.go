@@ -0,0 +1,248 @@
+package data
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"time"
+)
+
+// how a hash or a set is stored, a compact one is saved to RDB as a listpack
+const (
+	ENCODING_LISTPACK  = "listpack"
+	ENCODING_HASHTABLE = "hashtable"
+)
+
+// the latest a field can expire, in unix milliseconds
+const HASH_FIELD_MAX_EXPIRE = 1<<48 - 1
+
+// Hash is the value of a hash key. A small hash keeps its fields and
+// values in a flat slice, like the listpack Redis uses, where a linear
+// search is as fast as hashing and nothing is spent on buckets. It is
+// converted to a dict once it outgrows hash-max-listpack-entries or
+// hash-max-listpack-value and is never converted back. Fields may have
+// their own expiry, expired fields are removed by ExpireFields
+type Hash struct {
+	// field value pairs one after the other while the hash is compact
+	pairs [][]byte
+	dict  *Dict[[]byte]
+
+	expires map[string]time.Time
+	// no field expires before next, it may be earlier than the actual
+	// first expiry once a TTL is removed
+	next time.Time
+}
+
+func NewHash() *Hash {
+	return &Hash{}
+}
+
+func (h *Hash) Len() int {
+	if h.dict != nil {
+		return h.dict.Len()
+	}
+
+	return len(h.pairs) / 2
+}
+
+func (h *Hash) Encoding() string {
+	if h.dict != nil {
+		return ENCODING_HASHTABLE
+	}
+
+	return ENCODING_LISTPACK
+}
+
+// Convert moves the fields of a compact hash into a dict
+func (h *Hash) Convert() {
+	if h.dict != nil {
+		return
+	}
+
+	h.dict = NewDict[[]byte]()
+	for i := 0; i < len(h.pairs); i += 2 {
+		h.dict.Set(string(h.pairs[i]), h.pairs[i+1])
+	}
+	h.pairs = nil
+}
+
+// index returns the position of the field in pairs, -1 when it is missing
+func (h *Hash) index(field []byte) int {
+	for i := 0; i < len(h.pairs); i += 2 {
+		if bytes.Equal(h.pairs[i], field) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (h *Hash) Get(field []byte) ([]byte, bool) {
+	if h.dict != nil {
+		return h.dict.Get(string(field))
+	}
+
+	i := h.index(field)
+	if i < 0 {
+		return nil, false
+	}
+
+	return h.pairs[i+1], true
+}
+
+// SetKeepTTL stores the value of the field and reports whether the field
+// is new, an existing field keeps its expiry
+func (h *Hash) SetKeepTTL(field, value []byte) bool {
+	if h.dict != nil {
+		return h.dict.Set(string(field), value)
+	}
+
+	if i := h.index(field); i >= 0 {
+		h.pairs[i+1] = value
+		return false
+	}
+
+	h.pairs = append(h.pairs, field, value)
+	return true
+}
+
+// Set stores the value of the field and reports whether the field is new,
+// an existing field loses its expiry
+func (h *Hash) Set(field, value []byte) bool {
+	delete(h.expires, string(field))
+	return h.SetKeepTTL(field, value)
+}
+
+// Delete removes the field and reports whether it was stored
+func (h *Hash) Delete(field []byte) bool {
+	delete(h.expires, string(field))
+	if h.dict != nil {
+		_, ok := h.dict.Delete(string(field))
+		return ok
+	}
+
+	i := h.index(field)
+	if i < 0 {
+		return false
+	}
+
+	h.pairs = append(h.pairs[:i], h.pairs[i+2:]...)
+	return true
+}
+
+// Iterate calls fn with every field and its value until fn returns false,
+// the hash must not be modified by fn
+func (h *Hash) Iterate(fn func(field, value []byte) bool) {
+	if h.dict != nil {
+		h.dict.Range(func(k string, v []byte) bool {
+			return fn([]byte(k), v)
+		})
+		return
+	}
+
+	for i := 0; i < len(h.pairs); i += 2 {
+		if !fn(h.pairs[i], h.pairs[i+1]) {
+			return
+		}
+	}
+}
+
+// Random returns a random field and its value, the hash must not be empty
+func (h *Hash) Random() ([]byte, []byte) {
+	if h.dict != nil {
+		k, v, _ := h.dict.Random()
+		return []byte(k), v
+	}
+
+	i := rand.IntN(len(h.pairs)/2) * 2
+	return h.pairs[i], h.pairs[i+1]
+}
+
+// Scan returns every field at once while the hash is compact, like Redis
+// does for a listpack, the cursor is then always 0
+func (h *Hash) Scan(cursor uint64, fn func(elem, val []byte)) uint64 {
+	if h.dict != nil {
+		return h.dict.Scan(cursor, func(k string, v []byte) {
+			fn([]byte(k), v)
+		})
+	}
+
+	for i := 0; i < len(h.pairs); i += 2 {
+		fn(h.pairs[i], h.pairs[i+1])
+	}
+
+	return 0
+}
+
+// Copy returns a deep copy of the hash with the same encoding and field
+// expiries
+func (h *Hash) Copy() *Hash {
+	c := NewHash()
+	if h.dict != nil {
+		c.Convert()
+	}
+
+	h.Iterate(func(field, value []byte) bool {
+		c.Set(bytes.Clone(field), bytes.Clone(value))
+		return true
+	})
+
+	for f, t := range h.expires {
+		c.SetFieldExpiry([]byte(f), t)
+	}
+
+	return c
+}
+
+// FieldExpiry returns when the field expires, the zero time when it has no
+// expiry
+func (h *Hash) FieldExpiry(field []byte) time.Time {
+	return h.expires[string(field)]
+}
+
+// SetFieldExpiry changes the expiry of a stored field, a zero time removes
+// it
+func (h *Hash) SetFieldExpiry(field []byte, t time.Time) {
+	if t.IsZero() {
+		delete(h.expires, string(field))
+		return
+	}
+
+	if h.expires == nil {
+		h.expires = make(map[string]time.Time)
+	}
+
+	if len(h.expires) == 0 || t.Before(h.next) {
+		h.next = t
+	}
+	h.expires[string(field)] = t
+}
+
+// HasFieldExpiry reports whether any field has an expiry
+func (h *Hash) HasFieldExpiry() bool {
+	return len(h.expires) > 0
+}
+
+// ExpireFields deletes the fields whose expiry is not after now and
+// returns how many were deleted, it is cheap while no field is due
+func (h *Hash) ExpireFields(now time.Time) int {
+	if len(h.expires) == 0 || now.Before(h.next) {
+		return 0
+	}
+
+	var n int
+	var next time.Time
+	for f, t := range h.expires {
+		if !now.Before(t) {
+			h.Delete([]byte(f))
+			n++
+			continue
+		}
+
+		if next.IsZero() || t.Before(next) {
+			next = t
+		}
+	}
+	h.next = next
+
+	return n
+}
@@ -0,0 +1,369 @@
+package data
+
+import "bytes"
+
+// a node is full once it holds this many elements or bytes, the same as
+// the default list-max-listpack-size of -2
+const (
+	QUICKLIST_NODE_MAX_ENTRIES = 128
+	QUICKLIST_NODE_MAX_BYTES   = 8 * 1024
+)
+
+// Quicklist is the value of a list key, a doubly linked list of nodes that
+// each hold a small packed run of elements so pushing and popping at both
+// ends is cheap without paying a pointer for every element. An element
+// bigger than a node gets a node of its own
+//
+// https://github.com/redis/redis/blob/unstable/src/quicklist.c
+type Quicklist struct {
+	head  *quicklistNode
+	tail  *quicklistNode
+	count int
+}
+
+type quicklistNode struct {
+	prev    *quicklistNode
+	next    *quicklistNode
+	entries [][]byte
+	size    int
+}
+
+func NewQuicklist() *Quicklist {
+	return &Quicklist{}
+}
+
+// fits reports whether v can be added to the node, an empty node takes
+// any element
+func (n *quicklistNode) fits(v []byte) bool {
+	if len(n.entries) == 0 {
+		return true
+	}
+
+	return len(n.entries) < QUICKLIST_NODE_MAX_ENTRIES && n.size+len(v) <= QUICKLIST_NODE_MAX_BYTES
+}
+
+func (ql *Quicklist) Len() int {
+	return ql.count
+}
+
+// insertNode links n after at, or at the head when at is nil
+func (ql *Quicklist) insertNode(at, n *quicklistNode) {
+	if at == nil {
+		n.next = ql.head
+		if ql.head != nil {
+			ql.head.prev = n
+		}
+		ql.head = n
+	} else {
+		n.prev = at
+		n.next = at.next
+		if at.next != nil {
+			at.next.prev = n
+		}
+		at.next = n
+	}
+
+	if n.next == nil {
+		ql.tail = n
+	}
+}
+
+func (ql *Quicklist) unlinkNode(n *quicklistNode) {
+	if n.prev != nil {
+		n.prev.next = n.next
+	} else {
+		ql.head = n.next
+	}
+
+	if n.next != nil {
+		n.next.prev = n.prev
+	} else {
+		ql.tail = n.prev
+	}
+}
+
+func (ql *Quicklist) PushHead(v []byte) {
+	if ql.head == nil || !ql.head.fits(v) {
+		ql.insertNode(nil, &quicklistNode{})
+	}
+
+	h := ql.head
+	h.entries = append([][]byte{v}, h.entries...)
+	h.size += len(v)
+	ql.count++
+}
+
+func (ql *Quicklist) PushTail(v []byte) {
+	if ql.tail == nil || !ql.tail.fits(v) {
+		ql.insertNode(ql.tail, &quicklistNode{})
+	}
+
+	t := ql.tail
+	t.entries = append(t.entries, v)
+	t.size += len(v)
+	ql.count++
+}
+
+func (ql *Quicklist) PopHead() ([]byte, bool) {
+	if ql.count == 0 {
+		return nil, false
+	}
+
+	return ql.removeAt(ql.head, 0), true
+}
+
+func (ql *Quicklist) PopTail() ([]byte, bool) {
+	if ql.count == 0 {
+		return nil, false
+	}
+
+	return ql.removeAt(ql.tail, len(ql.tail.entries)-1), true
+}
+
+// removeAt deletes the i-th element of the node, a node left empty is
+// unlinked
+func (ql *Quicklist) removeAt(n *quicklistNode, i int) []byte {
+	v := n.entries[i]
+	switch i {
+	case 0:
+		n.entries = n.entries[1:]
+	case len(n.entries) - 1:
+		n.entries = n.entries[:i]
+	default:
+		n.entries = append(n.entries[:i:i], n.entries[i+1:]...)
+	}
+	n.size -= len(v)
+	ql.count--
+
+	if len(n.entries) == 0 {
+		ql.unlinkNode(n)
+	}
+
+	return v
+}
+
+// locate returns the node holding the element at index i, which must be in
+// range, and the position of the element in it. The walk starts from the
+// end that is closest
+func (ql *Quicklist) locate(i int) (*quicklistNode, int) {
+	if i < ql.count/2 {
+		n := ql.head
+		for i >= len(n.entries) {
+			i -= len(n.entries)
+			n = n.next
+		}
+		return n, i
+	}
+
+	n := ql.tail
+	i = ql.count - 1 - i
+	for i >= len(n.entries) {
+		i -= len(n.entries)
+		n = n.prev
+	}
+	return n, len(n.entries) - 1 - i
+}
+
+// normalize turns a negative index into one counted from the head, ok is
+// false when it is out of range
+func (ql *Quicklist) normalize(i int) (int, bool) {
+	if i < 0 {
+		i += ql.count
+	}
+
+	return i, i >= 0 && i < ql.count
+}
+
+// Index returns the element at i, negative indexes count from the tail
+func (ql *Quicklist) Index(i int) ([]byte, bool) {
+	i, ok := ql.normalize(i)
+	if !ok {
+		return nil, false
+	}
+
+	n, j := ql.locate(i)
+	return n.entries[j], true
+}
+
+// Set replaces the element at i, negative indexes count from the tail
+func (ql *Quicklist) Set(i int, v []byte) bool {
+	i, ok := ql.normalize(i)
+	if !ok {
+		return false
+	}
+
+	n, j := ql.locate(i)
+	n.size += len(v) - len(n.entries[j])
+	n.entries[j] = v
+	return true
+}
+
+// Range calls fn for the elements from index start to end inclusive, both
+// already normalized, walking toward the tail
+func (ql *Quicklist) Range(start, end int, fn func(v []byte)) {
+	if start > end || start >= ql.count {
+		return
+	}
+
+	n, j := ql.locate(start)
+	for i := start; i <= end && n != nil; n, j = n.next, 0 {
+		for ; j < len(n.entries) && i <= end; j++ {
+			fn(n.entries[j])
+			i++
+		}
+	}
+}
+
+// Iterate calls fn with every element and its index until fn returns
+// false, from the tail when reverse is set
+func (ql *Quicklist) Iterate(reverse bool, fn func(i int, v []byte) bool) {
+	if !reverse {
+		i := 0
+		for n := ql.head; n != nil; n = n.next {
+			for _, v := range n.entries {
+				if !fn(i, v) {
+					return
+				}
+				i++
+			}
+		}
+		return
+	}
+
+	i := ql.count - 1
+	for n := ql.tail; n != nil; n = n.prev {
+		for j := len(n.entries) - 1; j >= 0; j-- {
+			if !fn(i, n.entries[j]) {
+				return
+			}
+			i--
+		}
+	}
+}
+
+// Insert adds v before or after the first element equal to pivot, it
+// reports false when there is no such element
+func (ql *Quicklist) Insert(pivot, v []byte, after bool) bool {
+	for n := ql.head; n != nil; n = n.next {
+		for j, e := range n.entries {
+			if !bytes.Equal(e, pivot) {
+				continue
+			}
+
+			if after {
+				j++
+			}
+
+			// a full node is split at the insertion point, the element is
+			// added to the first half or to a node of its own
+			if !n.fits(v) {
+				if j < len(n.entries) {
+					rest := &quicklistNode{entries: append([][]byte{}, n.entries[j:]...)}
+					for _, r := range rest.entries {
+						rest.size += len(r)
+					}
+					n.entries = n.entries[:j:j]
+					n.size -= rest.size
+					ql.insertNode(n, rest)
+				}
+
+				if n.fits(v) {
+					j = len(n.entries)
+				} else {
+					m := &quicklistNode{}
+					ql.insertNode(n, m)
+					n, j = m, 0
+				}
+			}
+
+			n.entries = append(n.entries[:j:j], append([][]byte{v}, n.entries[j:]...)...)
+			n.size += len(v)
+			ql.count++
+			return true
+		}
+	}
+
+	return false
+}
+
+// Remove deletes up to count elements equal to v, starting from the head
+// when count is positive, from the tail when it is negative and all of
+// them when it is zero. It returns how many were removed
+func (ql *Quicklist) Remove(count int, v []byte) int {
+	reverse := count < 0
+	if reverse {
+		count = -count
+	}
+
+	removed := 0
+	n := ql.head
+	if reverse {
+		n = ql.tail
+	}
+
+	for n != nil && (count == 0 || removed < count) {
+		next := n.next
+		if reverse {
+			next = n.prev
+		}
+
+		drop := make([]bool, len(n.entries))
+		for k := range n.entries {
+			j := k
+			if reverse {
+				j = len(n.entries) - 1 - k
+			}
+
+			if (count == 0 || removed < count) && bytes.Equal(n.entries[j], v) {
+				drop[j] = true
+				removed++
+			}
+		}
+
+		kept := n.entries[:0]
+		for j, e := range n.entries {
+			if drop[j] {
+				n.size -= len(e)
+				ql.count--
+				continue
+			}
+			kept = append(kept, e)
+		}
+
+		n.entries = kept
+		if len(n.entries) == 0 {
+			ql.unlinkNode(n)
+		}
+		n = next
+	}
+
+	return removed
+}
+
+// Trim keeps only the elements from index start to end inclusive, both
+// already normalized, an empty range empties the list
+func (ql *Quicklist) Trim(start, end int) {
+	if start > end || start >= ql.count {
+		*ql = Quicklist{}
+		return
+	}
+
+	end = min(end, ql.count-1)
+	for range start {
+		ql.PopHead()
+	}
+	for range ql.count - (end - start + 1) {
+		ql.PopTail()
+	}
+}
+
+// Copy returns a deep copy of the list
+func (ql *Quicklist) Copy() *Quicklist {
+	c := NewQuicklist()
+	ql.Iterate(false, func(_ int, v []byte) bool {
+		c.PushTail(bytes.Clone(v))
+		return true
+	})
+
+	return c
+}
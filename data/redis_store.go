@@ -1,50 +1,297 @@
 package data
 
 import (
-	"log"
-	"strings"
+	"bytes"
+	"math/rand/v2"
+	"strconv"
 	"sync"
 	"time"
 
-	"github.com/JanitSri/codecrafters-build-your-own-redis/customerror"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/util"
 )
 
 type DataStore interface {
-	Get(key any) (any, bool)
-	Set(key, value any)
-	Keys() []any
-	GetConfig(string) string
+	Get(key []byte) (*RedisValue, bool)
+	Set(key []byte, value *RedisValue)
+	Delete(key []byte) bool
+	Rename(src, dst []byte) bool
+	SetExpiry(key []byte, t time.Time) bool
+	ReindexFieldExpiry(key []byte)
+	SampleVolatile(n int) [][]byte
+	VolatileLen() int
+	RandomKey() ([]byte, bool)
+	Keys() [][]byte
+	Scan(cursor uint64) ([][]byte, uint64)
+	GetConfig(string) (string, bool)
+	Config() *RedisConfig
 }
 
+// FieldVolatileSampler is implemented by stores that index the hashes with
+// field expiries, the active expire cycle samples them to reclaim fields
+// that are never accessed again
+type FieldVolatileSampler interface {
+	SampleFieldVolatile(n int) [][]byte
+	FieldVolatileLen() int
+}
+
+// RedisStore keeps keys as raw bytes, they are only converted to a string
+// to be usable as a dict key so any byte sequence is a valid key. Keys with
+// an expiry are also indexed in volatile, and hashes with fields that have
+// an expiry in fieldVolatile, so the active expire cycle can sample them
+// without walking the whole keyspace. A hash whose field expiries change
+// in place is reindexed with ReindexFieldExpiry
 type RedisStore struct {
-	cmap   sync.Map
-	config RedisConfig
+	mu            sync.Mutex
+	dict          *Dict[*RedisValue]
+	config        RedisConfig
+	volatile      keyIndex
+	fieldVolatile keyIndex
 }
 
 func NewRedisStore(rc RedisConfig) *RedisStore {
 	return &RedisStore{
-		config: rc,
+		dict:          NewDict[*RedisValue](),
+		config:        rc,
+		volatile:      newKeyIndex(),
+		fieldVolatile: newKeyIndex(),
+	}
+}
+
+func (rs *RedisStore) Get(key []byte) (*RedisValue, bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	return rs.dict.Get(string(key))
+}
+
+func (rs *RedisStore) Set(key []byte, value *RedisValue) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.dict.Set(string(key), value)
+	rs.indexExpiry(string(key), value)
+}
+
+// Delete removes the key and reports whether it was stored, expired keys
+// that were not removed yet count as stored
+func (rs *RedisStore) Delete(key []byte) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	_, ok := rs.dict.Delete(string(key))
+	rs.indexExpiry(string(key), nil)
+	return ok
+}
+
+// Rename moves the value of src, with its expiry, to dst replacing what
+// dst held. It reports false when src is not stored
+func (rs *RedisStore) Rename(src, dst []byte) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	v, ok := rs.dict.Delete(string(src))
+	if !ok {
+		return false
+	}
+
+	rs.dict.Set(string(dst), v)
+	rs.indexExpiry(string(src), nil)
+	rs.indexExpiry(string(dst), v)
+	return true
+}
+
+// SetExpiry changes the expiry of a stored key, a zero time removes it. It
+// reports false when the key is not stored
+func (rs *RedisStore) SetExpiry(key []byte, t time.Time) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	v, ok := rs.dict.Get(string(key))
+	if !ok {
+		return false
+	}
+
+	v.SetExpiry(t)
+	rs.indexExpiry(string(key), v)
+	return true
+}
+
+// ReindexFieldExpiry updates the index of hashes with field expiries after
+// the fields of the hash stored at key changed in place, like SetExpiry
+// does for the expiry of the key
+func (rs *RedisStore) ReindexFieldExpiry(key []byte) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	v, _ := rs.dict.Get(string(key))
+	rs.indexExpiry(string(key), v)
+}
+
+// SampleVolatile returns up to n random keys that have an expiry, the same
+// key may be returned more than once
+func (rs *RedisStore) SampleVolatile(n int) [][]byte {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	return rs.volatile.sample(n)
+}
+
+// VolatileLen is the number of keys that have an expiry
+func (rs *RedisStore) VolatileLen() int {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	return len(rs.volatile.keys)
+}
+
+// SampleFieldVolatile returns up to n random keys holding a hash with
+// fields that have an expiry, the same key may be returned more than once
+func (rs *RedisStore) SampleFieldVolatile(n int) [][]byte {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	return rs.fieldVolatile.sample(n)
+}
+
+// FieldVolatileLen is the number of hashes with fields that have an expiry
+func (rs *RedisStore) FieldVolatileLen() int {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	return len(rs.fieldVolatile.keys)
+}
+
+// indexExpiry adds the key to the volatile indexes the value belongs to
+// and removes it from the others, a nil value is removed from all of them.
+// The caller holds mu
+func (rs *RedisStore) indexExpiry(key string, v *RedisValue) {
+	var value any
+	if v != nil {
+		value = v.value
+	}
+
+	if v != nil && !v.expiry.IsZero() {
+		rs.volatile.add(key)
+	} else {
+		rs.volatile.remove(key)
 	}
+
+	if h, ok := value.(*Hash); ok && h.HasFieldExpiry() {
+		rs.fieldVolatile.add(key)
+	} else {
+		rs.fieldVolatile.remove(key)
+	}
+}
+
+// keyIndex is a set of keys that can be sampled at random, removal swaps
+// the last key into the freed slot
+type keyIndex struct {
+	keys []string
+	pos  map[string]int
 }
 
-func (rs *RedisStore) Get(key any) (any, bool) {
-	return rs.cmap.Load(key)
+func newKeyIndex() keyIndex {
+	return keyIndex{
+		pos: make(map[string]int),
+	}
 }
 
-func (rs *RedisStore) Set(key, value any) {
-	rs.cmap.Store(key, value)
+func (ki *keyIndex) add(key string) {
+	if _, ok := ki.pos[key]; ok {
+		return
+	}
+
+	ki.pos[key] = len(ki.keys)
+	ki.keys = append(ki.keys, key)
 }
 
-func (rs *RedisStore) Keys() []any {
-	var keys []any
-	rs.cmap.Range(func(k, _ any) bool {
-		keys = append(keys, k)
+func (ki *keyIndex) remove(key string) {
+	i, ok := ki.pos[key]
+	if !ok {
+		return
+	}
+
+	last := len(ki.keys) - 1
+	ki.keys[i] = ki.keys[last]
+	ki.pos[ki.keys[i]] = i
+	ki.keys = ki.keys[:last]
+	delete(ki.pos, key)
+}
+
+// sample returns n random keys, the same key may be returned more than once
+func (ki *keyIndex) sample(n int) [][]byte {
+	if len(ki.keys) == 0 {
+		return nil
+	}
+
+	keys := make([][]byte, 0, n)
+	for range n {
+		keys = append(keys, []byte(ki.keys[rand.IntN(len(ki.keys))]))
+	}
+
+	return keys
+}
+
+// RandomKey returns a random key, it may be expired so callers check it the
+// way they check any other lookup
+func (rs *RedisStore) RandomKey() ([]byte, bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	k, _, ok := rs.dict.Random()
+	if !ok {
+		return nil, false
+	}
+
+	return []byte(k), true
+}
+
+func (rs *RedisStore) Keys() [][]byte {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	keys := make([][]byte, 0, rs.dict.Len())
+	rs.dict.Range(func(k string, _ *RedisValue) bool {
+		keys = append(keys, []byte(k))
 		return true
 	})
 
 	return keys
 }
 
+// Scan returns the keys of one step of a SCAN iteration and the cursor to
+// continue from, see Dict.Scan for the guarantees
+func (rs *RedisStore) Scan(cursor uint64) ([][]byte, uint64) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	var keys [][]byte
+	next := rs.dict.Scan(cursor, func(k string, _ *RedisValue) {
+		keys = append(keys, []byte(k))
+	})
+
+	return keys, next
+}
+
+// the names TYPE reports for each kind of value
+const (
+	TYPE_NONE   = "none"
+	TYPE_STRING = "string"
+	TYPE_LIST   = "list"
+	TYPE_HASH   = "hash"
+	TYPE_SET    = "set"
+	TYPE_ZSET   = "zset"
+	TYPE_STREAM = "stream"
+)
+
+// Scanner is implemented by the values of collection types so they can be
+// iterated with a cursor by HSCAN, SSCAN and ZSCAN. Each element is passed
+// with its value, such as the value of a hash field or the score of a
+// sorted set member, val is nil for elements that have none
+type Scanner interface {
+	Scan(cursor uint64, fn func(elem, val []byte)) uint64
+}
+
 type RedisValue struct {
 	value  any
 	expiry time.Time
@@ -57,10 +304,76 @@ func NewRedisValue(value any, expiry time.Time) *RedisValue {
 	}
 }
 
+// NewStringValue stores a string the way Redis does, strings that are
+// the canonical form of a 64 bit integer are kept as an int64
+func NewStringValue(b []byte, expiry time.Time) *RedisValue {
+	if n, ok := util.ParseStrictInt(b); ok {
+		return NewRedisValue(n, expiry)
+	}
+
+	return NewRedisValue(b, expiry)
+}
+
+// Bytes returns the value of a string key whatever its encoding, ok is
+// false when the key holds another type
+func (rv RedisValue) Bytes() ([]byte, bool) {
+	switch v := rv.value.(type) {
+	case []byte:
+		return v, true
+	case int64:
+		return strconv.AppendInt(nil, v, 10), true
+	}
+
+	return nil, false
+}
+
+func (rv RedisValue) Type() string {
+	switch rv.value.(type) {
+	case []byte, int64:
+		return TYPE_STRING
+	case *Quicklist:
+		return TYPE_LIST
+	case *Hash:
+		return TYPE_HASH
+	case *Set:
+		return TYPE_SET
+	case *ZSet:
+		return TYPE_ZSET
+	}
+
+	return TYPE_NONE
+}
+
+// Copy returns a deep copy of the value with the same expiry, the copy
+// shares nothing with the original so either can be modified in place
+func (rv RedisValue) Copy() *RedisValue {
+	var v any
+	switch o := rv.value.(type) {
+	case []byte:
+		v = bytes.Clone(o)
+	case *Quicklist:
+		v = o.Copy()
+	case *Hash:
+		v = o.Copy()
+	case *Set:
+		v = o.Copy()
+	case *ZSet:
+		v = o.Copy()
+	default:
+		v = o
+	}
+
+	return NewRedisValue(v, rv.expiry)
+}
+
 func (rv RedisValue) IsExpired() bool {
 	return !rv.expiry.IsZero() && time.Now().After(rv.expiry)
 }
 
+func (rv RedisValue) Expiry() time.Time {
+	return rv.expiry
+}
+
 func (rv *RedisValue) SetExpiry(t time.Time) {
 	rv.expiry = t
 }
@@ -69,28 +382,10 @@ func (rv RedisValue) Value() any {
 	return rv.value
 }
 
-type RedisConfig struct {
-	dir        string
-	dbFileName string
+func (rs *RedisStore) GetConfig(name string) (string, bool) {
+	return rs.config.Get(name)
 }
 
-func NewRedisConfig(dir, dbFileName string) *RedisConfig {
-	return &RedisConfig{
-		dir,
-		dbFileName,
-	}
-}
-
-func (rs *RedisStore) GetConfig(name string) string {
-	var c string
-	switch strings.ToUpper(name) {
-	case "DIR":
-		c = rs.config.dir
-	case "DBFILENAME":
-		c = rs.config.dbFileName
-	default:
-		log.Fatal(customerror.InvalidServerConfigError{Name: name})
-	}
-
-	return c
+func (rs *RedisStore) Config() *RedisConfig {
+	return &rs.config
 }
@@ -0,0 +1,242 @@
+package data
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+
+	"github.com/JanitSri/codecrafters-build-your-own-redis/util"
+)
+
+// a set made only of integers is stored as a sorted array of them
+const ENCODING_INTSET = "intset"
+
+// Set is the value of a set key. A set starts as an intset, a sorted
+// array of integers searched by bisection, and becomes a listpack, a flat
+// slice of members, once a member that is not an integer is added. Either
+// is converted to a dict once it outgrows the set-max-* limits and is never
+// converted back
+type Set struct {
+	encoding string
+	ints     []int64
+	members  [][]byte
+	dict     *Dict[struct{}]
+}
+
+func NewSet() *Set {
+	return &Set{
+		encoding: ENCODING_INTSET,
+	}
+}
+
+func (s *Set) Len() int {
+	switch s.encoding {
+	case ENCODING_INTSET:
+		return len(s.ints)
+	case ENCODING_LISTPACK:
+		return len(s.members)
+	}
+
+	return s.dict.Len()
+}
+
+func (s *Set) Encoding() string {
+	return s.encoding
+}
+
+// Convert moves the members to the given encoding, an intset can become a
+// listpack or a dict and a listpack can become a dict
+func (s *Set) Convert(encoding string) {
+	if encoding == s.encoding || s.encoding == ENCODING_HASHTABLE {
+		return
+	}
+
+	var members [][]byte
+	s.Iterate(func(m []byte) bool {
+		members = append(members, m)
+		return true
+	})
+	s.ints, s.members = nil, nil
+
+	s.encoding = encoding
+	if encoding == ENCODING_LISTPACK {
+		s.members = members
+		return
+	}
+
+	s.dict = NewDict[struct{}]()
+	for _, m := range members {
+		s.dict.Set(string(m), struct{}{})
+	}
+}
+
+// search returns where n is or would be inserted in the intset
+func (s *Set) search(n int64) (int, bool) {
+	return slices.BinarySearch(s.ints, n)
+}
+
+// index returns the position of the member in the listpack, -1 when it is
+// missing
+func (s *Set) index(m []byte) int {
+	for i, e := range s.members {
+		if bytes.Equal(e, m) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (s *Set) Contains(m []byte) bool {
+	switch s.encoding {
+	case ENCODING_INTSET:
+		n, ok := util.ParseStrictInt(m)
+		if !ok {
+			return false
+		}
+		_, found := s.search(n)
+		return found
+	case ENCODING_LISTPACK:
+		return s.index(m) >= 0
+	}
+
+	_, ok := s.dict.Get(string(m))
+	return ok
+}
+
+// Add stores the member and reports whether it is new, an intset becomes a
+// listpack when the member is not an integer
+func (s *Set) Add(m []byte) bool {
+	if s.encoding == ENCODING_INTSET {
+		n, ok := util.ParseStrictInt(m)
+		if ok {
+			i, found := s.search(n)
+			if found {
+				return false
+			}
+			s.ints = slices.Insert(s.ints, i, n)
+			return true
+		}
+		s.Convert(ENCODING_LISTPACK)
+	}
+
+	if s.encoding == ENCODING_LISTPACK {
+		if s.index(m) >= 0 {
+			return false
+		}
+		s.members = append(s.members, m)
+		return true
+	}
+
+	return s.dict.Set(string(m), struct{}{})
+}
+
+// Remove deletes the member and reports whether it was stored
+func (s *Set) Remove(m []byte) bool {
+	switch s.encoding {
+	case ENCODING_INTSET:
+		n, ok := util.ParseStrictInt(m)
+		if !ok {
+			return false
+		}
+		i, found := s.search(n)
+		if found {
+			s.ints = slices.Delete(s.ints, i, i+1)
+		}
+		return found
+	case ENCODING_LISTPACK:
+		i := s.index(m)
+		if i >= 0 {
+			s.members = slices.Delete(s.members, i, i+1)
+		}
+		return i >= 0
+	}
+
+	_, ok := s.dict.Delete(string(m))
+	return ok
+}
+
+// Iterate calls fn with every member until fn returns false, the set must
+// not be modified by fn
+func (s *Set) Iterate(fn func(m []byte) bool) {
+	switch s.encoding {
+	case ENCODING_INTSET:
+		for _, n := range s.ints {
+			if !fn(strconv.AppendInt(nil, n, 10)) {
+				return
+			}
+		}
+	case ENCODING_LISTPACK:
+		for _, m := range s.members {
+			if !fn(m) {
+				return
+			}
+		}
+	default:
+		s.dict.Range(func(k string, _ struct{}) bool {
+			return fn([]byte(k))
+		})
+	}
+}
+
+// Random returns a random member, the set must not be empty
+func (s *Set) Random() []byte {
+	switch s.encoding {
+	case ENCODING_INTSET:
+		return strconv.AppendInt(nil, s.ints[rand.IntN(len(s.ints))], 10)
+	case ENCODING_LISTPACK:
+		return s.members[rand.IntN(len(s.members))]
+	}
+
+	k, _, _ := s.dict.Random()
+	return []byte(k)
+}
+
+// Pop removes a random member and returns it, the set must not be empty
+func (s *Set) Pop() []byte {
+	m := s.Random()
+	s.Remove(m)
+	return m
+}
+
+// Scan returns every member at once while the set is an intset or a
+// listpack, like Redis does, the cursor is then always 0
+func (s *Set) Scan(cursor uint64, fn func(elem, val []byte)) uint64 {
+	if s.encoding == ENCODING_HASHTABLE {
+		return s.dict.Scan(cursor, func(k string, _ struct{}) {
+			fn([]byte(k), nil)
+		})
+	}
+
+	s.Iterate(func(m []byte) bool {
+		fn(m, nil)
+		return true
+	})
+
+	return 0
+}
+
+// Copy returns a deep copy of the set with the same encoding
+func (s *Set) Copy() *Set {
+	c := &Set{
+		encoding: s.encoding,
+		ints:     slices.Clone(s.ints),
+	}
+
+	switch s.encoding {
+	case ENCODING_LISTPACK:
+		c.members = make([][]byte, len(s.members))
+		for i, m := range s.members {
+			c.members[i] = bytes.Clone(m)
+		}
+	case ENCODING_HASHTABLE:
+		c.dict = NewDict[struct{}]()
+		s.dict.Range(func(k string, _ struct{}) bool {
+			c.dict.Set(k, struct{}{})
+			return true
+		})
+	}
+
+	return c
+}
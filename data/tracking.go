@@ -0,0 +1,112 @@
+package data
+
+import (
+	"bytes"
+	"sync"
+)
+
+// TrackingTable remembers which clients may have cached which keys. In the
+// default mode keys are remembered as they are read and forgotten once an
+// invalidation is sent, in BCAST mode clients subscribe to key prefixes
+type TrackingTable struct {
+	mu       sync.Mutex
+	keys     map[string]map[int64]struct{}
+	prefixes map[string]map[int64]struct{}
+}
+
+func NewTrackingTable() *TrackingTable {
+	return &TrackingTable{
+		keys:     map[string]map[int64]struct{}{},
+		prefixes: map[string]map[int64]struct{}{},
+	}
+}
+
+func (t *TrackingTable) RememberKey(key []byte, id int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ids, ok := t.keys[string(key)]
+	if !ok {
+		ids = map[int64]struct{}{}
+		t.keys[string(key)] = ids
+	}
+	ids[id] = struct{}{}
+}
+
+// TakeKey forgets the key and returns the clients that were tracking it
+func (t *TrackingTable) TakeKey(key []byte) []int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ids := t.keys[string(key)]
+	delete(t.keys, string(key))
+
+	out := make([]int64, 0, len(ids))
+	for id := range ids {
+		out = append(out, id)
+	}
+
+	return out
+}
+
+func (t *TrackingTable) AddPrefix(prefix []byte, id int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ids, ok := t.prefixes[string(prefix)]
+	if !ok {
+		ids = map[int64]struct{}{}
+		t.prefixes[string(prefix)] = ids
+	}
+	ids[id] = struct{}{}
+}
+
+func (t *TrackingTable) RemovePrefix(prefix []byte, id int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ids, ok := t.prefixes[string(prefix)]
+	if !ok {
+		return
+	}
+
+	delete(ids, id)
+	if len(ids) == 0 {
+		delete(t.prefixes, string(prefix))
+	}
+}
+
+// PrefixSubscribers returns the BCAST clients with a prefix matching key
+func (t *TrackingTable) PrefixSubscribers(key []byte) []int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	seen := map[int64]struct{}{}
+	var out []int64
+	for p, ids := range t.prefixes {
+		if !bytes.HasPrefix(key, []byte(p)) {
+			continue
+		}
+
+		for id := range ids {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				out = append(out, id)
+			}
+		}
+	}
+
+	return out
+}
+
+func (t *TrackingTable) TotalKeys() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.keys)
+}
+
+func (t *TrackingTable) TotalPrefixes() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.prefixes)
+}
@@ -0,0 +1,616 @@
+package data
+
+import (
+	"bytes"
+	"cmp"
+	"math/rand/v2"
+
+	"github.com/JanitSri/codecrafters-build-your-own-redis/util"
+)
+
+// a sorted set is stored as a skiplist ordered by score then member, with
+// a dict from member to score
+const ENCODING_SKIPLIST = "skiplist"
+
+const (
+	// enough levels for 4^32 elements
+	ZSKIPLIST_MAXLEVEL = 32
+	// the probability that a node has one more level
+	ZSKIPLIST_P = 0.25
+)
+
+// ScoreRange is an interval of scores, an end is left out when it is
+// exclusive
+type ScoreRange struct {
+	Min, Max     float64
+	MinEx, MaxEx bool
+}
+
+func (r ScoreRange) gteMin(f float64) bool {
+	if r.MinEx {
+		return f > r.Min
+	}
+
+	return f >= r.Min
+}
+
+func (r ScoreRange) lteMax(f float64) bool {
+	if r.MaxEx {
+		return f < r.Max
+	}
+
+	return f <= r.Max
+}
+
+func (r ScoreRange) empty() bool {
+	return r.Min > r.Max || (r.Min == r.Max && (r.MinEx || r.MaxEx))
+}
+
+// LexBound is an end of a LexRange, Inf is -1 for -, the string smaller
+// than any other, 1 for +, the string greater than any other, and 0 when
+// the bound is Value
+type LexBound struct {
+	Value     []byte
+	Exclusive bool
+	Inf       int
+}
+
+// compareLexBounds orders two bounds the way the strings they stand for
+// are ordered
+func compareLexBounds(a, b LexBound) int {
+	if a.Inf != 0 || b.Inf != 0 {
+		return cmp.Compare(a.Inf, b.Inf)
+	}
+
+	return bytes.Compare(a.Value, b.Value)
+}
+
+// LexRange is an interval of members compared byte by byte, it is only
+// meaningful when every member has the same score
+type LexRange struct {
+	Min, Max LexBound
+}
+
+func (r LexRange) gteMin(m string) bool {
+	if r.Min.Inf != 0 {
+		return r.Min.Inf < 0
+	}
+
+	c := bytes.Compare([]byte(m), r.Min.Value)
+	return c > 0 || (c == 0 && !r.Min.Exclusive)
+}
+
+func (r LexRange) lteMax(m string) bool {
+	if r.Max.Inf != 0 {
+		return r.Max.Inf > 0
+	}
+
+	c := bytes.Compare([]byte(m), r.Max.Value)
+	return c < 0 || (c == 0 && !r.Max.Exclusive)
+}
+
+func (r LexRange) empty() bool {
+	c := compareLexBounds(r.Min, r.Max)
+	return c > 0 || (c == 0 && (r.Min.Exclusive || r.Max.Exclusive))
+}
+
+// zskiplist is a skiplist whose links also store how many nodes they
+// skip, the span, so the rank of a node is found on the way to it
+//
+// https://github.com/redis/redis/blob/unstable/src/t_zset.c
+type zskiplist struct {
+	header *zskiplistNode
+	tail   *zskiplistNode
+	length int
+	level  int
+}
+
+type zskiplistNode struct {
+	member   string
+	score    float64
+	backward *zskiplistNode
+	level    []zskiplistLevel
+}
+
+type zskiplistLevel struct {
+	forward *zskiplistNode
+	span    int
+}
+
+func newZSkiplist() *zskiplist {
+	return &zskiplist{
+		header: &zskiplistNode{level: make([]zskiplistLevel, ZSKIPLIST_MAXLEVEL)},
+		level:  1,
+	}
+}
+
+func randomLevel() int {
+	level := 1
+	for level < ZSKIPLIST_MAXLEVEL && rand.Float64() < ZSKIPLIST_P {
+		level++
+	}
+
+	return level
+}
+
+// less reports whether the node sorts before the score and member
+func (n *zskiplistNode) less(score float64, member string) bool {
+	return n.score < score || (n.score == score && n.member < member)
+}
+
+// insert adds a node, the member must not be in the skiplist
+func (zsl *zskiplist) insert(score float64, member string) {
+	var update [ZSKIPLIST_MAXLEVEL]*zskiplistNode
+	var rank [ZSKIPLIST_MAXLEVEL]int
+
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		if i < zsl.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.level[i].forward != nil && x.level[i].forward.less(score, member) {
+			rank[i] += x.level[i].span
+			x = x.level[i].forward
+		}
+		update[i] = x
+	}
+
+	level := randomLevel()
+	if level > zsl.level {
+		for i := zsl.level; i < level; i++ {
+			update[i] = zsl.header
+			update[i].level[i].span = zsl.length
+		}
+		zsl.level = level
+	}
+
+	x = &zskiplistNode{member: member, score: score, level: make([]zskiplistLevel, level)}
+	for i := range level {
+		x.level[i].forward = update[i].level[i].forward
+		update[i].level[i].forward = x
+
+		// the new node splits the span of the link it was inserted into
+		x.level[i].span = update[i].level[i].span - (rank[0] - rank[i])
+		update[i].level[i].span = rank[0] - rank[i] + 1
+	}
+
+	// the links above the new node skip one more node
+	for i := level; i < zsl.level; i++ {
+		update[i].level[i].span++
+	}
+
+	if update[0] != zsl.header {
+		x.backward = update[0]
+	}
+	if x.level[0].forward != nil {
+		x.level[0].forward.backward = x
+	} else {
+		zsl.tail = x
+	}
+	zsl.length++
+}
+
+// search returns the last node of each level for which before is true,
+// before must hold for a prefix of the skiplist
+func (zsl *zskiplist) search(before func(n *zskiplistNode) bool) [ZSKIPLIST_MAXLEVEL]*zskiplistNode {
+	var update [ZSKIPLIST_MAXLEVEL]*zskiplistNode
+
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && before(x.level[i].forward) {
+			x = x.level[i].forward
+		}
+		update[i] = x
+	}
+
+	return update
+}
+
+// deleteNode unlinks x, update holds the nodes search found before it
+func (zsl *zskiplist) deleteNode(x *zskiplistNode, update *[ZSKIPLIST_MAXLEVEL]*zskiplistNode) {
+	for i := range zsl.level {
+		if update[i].level[i].forward == x {
+			update[i].level[i].span += x.level[i].span - 1
+			update[i].level[i].forward = x.level[i].forward
+		} else {
+			update[i].level[i].span--
+		}
+	}
+
+	if x.level[0].forward != nil {
+		x.level[0].forward.backward = x.backward
+	} else {
+		zsl.tail = x.backward
+	}
+
+	for zsl.level > 1 && zsl.header.level[zsl.level-1].forward == nil {
+		zsl.level--
+	}
+	zsl.length--
+}
+
+func (zsl *zskiplist) delete(score float64, member string) {
+	update := zsl.search(func(n *zskiplistNode) bool {
+		return n.less(score, member)
+	})
+
+	x := update[0].level[0].forward
+	if x != nil && x.score == score && x.member == member {
+		zsl.deleteNode(x, &update)
+	}
+}
+
+// updateScore moves the member to its new score, the node is kept in place
+// when it stays between its neighbours
+func (zsl *zskiplist) updateScore(score float64, member string, newScore float64) {
+	update := zsl.search(func(n *zskiplistNode) bool {
+		return n.less(score, member)
+	})
+
+	x := update[0].level[0].forward
+	if (x.backward == nil || x.backward.score < newScore) &&
+		(x.level[0].forward == nil || x.level[0].forward.score > newScore) {
+		x.score = newScore
+		return
+	}
+
+	zsl.deleteNode(x, &update)
+	zsl.insert(newScore, member)
+}
+
+// deleteWhile removes the nodes from the first one before returns false
+// for, as long as in returns true, fn is called with every removed member
+func (zsl *zskiplist) deleteWhile(before, in func(n *zskiplistNode) bool, fn func(member string)) int {
+	update := zsl.search(before)
+
+	removed := 0
+	x := update[0].level[0].forward
+	for x != nil && in(x) {
+		next := x.level[0].forward
+		zsl.deleteNode(x, &update)
+		fn(x.member)
+		removed++
+		x = next
+	}
+
+	return removed
+}
+
+// rank returns the 1 based rank of the member, 0 when it is missing
+func (zsl *zskiplist) rank(score float64, member string) int {
+	rank := 0
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil &&
+			(x.level[i].forward.less(score, member) || (x.level[i].forward.score == score && x.level[i].forward.member == member)) {
+			rank += x.level[i].span
+			x = x.level[i].forward
+		}
+
+		if x != zsl.header && x.member == member {
+			return rank
+		}
+	}
+
+	return 0
+}
+
+// byRank returns the node of the 1 based rank
+func (zsl *zskiplist) byRank(rank int) *zskiplistNode {
+	traversed := 0
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && traversed+x.level[i].span <= rank {
+			traversed += x.level[i].span
+			x = x.level[i].forward
+		}
+
+		if traversed == rank {
+			return x
+		}
+	}
+
+	return nil
+}
+
+// first returns the first node for which before is false, nil when it is
+// not in the range
+func (zsl *zskiplist) first(before, in func(n *zskiplistNode) bool) *zskiplistNode {
+	update := zsl.search(before)
+
+	x := update[0].level[0].forward
+	if x == nil || !in(x) {
+		return nil
+	}
+
+	return x
+}
+
+// last returns the last node for which in is true, nil when after is
+// false for it
+func (zsl *zskiplist) last(in, after func(n *zskiplistNode) bool) *zskiplistNode {
+	update := zsl.search(in)
+
+	x := update[0]
+	if x == zsl.header || !after(x) {
+		return nil
+	}
+
+	return x
+}
+
+// ZSet is the value of a sorted set key. The skiplist keeps the members
+// ordered for range and rank queries in O(log n) while the dict finds the
+// score of a member in O(1)
+type ZSet struct {
+	dict *Dict[float64]
+	zsl  *zskiplist
+}
+
+func NewZSet() *ZSet {
+	return &ZSet{
+		dict: NewDict[float64](),
+		zsl:  newZSkiplist(),
+	}
+}
+
+func (z *ZSet) Len() int {
+	return z.zsl.length
+}
+
+func (z *ZSet) Encoding() string {
+	return ENCODING_SKIPLIST
+}
+
+func (z *ZSet) Score(m []byte) (float64, bool) {
+	return z.dict.Get(string(m))
+}
+
+// Add stores the member with the score and reports whether it is new, an
+// existing member is moved to the score
+func (z *ZSet) Add(m []byte, score float64) bool {
+	k := string(m)
+	if cur, ok := z.dict.Get(k); ok {
+		if cur != score {
+			z.zsl.updateScore(cur, k, score)
+			z.dict.Set(k, score)
+		}
+		return false
+	}
+
+	z.zsl.insert(score, k)
+	z.dict.Set(k, score)
+	return true
+}
+
+// Remove deletes the member and reports whether it was stored
+func (z *ZSet) Remove(m []byte) bool {
+	k := string(m)
+	score, ok := z.dict.Delete(k)
+	if ok {
+		z.zsl.delete(score, k)
+	}
+
+	return ok
+}
+
+// Rank returns the 0 based rank of the member, by descending scores when
+// reverse is set
+func (z *ZSet) Rank(m []byte, reverse bool) (int, bool) {
+	k := string(m)
+	score, ok := z.dict.Get(k)
+	if !ok {
+		return 0, false
+	}
+
+	rank := z.zsl.rank(score, k)
+	if reverse {
+		return z.zsl.length - rank, true
+	}
+
+	return rank - 1, true
+}
+
+// walk calls fn from x toward the tail, or the head when reverse is set,
+// until fn returns false
+func walk(x *zskiplistNode, reverse bool, fn func(member []byte, score float64) bool) {
+	for x != nil && fn([]byte(x.member), x.score) {
+		if reverse {
+			x = x.backward
+		} else {
+			x = x.level[0].forward
+		}
+	}
+}
+
+// RangeByRank calls fn with the members from the 0 based rank start to
+// stop included until fn returns false, the ranks count from the highest
+// score when reverse is set and must be within the sorted set
+func (z *ZSet) RangeByRank(start, stop int, reverse bool, fn func(member []byte, score float64) bool) {
+	x := z.zsl.byRank(start + 1)
+	if reverse {
+		x = z.zsl.byRank(z.zsl.length - start)
+	}
+
+	n := stop - start + 1
+	walk(x, reverse, func(member []byte, score float64) bool {
+		n--
+		return n >= 0 && fn(member, score)
+	})
+}
+
+func (z *ZSet) firstInScoreRange(r ScoreRange) *zskiplistNode {
+	if r.empty() {
+		return nil
+	}
+
+	return z.zsl.first(func(n *zskiplistNode) bool {
+		return !r.gteMin(n.score)
+	}, func(n *zskiplistNode) bool {
+		return r.lteMax(n.score)
+	})
+}
+
+func (z *ZSet) lastInScoreRange(r ScoreRange) *zskiplistNode {
+	if r.empty() {
+		return nil
+	}
+
+	return z.zsl.last(func(n *zskiplistNode) bool {
+		return r.lteMax(n.score)
+	}, func(n *zskiplistNode) bool {
+		return r.gteMin(n.score)
+	})
+}
+
+// RangeByScore calls fn with the members whose score is in the range,
+// from the highest score when reverse is set, until fn returns false
+func (z *ZSet) RangeByScore(r ScoreRange, reverse bool, fn func(member []byte, score float64) bool) {
+	x := z.firstInScoreRange(r)
+	if reverse {
+		x = z.lastInScoreRange(r)
+	}
+
+	walk(x, reverse, func(member []byte, score float64) bool {
+		if reverse {
+			return r.gteMin(score) && fn(member, score)
+		}
+		return r.lteMax(score) && fn(member, score)
+	})
+}
+
+// CountByScore returns how many members have a score in the range, it
+// only looks up the rank of both ends
+func (z *ZSet) CountByScore(r ScoreRange) int {
+	return z.count(z.firstInScoreRange(r), z.lastInScoreRange(r))
+}
+
+func (z *ZSet) count(first, last *zskiplistNode) int {
+	if first == nil || last == nil {
+		return 0
+	}
+
+	return z.zsl.rank(last.score, last.member) - z.zsl.rank(first.score, first.member) + 1
+}
+
+func (z *ZSet) firstInLexRange(r LexRange) *zskiplistNode {
+	if r.empty() {
+		return nil
+	}
+
+	return z.zsl.first(func(n *zskiplistNode) bool {
+		return !r.gteMin(n.member)
+	}, func(n *zskiplistNode) bool {
+		return r.lteMax(n.member)
+	})
+}
+
+func (z *ZSet) lastInLexRange(r LexRange) *zskiplistNode {
+	if r.empty() {
+		return nil
+	}
+
+	return z.zsl.last(func(n *zskiplistNode) bool {
+		return r.lteMax(n.member)
+	}, func(n *zskiplistNode) bool {
+		return r.gteMin(n.member)
+	})
+}
+
+// RangeByLex calls fn with the members in the range, from the greatest
+// when reverse is set, until fn returns false
+func (z *ZSet) RangeByLex(r LexRange, reverse bool, fn func(member []byte, score float64) bool) {
+	x := z.firstInLexRange(r)
+	if reverse {
+		x = z.lastInLexRange(r)
+	}
+
+	walk(x, reverse, func(member []byte, score float64) bool {
+		if reverse {
+			return r.gteMin(string(member)) && fn(member, score)
+		}
+		return r.lteMax(string(member)) && fn(member, score)
+	})
+}
+
+// CountByLex returns how many members are in the range
+func (z *ZSet) CountByLex(r LexRange) int {
+	return z.count(z.firstInLexRange(r), z.lastInLexRange(r))
+}
+
+// RemoveRangeByRank deletes the members from the 0 based rank start to
+// stop included, the ranks must be within the sorted set
+func (z *ZSet) RemoveRangeByRank(start, stop int) int {
+	first := z.zsl.byRank(start + 1)
+	n := stop - start + 1
+
+	return z.zsl.deleteWhile(func(x *zskiplistNode) bool {
+		return x.less(first.score, first.member)
+	}, func(*zskiplistNode) bool {
+		n--
+		return n >= 0
+	}, z.forget)
+}
+
+// RemoveRangeByScore deletes the members whose score is in the range
+func (z *ZSet) RemoveRangeByScore(r ScoreRange) int {
+	if r.empty() {
+		return 0
+	}
+
+	return z.zsl.deleteWhile(func(x *zskiplistNode) bool {
+		return !r.gteMin(x.score)
+	}, func(x *zskiplistNode) bool {
+		return r.lteMax(x.score)
+	}, z.forget)
+}
+
+// RemoveRangeByLex deletes the members in the range
+func (z *ZSet) RemoveRangeByLex(r LexRange) int {
+	if r.empty() {
+		return 0
+	}
+
+	return z.zsl.deleteWhile(func(x *zskiplistNode) bool {
+		return !r.gteMin(x.member)
+	}, func(x *zskiplistNode) bool {
+		return r.lteMax(x.member)
+	}, z.forget)
+}
+
+// forget removes from the dict a member already removed from the skiplist
+func (z *ZSet) forget(member string) {
+	z.dict.Delete(member)
+}
+
+// Iterate calls fn with every member by ascending score until fn returns
+// false, the sorted set must not be modified by fn
+func (z *ZSet) Iterate(fn func(member []byte, score float64) bool) {
+	walk(z.zsl.header.level[0].forward, false, fn)
+}
+
+// Scan iterates the dict so the cursor survives modifications, every
+// member is passed with its score
+func (z *ZSet) Scan(cursor uint64, fn func(elem, val []byte)) uint64 {
+	return z.dict.Scan(cursor, func(k string, score float64) {
+		fn([]byte(k), FormatScore(score))
+	})
+}
+
+// Copy returns a deep copy of the sorted set
+func (z *ZSet) Copy() *ZSet {
+	c := NewZSet()
+	z.Iterate(func(member []byte, score float64) bool {
+		c.Add(member, score)
+		return true
+	})
+
+	return c
+}
+
+// FormatScore writes a score the way Redis replies with it, in the
+// shortest form that reads back as the same float and without an exponent
+// for ordinary values
+func FormatScore(f float64) []byte {
+	return []byte(util.FormatDouble(f))
+}
@@ -24,6 +24,24 @@ func main() {
 	var replicaOf string
 	flag.StringVar(&replicaOf, "replicaof", "", "redis server port number")
 
+	configs := map[string]string{}
+	configFlag := func(name, usage string) {
+		flag.Func(name, usage, func(v string) error {
+			configs[name] = v
+			return nil
+		})
+	}
+	configFlag("proto-max-bulk-len", "the maximum size of a single bulk string in a request (example: 512mb)")
+	configFlag("proto-max-multibulk-len", "the maximum number of arguments in a single request (example: 1048576)")
+	configFlag("client-query-buffer-limit", "the maximum size of a single request (example: 1gb)")
+	configFlag("hz", "how many times per second background tasks such as expiring keys run (example: 10)")
+	configFlag("hash-max-listpack-entries", "the most fields a hash can have before it stops being compact (example: 128)")
+	configFlag("hash-max-listpack-value", "the longest field or value a compact hash can hold in bytes (example: 64)")
+	configFlag("set-max-intset-entries", "the most members a set of integers can have before it stops being an intset (example: 512)")
+	configFlag("set-max-listpack-entries", "the most members a set can have before it stops being compact (example: 128)")
+	configFlag("set-max-listpack-value", "the longest member a compact set can hold in bytes (example: 64)")
+	configFlag("rdb-error-policy", "what to do when the RDB file can't be loaded, exit or skip (example: skip)")
+
 	flag.Parse()
 
 	var wg sync.WaitGroup
@@ -44,7 +62,7 @@ func main() {
 	if replicaOf != "" {
 		role = "slave"
 	}
-	leader := createRedisServer(d, db, port, role)
+	leader := createRedisServer(d, db, port, role, configs)
 
 	op.Join(leader)
 
@@ -56,8 +74,13 @@ func main() {
 	log.Println("main exiting")
 }
 
-func createRedisServer(dir, dbFilename, port, role string) *redis.RedisServer {
+func createRedisServer(dir, dbFilename, port, role string, configs map[string]string) *redis.RedisServer {
 	rc := data.NewRedisConfig(dir, dbFilename)
+	for n, v := range configs {
+		if err := rc.Set(n, v); err != nil {
+			log.Fatalln(err)
+		}
+	}
 	sc := redis.NewServerConfig("tcp", "0.0.0.0", port)
 
 	rr := &data.Replication{
@@ -66,6 +89,7 @@ func createRedisServer(dir, dbFilename, port, role string) *redis.RedisServer {
 		MasterReplOffset: 0,
 	}
 	ri := &data.RedisInfo{
+		Stats:       &data.Stats{ExpiredStalePerc: "0.00"},
 		Replication: rr,
 	}
 
@@ -0,0 +1,861 @@
+package parser
+
+import (
+	"log"
+	"math"
+	"math/bits"
+	"strings"
+	"time"
+
+	"github.com/JanitSri/codecrafters-build-your-own-redis/customerror"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/data"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/util"
+)
+
+// Bits are numbered the way Redis numbers them, bit 0 is the most
+// significant bit of the first byte, so bitmaps read from or written to a
+// dump file keep their meaning
+//
+// https://redis.io/docs/latest/develop/data-types/bitmaps/
+
+func parseSetBitCmd(args [][]byte) Command {
+	if _, ok := parseBitOffset(args[2], false, 1); !ok {
+		return NewErrorCommand(customerror.BitOffsetError{})
+	}
+
+	if string(args[3]) != "0" && string(args[3]) != "1" {
+		return NewErrorCommand(customerror.BitValueError{})
+	}
+
+	return NewSetBitCommand([][]byte{args[1], args[2], args[3]}, nil)
+}
+
+func parseGetBitCmd(args [][]byte) Command {
+	if _, ok := parseBitOffset(args[2], false, 1); !ok {
+		return NewErrorCommand(customerror.BitOffsetError{})
+	}
+
+	return NewGetBitCommand([][]byte{args[1], args[2]}, nil)
+}
+
+func parseBitCountCmd(args [][]byte) Command {
+	switch len(args) {
+	case 2:
+		return NewBitCountCommand([][]byte{args[1]}, nil)
+	case 4, 5:
+	default:
+		return NewErrorCommand(customerror.SyntaxError{})
+	}
+
+	return parseBitRange(args[2:], [][]byte{args[1]}, NewBitCountCommand)
+}
+
+func parseBitPosCmd(args [][]byte) Command {
+	bit, ok := util.ParseStrictInt(args[2])
+	if !ok {
+		return NewErrorCommand(customerror.NotIntegerError{})
+	}
+
+	if bit != 0 && bit != 1 {
+		return NewErrorCommand(customerror.BitArgumentError{})
+	}
+
+	if len(args) > 6 {
+		return NewErrorCommand(customerror.SyntaxError{})
+	}
+
+	return parseBitRange(args[3:], [][]byte{args[1], args[2]}, NewBitPosCommand)
+}
+
+// parseBitRange parses the optional start, end and BYTE|BIT arguments of
+// BITCOUNT and BITPOS, the range is appended to args
+func parseBitRange[T Command](rangeArgs, args [][]byte, newCmd func([][]byte, []*Flag) T) Command {
+	flags := []*Flag{}
+	for i, a := range rangeArgs {
+		if i == 2 {
+			unit := strings.ToUpper(string(a))
+			if unit != BYTE && unit != BIT {
+				return NewErrorCommand(customerror.SyntaxError{})
+			}
+			flags = append(flags, NewFlag(unit, ""))
+			continue
+		}
+
+		if _, ok := util.ParseStrictInt(a); !ok {
+			return NewErrorCommand(customerror.NotIntegerError{})
+		}
+		args = append(args, a)
+	}
+
+	return newCmd(args, flags)
+}
+
+func parseBitOpCmd(args [][]byte) Command {
+	op := strings.ToUpper(string(args[1]))
+	srcs := args[3:]
+
+	switch op {
+	case AND, OR, XOR, ONE:
+	case NOT:
+		if len(srcs) != 1 {
+			return NewErrorCommand(customerror.BitOpNotError{})
+		}
+	case DIFF, DIFF1, ANDOR:
+		if len(srcs) < 2 {
+			return NewErrorCommand(customerror.BitOpSourceKeysError{})
+		}
+	default:
+		return NewErrorCommand(customerror.SyntaxError{})
+	}
+
+	return NewBitOpCommand(args[2:], []*Flag{NewFlag(op, "")})
+}
+
+func parseBitfieldCmd(args [][]byte) Command {
+	return parseBitfield(args, false)
+}
+
+func parseBitfieldRoCmd(args [][]byte) Command {
+	return parseBitfield(args, true)
+}
+
+// bitfieldOp is a single GET, SET or INCRBY of a BITFIELD call, the
+// OVERFLOW behaviour in effect when it was parsed is kept with it
+type bitfieldOp struct {
+	name     string
+	signed   bool
+	bits     int64
+	offset   int64
+	value    int64
+	overflow string
+}
+
+func parseBitfield(args [][]byte, readOnly bool) Command {
+	ops := []bitfieldOp{}
+	overflow := WRAP
+
+	for i := 2; i < len(args); i++ {
+		name := strings.ToUpper(string(args[i]))
+
+		n := 0
+		switch name {
+		case GET:
+			n = 2
+		case SET, INCRBY:
+			n = 3
+		case OVERFLOW:
+			n = 1
+		default:
+			return NewErrorCommand(customerror.SyntaxError{})
+		}
+
+		if i+n >= len(args) {
+			return NewErrorCommand(customerror.SyntaxError{})
+		}
+
+		if readOnly && name != GET {
+			return NewErrorCommand(customerror.BitfieldReadOnlyError{})
+		}
+
+		if name == OVERFLOW {
+			overflow = strings.ToUpper(string(args[i+1]))
+			if overflow != WRAP && overflow != SAT && overflow != FAIL {
+				return NewErrorCommand(customerror.BitfieldOverflowError{})
+			}
+			i++
+			continue
+		}
+
+		op := bitfieldOp{name: name, overflow: overflow}
+
+		var ok bool
+		op.signed, op.bits, ok = parseBitfieldType(args[i+1])
+		if !ok {
+			return NewErrorCommand(customerror.BitfieldTypeError{})
+		}
+
+		op.offset, ok = parseBitOffset(args[i+2], true, op.bits)
+		if !ok {
+			return NewErrorCommand(customerror.BitOffsetError{})
+		}
+
+		if name != GET {
+			op.value, ok = util.ParseStrictInt(args[i+3])
+			if !ok {
+				return NewErrorCommand(customerror.NotIntegerError{})
+			}
+		}
+
+		ops = append(ops, op)
+		i += n
+	}
+
+	return NewBitfieldCommand([][]byte{args[1]}, ops)
+}
+
+// parseBitfieldType parses i1 to i64 and u1 to u63
+func parseBitfieldType(b []byte) (signed bool, n int64, ok bool) {
+	if len(b) < 2 {
+		return false, 0, false
+	}
+
+	switch b[0] {
+	case 'i', 'I':
+		signed = true
+	case 'u', 'U':
+	default:
+		return false, 0, false
+	}
+
+	n, ok = util.ParseStrictInt(b[1:])
+	if !ok || n < 1 || (signed && n > 64) || (!signed && n > 63) {
+		return false, 0, false
+	}
+
+	return signed, n, true
+}
+
+// parseBitOffset parses a bit offset, BITFIELD also accepts #N which is
+// the Nth field of the given width
+func parseBitOffset(b []byte, hash bool, width int64) (int64, bool) {
+	mul := int64(1)
+	if hash && len(b) > 0 && b[0] == '#' {
+		b = b[1:]
+		mul = width
+	}
+
+	n, ok := util.ParseStrictInt(b)
+	if !ok || n < 0 || n > math.MaxInt64/mul {
+		return 0, false
+	}
+
+	return n * mul, true
+}
+
+// checkBitOffset keeps bitmaps within the size a client could send as a
+// single string
+func checkBitOffset(rc *data.RedisContext, offset int64) error {
+	if offset>>3 >= rc.DataStore.Config().ProtoMaxBulkLen() {
+		return customerror.BitOffsetError{}
+	}
+
+	return nil
+}
+
+// growBitmap makes sure b holds at least n bytes, bytes that are already
+// there are modified in place
+func growBitmap(b []byte, n int64) []byte {
+	if int64(len(b)) >= n {
+		return b
+	}
+
+	nb := make([]byte, n)
+	copy(nb, b)
+	return nb
+}
+
+func getBit(b []byte, offset int64) uint64 {
+	i := offset >> 3
+	if i >= int64(len(b)) {
+		return 0
+	}
+
+	return uint64(b[i]>>(7-offset&7)) & 1
+}
+
+func setBit(b []byte, offset int64, bit uint64) {
+	mask := byte(1) << (7 - offset&7)
+	if bit == 1 {
+		b[offset>>3] |= mask
+	} else {
+		b[offset>>3] &^= mask
+	}
+}
+
+// getBits reads n bits starting at offset as an unsigned integer, bits past
+// the end of the string are zero
+func getBits(b []byte, offset, n int64) uint64 {
+	var v uint64
+	for i := range n {
+		v = v<<1 | getBit(b, offset+i)
+	}
+
+	return v
+}
+
+func setBits(b []byte, offset, n int64, v uint64) {
+	for i := range n {
+		setBit(b, offset+i, v>>(n-1-i)&1)
+	}
+}
+
+func signExtend(v uint64, n int64) int64 {
+	if n < 64 && v&(1<<(n-1)) != 0 {
+		v |= math.MaxUint64 << n
+	}
+
+	return int64(v)
+}
+
+type SetBitCommand struct {
+	BaseCommand
+}
+
+func NewSetBitCommand(args [][]byte, flags []*Flag) *SetBitCommand {
+	return &SetBitCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (sc *SetBitCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("setting bit...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := sc.args[0]
+	offset, _ := parseBitOffset(sc.args[1], false, 1)
+	bit := uint64(sc.args[2][0] - '0')
+
+	if err := checkBitOffset(rc, offset); err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	old, exists, err := lookupString(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	var b []byte
+	var expiry time.Time
+	if exists {
+		b, _ = old.Bytes()
+		expiry = old.Expiry()
+	}
+
+	b = growBitmap(b, offset>>3+1)
+	prev := getBit(b, offset)
+	setBit(b, offset, bit)
+
+	rc.DataStore.Set(key, data.NewStringValue(b, expiry))
+	signalModifiedKey(rc, key)
+
+	w.Integer(int64(prev))
+	return w.Bytes()
+}
+
+type GetBitCommand struct {
+	BaseCommand
+}
+
+func NewGetBitCommand(args [][]byte, flags []*Flag) *GetBitCommand {
+	return &GetBitCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (gc *GetBitCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting bit...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	offset, _ := parseBitOffset(gc.args[1], false, 1)
+
+	if err := checkBitOffset(rc, offset); err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	v, exists, err := lookupString(rc, gc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	var b []byte
+	if exists {
+		b, _ = v.Bytes()
+	}
+
+	w.Integer(int64(getBit(b, offset)))
+	return w.Bytes()
+}
+
+type BitCountCommand struct {
+	BaseCommand
+}
+
+func NewBitCountCommand(args [][]byte, flags []*Flag) *BitCountCommand {
+	return &BitCountCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+// https://redis.io/docs/latest/commands/bitcount/
+func (bc *BitCountCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("counting bits...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	v, exists, err := lookupString(rc, bc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	b, _ := v.Bytes()
+	if len(bc.args) == 1 {
+		w.Integer(popCount(b))
+		return w.Bytes()
+	}
+
+	bitUnit := len(bc.flags) > 0 && bc.flags[0].name == BIT
+	start, _ := util.ParseStrictInt(bc.args[1])
+	end, _ := util.ParseStrictInt(bc.args[2])
+
+	total := int64(len(b))
+	if bitUnit {
+		total *= 8
+	}
+
+	from, to, ok := normalizeRange(start, end, total)
+	if !ok {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	if !bitUnit {
+		w.Integer(popCount(b[from : to+1]))
+		return w.Bytes()
+	}
+
+	// count the whole bytes then drop the bits outside of the range in the
+	// first and the last byte
+	first, last := from>>3, to>>3
+	n := popCount(b[first : last+1])
+	n -= int64(bits.OnesCount8(b[first] &^ (0xFF >> (from & 7))))
+	n -= int64(bits.OnesCount8(b[last] & (0xFF >> (to&7 + 1))))
+
+	w.Integer(n)
+	return w.Bytes()
+}
+
+func popCount(b []byte) int64 {
+	var n int
+	for _, c := range b {
+		n += bits.OnesCount8(c)
+	}
+
+	return int64(n)
+}
+
+type BitPosCommand struct {
+	BaseCommand
+}
+
+func NewBitPosCommand(args [][]byte, flags []*Flag) *BitPosCommand {
+	return &BitPosCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+// https://redis.io/docs/latest/commands/bitpos/
+func (bc *BitPosCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("finding bit position...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	bit := uint64(bc.args[1][0] - '0')
+
+	v, exists, err := lookupString(rc, bc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	// a missing key is an empty string, it has no set bits and its first
+	// clear bit is the first one
+	if !exists {
+		if bit == 1 {
+			w.Integer(-1)
+		} else {
+			w.Integer(0)
+		}
+		return w.Bytes()
+	}
+
+	b, _ := v.Bytes()
+	bitUnit := len(bc.flags) > 0 && bc.flags[0].name == BIT
+
+	total := int64(len(b))
+	if bitUnit {
+		total *= 8
+	}
+
+	start, end := int64(0), total-1
+	endGiven := len(bc.args) > 3
+	if len(bc.args) > 2 {
+		start, _ = util.ParseStrictInt(bc.args[2])
+	}
+	if endGiven {
+		end, _ = util.ParseStrictInt(bc.args[3])
+	}
+
+	if start < 0 {
+		start = total + start
+	}
+	if end < 0 {
+		end = total + end
+	}
+	start, end = max(start, 0), max(end, 0)
+	if end >= total {
+		end = total - 1
+	}
+
+	if start > end {
+		w.Integer(-1)
+		return w.Bytes()
+	}
+
+	if !bitUnit {
+		start, end = start*8, end*8+7
+	}
+
+	pos := findBit(b, bit, start, end)
+
+	// without an explicit end the string is considered padded with zeros
+	if pos == -1 && bit == 0 && !endGiven {
+		pos = end + 1
+	}
+
+	w.Integer(pos)
+	return w.Bytes()
+}
+
+// findBit returns the position of the first bit set to bit between the
+// from and to bit offsets, both inclusive, or -1
+func findBit(b []byte, bit uint64, from, to int64) int64 {
+	skip := byte(0x00)
+	if bit == 0 {
+		skip = 0xFF
+	}
+
+	for i := from; i <= to; {
+		// skip the whole bytes that can't hold the bit
+		if i&7 == 0 && i+7 <= to && b[i>>3] == skip {
+			i += 8
+			continue
+		}
+
+		if getBit(b, i) == bit {
+			return i
+		}
+		i++
+	}
+
+	return -1
+}
+
+type BitOpCommand struct {
+	BaseCommand
+}
+
+func NewBitOpCommand(args [][]byte, flags []*Flag) *BitOpCommand {
+	return &BitOpCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+// https://redis.io/docs/latest/commands/bitop/
+func (bc *BitOpCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("bit operation...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	op := bc.flags[0].name
+	dest := bc.args[0]
+
+	srcs := make([][]byte, 0, len(bc.args)-1)
+	n := 0
+	for _, k := range bc.args[1:] {
+		v, exists, err := lookupString(rc, k)
+		if err != nil {
+			w.Error(err)
+			return w.Bytes()
+		}
+
+		var b []byte
+		if exists {
+			b, _ = v.Bytes()
+		}
+		srcs = append(srcs, b)
+		n = max(n, len(b))
+	}
+
+	// shorter strings are padded with zeros
+	byteAt := func(src []byte, i int) byte {
+		if i < len(src) {
+			return src[i]
+		}
+		return 0
+	}
+
+	res := make([]byte, n)
+	for i := range res {
+		x := byteAt(srcs[0], i)
+
+		// others is the OR of every source but the first, once holds the
+		// bits set in at least one source and many in more than one
+		var others, once, many byte
+		for _, src := range srcs[1:] {
+			others |= byteAt(src, i)
+		}
+		for _, src := range srcs {
+			c := byteAt(src, i)
+			many |= once & c
+			once |= c
+		}
+
+		switch op {
+		case AND:
+			r := x
+			for _, src := range srcs[1:] {
+				r &= byteAt(src, i)
+			}
+			res[i] = r
+		case OR:
+			res[i] = once
+		case XOR:
+			r := x
+			for _, src := range srcs[1:] {
+				r ^= byteAt(src, i)
+			}
+			res[i] = r
+		case NOT:
+			res[i] = ^x
+		case DIFF:
+			res[i] = x &^ others
+		case DIFF1:
+			res[i] = others &^ x
+		case ANDOR:
+			res[i] = x & others
+		case ONE:
+			res[i] = once &^ many
+		}
+	}
+
+	// an empty result deletes the destination
+	if n == 0 {
+		if rc.DataStore.Delete(dest) {
+			signalModifiedKey(rc, dest)
+		}
+	} else {
+		rc.DataStore.Set(dest, data.NewStringValue(res, time.Time{}))
+		signalModifiedKey(rc, dest)
+	}
+
+	w.Integer(int64(n))
+	return w.Bytes()
+}
+
+type BitfieldCommand struct {
+	BaseCommand
+	ops []bitfieldOp
+}
+
+func NewBitfieldCommand(args [][]byte, ops []bitfieldOp) *BitfieldCommand {
+	return &BitfieldCommand{
+		BaseCommand{
+			args,
+			nil,
+		},
+		ops,
+	}
+}
+
+// https://redis.io/docs/latest/commands/bitfield/
+func (bc *BitfieldCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("bitfield...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := bc.args[0]
+
+	var size int64
+	writes := false
+	for _, op := range bc.ops {
+		if err := checkBitOffset(rc, op.offset); err != nil {
+			w.Error(err)
+			return w.Bytes()
+		}
+
+		if op.name != GET {
+			writes = true
+			size = max(size, (op.offset+op.bits+7)>>3)
+		}
+	}
+
+	old, exists, err := lookupString(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	var b []byte
+	var expiry time.Time
+	if exists {
+		b, _ = old.Bytes()
+		expiry = old.Expiry()
+	}
+
+	// like SETBIT the string is created and padded before any field is
+	// written, even if every write fails
+	if writes {
+		b = growBitmap(b, size)
+	}
+
+	changes := 0
+	w.ArrayLen(len(bc.ops))
+	for _, op := range bc.ops {
+		prev := getBits(b, op.offset, op.bits)
+		if op.name == GET {
+			writeBitfieldValue(w, op, prev)
+			continue
+		}
+
+		next, ok := op.apply(prev)
+		if !ok {
+			w.Null()
+			continue
+		}
+
+		setBits(b, op.offset, op.bits, next)
+		changes++
+
+		if op.name == SET {
+			writeBitfieldValue(w, op, prev)
+		} else {
+			writeBitfieldValue(w, op, next)
+		}
+	}
+
+	if writes {
+		rc.DataStore.Set(key, data.NewStringValue(b, expiry))
+	}
+	if changes > 0 {
+		signalModifiedKey(rc, key)
+	}
+
+	return w.Bytes()
+}
+
+func writeBitfieldValue(w *ReplyWriter, op bitfieldOp, v uint64) {
+	if op.signed {
+		w.Integer(signExtend(v, op.bits))
+	} else {
+		w.Integer(int64(v))
+	}
+}
+
+// apply computes the new raw value of the field for SET and INCRBY, ok is
+// false when the result overflows and the overflow behaviour is FAIL
+func (op bitfieldOp) apply(prev uint64) (uint64, bool) {
+	var value, incr int64
+	if op.name == SET {
+		value = op.value
+	} else {
+		incr = op.value
+		if op.signed {
+			value = signExtend(prev, op.bits)
+		} else {
+			value = int64(prev)
+		}
+	}
+
+	var res uint64
+	var overflow bool
+	if op.signed {
+		res, overflow = signedBitfieldOverflow(value, incr, op.bits, op.overflow)
+	} else {
+		res, overflow = unsignedBitfieldOverflow(uint64(value), incr, op.bits, op.overflow)
+	}
+
+	if overflow && op.overflow == FAIL {
+		return 0, false
+	}
+
+	if !overflow {
+		res = uint64(value + incr)
+	}
+
+	return res & (math.MaxUint64 >> (64 - op.bits)), true
+}
+
+// unsignedBitfieldOverflow reports whether value+incr fits in n bits, on
+// overflow the result is the wrapped or saturated value
+func unsignedBitfieldOverflow(value uint64, incr, n int64, overflow string) (uint64, bool) {
+	limit := uint64(1)<<n - 1
+	maxIncr := int64(limit - value)
+	minIncr := -int64(value)
+
+	switch {
+	case value > limit || (incr > 0 && incr > maxIncr):
+		if overflow == SAT {
+			return limit, true
+		}
+	case incr < 0 && incr < minIncr:
+		if overflow == SAT {
+			return 0, true
+		}
+	default:
+		return 0, false
+	}
+
+	return (value + uint64(incr)) & limit, true
+}
+
+// signedBitfieldOverflow is the signed version of unsignedBitfieldOverflow,
+// a wrapped result keeps its sign bit propagated
+func signedBitfieldOverflow(value, incr, n int64, overflow string) (uint64, bool) {
+	limit := int64(math.MaxInt64)
+	if n < 64 {
+		limit = 1<<(n-1) - 1
+	}
+	low := -limit - 1
+
+	// these may overflow but are only used once value is known to be in
+	// range, where they can't
+	maxIncr := int64(uint64(limit) - uint64(value))
+	minIncr := low - value
+
+	switch {
+	case value > limit || (n != 64 && incr > maxIncr) || (value >= 0 && incr > 0 && incr > maxIncr):
+		if overflow == SAT {
+			return uint64(limit), true
+		}
+	case value < low || (n != 64 && incr < minIncr) || (value < 0 && incr < 0 && incr < minIncr):
+		if overflow == SAT {
+			return uint64(low), true
+		}
+	default:
+		return 0, false
+	}
+
+	return uint64(signExtend((uint64(value)+uint64(incr))&(math.MaxUint64>>(64-n)), n)), true
+}
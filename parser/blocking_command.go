@@ -0,0 +1,286 @@
+package parser
+
+import (
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/JanitSri/codecrafters-build-your-own-redis/customerror"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/data"
+)
+
+func parseBLPopCmd(args [][]byte) Command {
+	return parseBPopCmd(args, LEFT)
+}
+
+func parseBRPopCmd(args [][]byte) Command {
+	return parseBPopCmd(args, RIGHT)
+}
+
+// https://redis.io/docs/latest/commands/blpop/
+func parseBPopCmd(args [][]byte, where string) Command {
+	timeout, err := parseTimeout(args[len(args)-1])
+	if err != nil {
+		return NewErrorCommand(err)
+	}
+
+	return NewBPopCommand(timeout, args[1:len(args)-1], []*Flag{NewFlag(where, "")})
+}
+
+func parseBLMoveCmd(args [][]byte) Command {
+	from := strings.ToUpper(string(args[3]))
+	to := strings.ToUpper(string(args[4]))
+	if !isListEnd(from) || !isListEnd(to) {
+		return NewErrorCommand(customerror.SyntaxError{})
+	}
+
+	timeout, err := parseTimeout(args[5])
+	if err != nil {
+		return NewErrorCommand(err)
+	}
+
+	return NewBLMoveCommand(timeout, args[1:3], []*Flag{NewFlag(from, ""), NewFlag(to, "")})
+}
+
+func parseBRPopLPushCmd(args [][]byte) Command {
+	timeout, err := parseTimeout(args[3])
+	if err != nil {
+		return NewErrorCommand(err)
+	}
+
+	return NewBLMoveCommand(timeout, args[1:3], []*Flag{NewFlag(RIGHT, ""), NewFlag(LEFT, "")})
+}
+
+// https://redis.io/docs/latest/commands/blmpop/
+func parseBLMPopCmd(args [][]byte) Command {
+	timeout, err := parseTimeout(args[1])
+	if err != nil {
+		return NewErrorCommand(err)
+	}
+
+	keys, flags, err := parseMPopArgs(args[2:])
+	if err != nil {
+		return NewErrorCommand(err)
+	}
+
+	return NewBLMPopCommand(timeout, keys, flags)
+}
+
+// parseTimeout reads the timeout of a blocking command in seconds, with a
+// decimal part for sub second timeouts. Zero blocks forever
+func parseTimeout(b []byte) (time.Duration, error) {
+	f, ok := parseFloat(b)
+	if !ok || math.IsInf(f, 0) {
+		return 0, customerror.TimeoutNotFloatError{}
+	}
+
+	if f < 0 {
+		return 0, customerror.TimeoutNegativeError{}
+	}
+
+	if f > float64(math.MaxInt64)/float64(time.Second) {
+		return 0, customerror.TimeoutOutOfRangeError{}
+	}
+
+	return time.Duration(f * float64(time.Second)), nil
+}
+
+// blockClient waits until serve succeeds for one of the keys, it runs
+// again every time a command signals one of them as ready. The execution
+// lock is released while waiting so other clients can push to the keys,
+// and the replies the client already has are sent before it waits.
+// served is false when the timeout expires or the client disconnects
+func blockClient(rc *data.RedisContext, keys [][]byte, timeout time.Duration, serve func() ([]byte, bool)) ([]byte, bool) {
+	bc := rc.Blocking.Block(rc.Client, keys, serve)
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		expired = t.C
+	}
+
+	rc.Unlock()
+	rc.Client.BeforeBlock()
+	select {
+	case b := <-bc.Reply():
+		rc.Lock()
+		return b, true
+	case <-expired:
+	case <-rc.Client.Closed():
+	}
+	rc.Lock()
+
+	// the client may have been served while it waited for the lock
+	if !rc.Blocking.Unblock(bc) {
+		return <-bc.Reply(), true
+	}
+
+	return nil, false
+}
+
+// BPopCommand implements BLPOP and BRPOP
+type BPopCommand struct {
+	BaseCommand
+	timeout time.Duration
+}
+
+func NewBPopCommand(timeout time.Duration, args [][]byte, flags []*Flag) *BPopCommand {
+	return &BPopCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+		timeout,
+	}
+}
+
+func (bp *BPopCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("blocking pop...")
+
+	b, ok, err := bp.pop(rc)
+	if err != nil || ok {
+		return b
+	}
+
+	b, ok = blockClient(rc, bp.args, bp.timeout, func() ([]byte, bool) {
+		b, ok, err := bp.pop(rc)
+		return b, ok && err == nil
+	})
+	if ok {
+		return b
+	}
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	w.NullArray()
+	return w.Bytes()
+}
+
+// pop takes an element from the first non empty list, ok is false when
+// every list is empty
+func (bp *BPopCommand) pop(rc *data.RedisContext) ([]byte, bool, error) {
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	for _, key := range bp.args {
+		ql, exists, err := lookupList(rc, key)
+		if err != nil {
+			w.Error(err)
+			return w.Bytes(), false, err
+		}
+		if !exists {
+			continue
+		}
+
+		w.ArrayLen(2)
+		w.BulkString(key)
+		w.BulkString(popList(rc, key, ql, bp.flags[0].name))
+		signalModifiedKey(rc, key)
+		return w.Bytes(), true, nil
+	}
+
+	return nil, false, nil
+}
+
+// BLMoveCommand implements BLMOVE and BRPOPLPUSH
+type BLMoveCommand struct {
+	BaseCommand
+	timeout time.Duration
+}
+
+func NewBLMoveCommand(timeout time.Duration, args [][]byte, flags []*Flag) *BLMoveCommand {
+	return &BLMoveCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+		timeout,
+	}
+}
+
+func (bm *BLMoveCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("blocking move...")
+
+	b, ok, err := bm.move(rc)
+	if err != nil || ok {
+		return b
+	}
+
+	// only the source can unblock the client
+	b, ok = blockClient(rc, bm.args[:1], bm.timeout, func() ([]byte, bool) {
+		b, ok, err := bm.move(rc)
+		return b, ok && err == nil
+	})
+	if ok {
+		return b
+	}
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	w.Null()
+	return w.Bytes()
+}
+
+func (bm *BLMoveCommand) move(rc *data.RedisContext) ([]byte, bool, error) {
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	v, err := moveListElement(rc, bm.args[0], bm.args[1], bm.flags[0].name, bm.flags[1].name)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes(), false, err
+	}
+
+	if v == nil {
+		return nil, false, nil
+	}
+
+	w.BulkString(v)
+	return w.Bytes(), true, nil
+}
+
+type BLMPopCommand struct {
+	BaseCommand
+	timeout time.Duration
+}
+
+func NewBLMPopCommand(timeout time.Duration, args [][]byte, flags []*Flag) *BLMPopCommand {
+	return &BLMPopCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+		timeout,
+	}
+}
+
+func (bm *BLMPopCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("blocking pop from lists...")
+
+	b, ok, err := bm.pop(rc)
+	if err != nil || ok {
+		return b
+	}
+
+	b, ok = blockClient(rc, bm.args, bm.timeout, func() ([]byte, bool) {
+		b, ok, err := bm.pop(rc)
+		return b, ok && err == nil
+	})
+	if ok {
+		return b
+	}
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	w.NullArray()
+	return w.Bytes()
+}
+
+func (bm *BLMPopCommand) pop(rc *data.RedisContext) ([]byte, bool, error) {
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	served, err := mpopLists(rc, w, bm.args, bm.flags)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes(), false, err
+	}
+
+	return w.Bytes(), served, nil
+}
@@ -0,0 +1,314 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/JanitSri/codecrafters-build-your-own-redis/customerror"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/data"
+)
+
+func parseClientIdCmd(args [][]byte) Command {
+	return NewClientIdCommand()
+}
+
+func parseClientTrackingCmd(args [][]byte) Command {
+	mode := strings.ToUpper(string(args[2]))
+	if mode != ON && mode != OFF {
+		return NewErrorCommand(customerror.SyntaxError{})
+	}
+
+	flags := []*Flag{}
+	for i := 3; i < len(args); i++ {
+		f := strings.ToUpper(string(args[i]))
+		switch f {
+		case REDIRECT, PREFIX:
+			if i+1 >= len(args) {
+				return NewErrorCommand(customerror.SyntaxError{})
+			}
+			flags = append(flags, NewFlag(f, string(args[i+1])))
+			i++
+		case BCAST, OPTIN, OPTOUT, NOLOOP:
+			flags = append(flags, NewFlag(f, ""))
+		default:
+			return NewErrorCommand(customerror.SyntaxError{})
+		}
+	}
+
+	return NewClientTrackingCommand([][]byte{[]byte(mode)}, flags)
+}
+
+func parseClientCachingCmd(args [][]byte) Command {
+	mode := strings.ToUpper(string(args[2]))
+	if mode != YES && mode != NO {
+		return NewErrorCommand(customerror.SyntaxError{})
+	}
+
+	return NewClientCachingCommand([][]byte{[]byte(mode)}, nil)
+}
+
+func parseClientGetRedirCmd(args [][]byte) Command {
+	return NewClientGetRedirCommand()
+}
+
+func parseClientTrackingInfoCmd(args [][]byte) Command {
+	return NewClientTrackingInfoCommand()
+}
+
+type ClientIdCommand struct {
+}
+
+func NewClientIdCommand() *ClientIdCommand {
+	return &ClientIdCommand{}
+}
+
+func (cc *ClientIdCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("client id...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	w.Integer(rc.Client.Id())
+	return w.Bytes()
+}
+
+type ClientTrackingCommand struct {
+	BaseCommand
+}
+
+func NewClientTrackingCommand(args [][]byte, flags []*Flag) *ClientTrackingCommand {
+	return &ClientTrackingCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+// https://redis.io/docs/latest/commands/client-tracking/
+func (cc *ClientTrackingCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("client tracking...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	old := rc.Client.Tracking()
+
+	if string(cc.args[0]) == OFF {
+		for _, p := range old.Prefixes {
+			rc.Tracking.RemovePrefix(p, rc.Client.Id())
+		}
+		rc.Client.SetTracking(data.TrackingOptions{})
+
+		w.OK()
+		return w.Bytes()
+	}
+
+	t := data.TrackingOptions{Enabled: true}
+	var prefixes [][]byte
+	for _, f := range cc.flags {
+		switch f.name {
+		case REDIRECT:
+			if t.Redirect != 0 {
+				w.Error(customerror.InvalidTrackingOptionError{Reason: "A client can only redirect to a single other client"})
+				return w.Bytes()
+			}
+
+			id, err := strconv.ParseInt(f.value, 10, 64)
+			if err != nil {
+				w.Error(customerror.NotIntegerError{})
+				return w.Bytes()
+			}
+			if _, ok := rc.Clients.Get(id); !ok || id == 0 {
+				w.Error(customerror.InvalidTrackingOptionError{Reason: "The client ID you want redirect to does not exist"})
+				return w.Bytes()
+			}
+			t.Redirect = id
+		case PREFIX:
+			prefixes = append(prefixes, []byte(f.value))
+		case BCAST:
+			t.Bcast = true
+		case OPTIN:
+			t.OptIn = true
+		case OPTOUT:
+			t.OptOut = true
+		case NOLOOP:
+			t.NoLoop = true
+		}
+	}
+
+	if err := checkTrackingOptions(old, t, prefixes); err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if t.Bcast {
+		// without a prefix every key is broadcast
+		if len(prefixes) == 0 && len(old.Prefixes) == 0 {
+			prefixes = append(prefixes, []byte{})
+		}
+		for _, p := range prefixes {
+			rc.Tracking.AddPrefix(p, rc.Client.Id())
+		}
+		t.Prefixes = append(old.Prefixes, prefixes...)
+	}
+
+	rc.Client.SetTracking(t)
+
+	w.OK()
+	return w.Bytes()
+}
+
+func checkTrackingOptions(old, t data.TrackingOptions, prefixes [][]byte) error {
+	if !t.Bcast && len(prefixes) > 0 {
+		return customerror.InvalidTrackingOptionError{Reason: "PREFIX option requires BCAST mode to be enabled"}
+	}
+
+	if old.Enabled && old.Bcast != t.Bcast {
+		return customerror.InvalidTrackingOptionError{Reason: "You can't switch BCAST mode on/off before disabling tracking for this client, and then re-enabling it with a different mode."}
+	}
+
+	if t.OptIn && t.OptOut {
+		return customerror.InvalidTrackingOptionError{Reason: "You can't use both OPTIN and OPTOUT"}
+	}
+
+	if t.Bcast && (t.OptIn || t.OptOut) {
+		return customerror.InvalidTrackingOptionError{Reason: "OPTIN and OPTOUT are not compatible with BCAST"}
+	}
+
+	if old.Enabled && ((old.OptIn && t.OptOut) || (old.OptOut && t.OptIn)) {
+		return customerror.InvalidTrackingOptionError{Reason: "You can't switch OPTIN/OPTOUT mode before disabling tracking for this client, and then re-enabling it with a different mode."}
+	}
+
+	// a key matching two prefixes of the same client would be invalidated
+	// twice so prefixes must not overlap
+	existing := append([][]byte{}, old.Prefixes...)
+	for _, p := range prefixes {
+		for _, e := range existing {
+			if bytes.HasPrefix(p, e) || bytes.HasPrefix(e, p) {
+				return customerror.InvalidTrackingOptionError{Reason: fmt.Sprintf("Prefix '%s' overlaps with an existing prefix '%s'. Prefixes for a single client must not overlap.", p, e)}
+			}
+		}
+		existing = append(existing, p)
+	}
+
+	return nil
+}
+
+type ClientCachingCommand struct {
+	BaseCommand
+}
+
+func NewClientCachingCommand(args [][]byte, flags []*Flag) *ClientCachingCommand {
+	return &ClientCachingCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (cc *ClientCachingCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("client caching...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	t := rc.Client.Tracking()
+
+	switch {
+	case !t.Enabled:
+		w.Error(customerror.InvalidTrackingOptionError{Reason: "CLIENT CACHING can be called only when the client is in tracking mode with OPTIN or OPTOUT mode enabled"})
+	case string(cc.args[0]) == YES && !t.OptIn:
+		w.Error(customerror.InvalidTrackingOptionError{Reason: "CLIENT CACHING YES is only valid when tracking is enabled in OPTIN mode."})
+	case string(cc.args[0]) == NO && !t.OptOut:
+		w.Error(customerror.InvalidTrackingOptionError{Reason: "CLIENT CACHING NO is only valid when tracking is enabled in OPTOUT mode."})
+	default:
+		rc.Client.SetCaching(true)
+		w.OK()
+	}
+
+	return w.Bytes()
+}
+
+type ClientGetRedirCommand struct {
+}
+
+func NewClientGetRedirCommand() *ClientGetRedirCommand {
+	return &ClientGetRedirCommand{}
+}
+
+func (cc *ClientGetRedirCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("client getredir...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	w.Integer(trackingRedirect(rc.Client.Tracking()))
+	return w.Bytes()
+}
+
+// trackingRedirect is -1 when tracking is off and 0 when the client is not
+// redirecting its invalidations
+func trackingRedirect(t data.TrackingOptions) int64 {
+	if !t.Enabled {
+		return -1
+	}
+
+	return t.Redirect
+}
+
+type ClientTrackingInfoCommand struct {
+}
+
+func NewClientTrackingInfoCommand() *ClientTrackingInfoCommand {
+	return &ClientTrackingInfoCommand{}
+}
+
+func (cc *ClientTrackingInfoCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("client trackinginfo...")
+
+	t := rc.Client.Tracking()
+
+	flags := []string{"off"}
+	if t.Enabled {
+		flags = []string{"on"}
+		if t.Bcast {
+			flags = append(flags, "bcast")
+		}
+		if t.OptIn {
+			flags = append(flags, "optin")
+			if rc.Client.Caching() {
+				flags = append(flags, "caching-yes")
+			}
+		}
+		if t.OptOut {
+			flags = append(flags, "optout")
+			if rc.Client.Caching() {
+				flags = append(flags, "caching-no")
+			}
+		}
+		if t.NoLoop {
+			flags = append(flags, "noloop")
+		}
+		if t.Redirect != 0 {
+			if _, ok := rc.Clients.Get(t.Redirect); !ok {
+				flags = append(flags, "broken_redirect")
+			}
+		}
+	}
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	w.MapLen(3)
+	w.BulkStringString("flags")
+	w.SetLen(len(flags))
+	for _, f := range flags {
+		w.BulkStringString(f)
+	}
+
+	w.BulkStringString("redirect")
+	w.Integer(trackingRedirect(t))
+
+	w.BulkStringString("prefixes")
+	w.ArrayLen(len(t.Prefixes))
+	for _, p := range t.Prefixes {
+		w.BulkString(p)
+	}
+
+	return w.Bytes()
+}
@@ -2,10 +2,7 @@ package parser
 
 import (
 	"bytes"
-	"fmt"
 	"log"
-	"reflect"
-	"strconv"
 	"strings"
 	"time"
 
@@ -14,28 +11,6 @@ import (
 	"github.com/JanitSri/codecrafters-build-your-own-redis/util"
 )
 
-func writeBulkString(s string) []byte {
-	l := strconv.Itoa(len(s))
-	var buf bytes.Buffer
-	buf.WriteString(BULK_STRING)
-	buf.WriteString(l)
-	buf.WriteString(REDIS_TERMINATOR)
-	buf.WriteString(s)
-	buf.WriteString(REDIS_TERMINATOR)
-	return buf.Bytes()
-}
-
-func writeSimpleError(err error) []byte {
-	en := reflect.TypeOf(err).Name()
-	em := fmt.Sprintf("%s %s", en, err.Error())
-
-	var buf bytes.Buffer
-	buf.WriteString(SIMPLE_ERROR)
-	buf.WriteString(em)
-	buf.WriteString(REDIS_TERMINATOR)
-	return buf.Bytes()
-}
-
 type Flag struct {
 	name  string
 	value string
@@ -53,7 +28,7 @@ type Command interface {
 }
 
 type BaseCommand struct {
-	args  []string
+	args  [][]byte
 	flags []*Flag
 }
 
@@ -67,16 +42,16 @@ func NewPingCommand() *PingCommand {
 func (pc *PingCommand) Execute(rc *data.RedisContext) []byte {
 	log.Println("ponging...")
 
-	var buf bytes.Buffer
-	buf.WriteString(PONG)
-	return buf.Bytes()
+	w := NewReplyWriter(rc.Client.Protocol())
+	w.SimpleString("PONG")
+	return w.Bytes()
 }
 
 type EchoCommand struct {
 	BaseCommand
 }
 
-func NewEchoCommand(args []string) *EchoCommand {
+func NewEchoCommand(args [][]byte) *EchoCommand {
 	return &EchoCommand{
 		BaseCommand{
 			args,
@@ -88,18 +63,16 @@ func NewEchoCommand(args []string) *EchoCommand {
 func (ec *EchoCommand) Execute(rc *data.RedisContext) []byte {
 	log.Println("echoing...")
 
-	if len(ec.args) != 1 {
-		return writeSimpleError(customerror.InvalidNumberOfArgumentsError{})
-	}
-
-	return writeBulkString(ec.args[0])
+	w := NewReplyWriter(rc.Client.Protocol())
+	w.BulkString(ec.args[0])
+	return w.Bytes()
 }
 
 type SetCommand struct {
 	BaseCommand
 }
 
-func NewSetCommand(args []string, flags []*Flag) *SetCommand {
+func NewSetCommand(args [][]byte, flags []*Flag) *SetCommand {
 	return &SetCommand{
 		BaseCommand{
 			args,
@@ -111,34 +84,74 @@ func NewSetCommand(args []string, flags []*Flag) *SetCommand {
 func (sc *SetCommand) Execute(rc *data.RedisContext) []byte {
 	log.Println("setting...")
 
-	if len(sc.args) != 2 {
-		return writeSimpleError(customerror.InvalidNumberOfArgumentsError{})
-	}
+	w := NewReplyWriter(rc.Client.Protocol())
 
-	v := data.NewRedisValue(sc.args[1], time.Time{})
+	var expiry time.Time
+	var nx, xx, keepTTL, get bool
 	for _, f := range sc.flags {
-		switch strings.ToUpper(f.name) {
-		case PX:
-			ms, _ := strconv.Atoi(f.value)
-			v.SetExpiry(time.Now().Add(time.Duration(ms) * time.Millisecond))
+		switch f.name {
+		case EX, PX, EXAT, PXAT:
+			expiry = expireAt(f.name, f.value, time.Now())
+		case NX:
+			nx = true
+		case XX:
+			xx = true
+		case KEEPTTL:
+			keepTTL = true
+		case GET:
+			get = true
 		default:
-			return writeSimpleError(customerror.InvalidCommandFlagError{Cmd: SET, Flag: f.name})
+			w.Error(customerror.SyntaxError{})
+			return w.Bytes()
 		}
 	}
 
-	rc.DataStore.Set(sc.args[0], v)
+	old, ok := rc.DataStore.Get(sc.args[0])
+	exists := ok && !old.IsExpired()
 
-	var buf bytes.Buffer
-	buf.WriteString(OK)
+	var prev []byte
+	if get && exists {
+		b, isString := old.Bytes()
+		if !isString {
+			w.Error(customerror.WrongTypeError{})
+			return w.Bytes()
+		}
+		prev = b
+	}
 
-	return buf.Bytes()
+	if (nx && exists) || (xx && !exists) {
+		if get && exists {
+			w.BulkString(prev)
+		} else {
+			w.Null()
+		}
+		return w.Bytes()
+	}
+
+	v := data.NewStringValue(sc.args[1], expiry)
+	if keepTTL && exists {
+		v.SetExpiry(old.Expiry())
+	}
+
+	rc.DataStore.Set(sc.args[0], v)
+	signalModifiedKey(rc, sc.args[0])
+
+	switch {
+	case !get:
+		w.OK()
+	case exists:
+		w.BulkString(prev)
+	default:
+		w.Null()
+	}
+	return w.Bytes()
 }
 
 type GetCommand struct {
 	BaseCommand
 }
 
-func NewGetCommand(args []string, flags []*Flag) *GetCommand {
+func NewGetCommand(args [][]byte, flags []*Flag) *GetCommand {
 	return &GetCommand{
 		BaseCommand{
 			args,
@@ -150,32 +163,29 @@ func NewGetCommand(args []string, flags []*Flag) *GetCommand {
 func (gc *GetCommand) Execute(rc *data.RedisContext) []byte {
 	log.Println("getting...")
 
-	if len(gc.args) != 1 {
-		return writeSimpleError(customerror.InvalidNumberOfArgumentsError{})
-	}
+	w := NewReplyWriter(rc.Client.Protocol())
 
-	v, ok := rc.DataStore.Get(gc.args[0])
-	var buf bytes.Buffer
+	v, ok := lookupKey(rc, gc.args[0])
 	if !ok {
-		buf.WriteString(NULL_BULK_STRING)
-		return buf.Bytes()
+		w.Null()
+		return w.Bytes()
 	}
 
-	res := v.(*data.RedisValue)
-	if res.IsExpired() {
-		buf.WriteString(NULL_BULK_STRING)
-		return buf.Bytes()
+	b, isString := v.Bytes()
+	if !isString {
+		w.Error(customerror.WrongTypeError{})
+		return w.Bytes()
 	}
 
-	vs := res.Value().(string)
-	return writeBulkString(vs)
+	w.BulkString(b)
+	return w.Bytes()
 }
 
 type ConfigCommand struct {
 	BaseCommand
 }
 
-func NewConfigCommand(args []string, flags []*Flag) *ConfigCommand {
+func NewConfigCommand(args [][]byte, flags []*Flag) *ConfigCommand {
 	return &ConfigCommand{
 		BaseCommand{
 			args,
@@ -187,30 +197,37 @@ func NewConfigCommand(args []string, flags []*Flag) *ConfigCommand {
 func (cc *ConfigCommand) Execute(rc *data.RedisContext) []byte {
 	log.Println("configuring...")
 
-	var buf bytes.Buffer
+	// https://redis.io/docs/latest/commands/config-get/
+	w := NewReplyWriter(rc.Client.Protocol())
+	tw := NewReplyWriter(rc.Client.Protocol())
+	l := 0
 	for _, f := range cc.flags {
 		switch strings.ToUpper(f.name) {
 		case GET:
-			// todo: update to handle multiple CONFIG GET params
-			// https://redis.io/docs/latest/commands/config-get/
 			cn := f.value
-			cv := rc.DataStore.GetConfig(cn)
-			buf.WriteString(ARRAY + strconv.Itoa(2) + REDIS_TERMINATOR)
-			buf.Write(writeBulkString(cn))
-			buf.Write(writeBulkString(cv))
+			cv, ok := rc.DataStore.GetConfig(cn)
+			if !ok {
+				continue
+			}
+			tw.BulkStringString(cn)
+			tw.BulkStringString(cv)
+			l++
 		default:
-			return writeSimpleError(customerror.InvalidCommandFlagError{Cmd: CONFIG, Flag: f.name})
+			w.Error(customerror.SyntaxError{})
+			return w.Bytes()
 		}
 	}
 
-	return buf.Bytes()
+	w.MapLen(l)
+	w.Append(tw)
+	return w.Bytes()
 }
 
 type KeysCommand struct {
 	BaseCommand
 }
 
-func NewKeysCommand(args []string, flags []*Flag) *KeysCommand {
+func NewKeysCommand(args [][]byte, flags []*Flag) *KeysCommand {
 	return &KeysCommand{
 		BaseCommand{
 			args,
@@ -222,37 +239,70 @@ func NewKeysCommand(args []string, flags []*Flag) *KeysCommand {
 func (kc *KeysCommand) Execute(rc *data.RedisContext) []byte {
 	log.Println("Getting Keys...")
 
-	var buf bytes.Buffer
-
-	if len(kc.args) == 0 {
-		return writeSimpleError(customerror.InvalidNumberOfArgumentsError{})
-	}
+	w := NewReplyWriter(rc.Client.Protocol())
+	tw := NewReplyWriter(rc.Client.Protocol())
 
 	p := kc.args[0]
-	var tempBuf bytes.Buffer
+	all := string(p) == "*"
 	l := 0
 	ks := rc.DataStore.Keys()
 	for _, k := range ks {
-		ks := k.(string)
-		if p == "*" {
-			tempBuf.Write(writeBulkString(ks))
-			l++
+		if !all && !util.GlobMatch(p, k, false) {
+			continue
 		}
+
+		// expired keys are deleted instead of listed
+		if _, ok := lookupKey(rc, k); !ok {
+			continue
+		}
+
+		tw.BulkString(k)
+		l++
 	}
 
-	buf.WriteString(ARRAY)
-	buf.WriteString(strconv.Itoa(l))
-	buf.WriteString(REDIS_TERMINATOR)
-	tempBuf.WriteTo(&buf)
+	w.ArrayLen(l)
+	w.Append(tw)
+	return w.Bytes()
+}
 
-	return buf.Bytes()
+func parseSaveCmd(args [][]byte) Command {
+	return NewSaveCommand(nil, nil)
+}
+
+// SaveCommand writes the dataset to the RDB file while holding the
+// execution lock, no client is served until it is done
+type SaveCommand struct {
+	BaseCommand
+}
+
+func NewSaveCommand(args [][]byte, flags []*Flag) *SaveCommand {
+	return &SaveCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (sc *SaveCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("saving...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	if err := SaveRDBFile(rc.DataStore); err != nil {
+		log.Printf("error saving the RDB file: %v\n", err)
+		w.Error(customerror.RDBSaveError{Err: err})
+		return w.Bytes()
+	}
+
+	w.OK()
+	return w.Bytes()
 }
 
 type InfoCommand struct {
 	BaseCommand
 }
 
-func NewInfoCommand(args []string, flags []*Flag) *InfoCommand {
+func NewInfoCommand(args [][]byte, flags []*Flag) *InfoCommand {
 	return &InfoCommand{
 		BaseCommand{
 			args,
@@ -266,23 +316,411 @@ func (ic *InfoCommand) Execute(rc *data.RedisContext) []byte {
 
 	var arg string
 	if len(ic.args) >= 1 {
-		arg = ic.args[0]
+		arg = string(ic.args[0])
+	}
+
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	sections := []struct {
+		name  string
+		value any
+	}{
+		{STATS, *rc.RedisInfo.Stats},
+		{REPLICATION, *rc.RedisInfo.Replication},
 	}
 
-	var buf bytes.Buffer
-	switch strings.ToUpper(arg) {
-	case REPLICATION:
-		s, err := util.SerializeSection(*rc.RedisInfo.Replication)
+	// sections that are not implemented yet produce an empty reply
+	var sb strings.Builder
+	for _, sec := range sections {
+		if arg != "" && !strings.EqualFold(arg, sec.name) {
+			continue
+		}
+
+		s, err := util.SerializeSection(sec.value)
 		if err != nil {
-			return writeSimpleError(err)
+			w.Error(err)
+			return w.Bytes()
 		}
-		sb := writeBulkString(s)
-		buf.Write(sb)
-	default:
-		return writeSimpleError(customerror.InvalidNumberOfArgumentsError{})
+
+		if sb.Len() > 0 {
+			sb.WriteString("\r\n")
+		}
+		sb.WriteString("# " + sec.name[:1] + strings.ToLower(sec.name[1:]) + "\r\n")
+		sb.WriteString(s)
+	}
+
+	w.VerbatimString("txt", []byte(sb.String()))
+	return w.Bytes()
+}
+
+type HelloCommand struct {
+	BaseCommand
+	protover int
+	username []byte
+	password []byte
+	name     []byte
+}
+
+func NewHelloCommand(protover int, username, password, name []byte) *HelloCommand {
+	return &HelloCommand{
+		BaseCommand{
+			nil,
+			nil,
+		},
+		protover,
+		username,
+		password,
+		name,
+	}
+}
+
+func (hc *HelloCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("helloing...")
+
+	if hc.protover != 0 && hc.protover != data.RESP2 && hc.protover != data.RESP3 {
+		w := NewReplyWriter(rc.Client.Protocol())
+		w.Error(customerror.UnsupportedProtocolError{})
+		return w.Bytes()
+	}
+
+	// there are no ACL users yet, the default user has no password so any
+	// password is accepted for it
+	if hc.username != nil && string(hc.username) != DEFAULT_USER {
+		w := NewReplyWriter(rc.Client.Protocol())
+		w.Error(customerror.InvalidCredentialsError{})
+		return w.Bytes()
+	}
+
+	if hc.name != nil {
+		rc.Client.SetName(hc.name)
+	}
+	if hc.protover != 0 {
+		rc.Client.SetProtocol(hc.protover)
+	}
+
+	// the reply is already encoded in the protocol that was just negotiated
+	w := NewReplyWriter(rc.Client.Protocol())
+	w.MapLen(7)
+	w.BulkStringString("server")
+	w.BulkStringString(SERVER_NAME)
+	w.BulkStringString("version")
+	w.BulkStringString(SERVER_VERSION)
+	w.BulkStringString("proto")
+	w.Integer(int64(rc.Client.Protocol()))
+	w.BulkStringString("id")
+	w.Integer(rc.Client.Id())
+	w.BulkStringString("mode")
+	w.BulkStringString("standalone")
+	w.BulkStringString("role")
+	w.BulkStringString(rc.RedisInfo.Replication.Role)
+	w.BulkStringString("modules")
+	w.ArrayLen(0)
+	return w.Bytes()
+}
+
+type CommandCountCommand struct {
+}
+
+func NewCommandCountCommand() *CommandCountCommand {
+	return &CommandCountCommand{}
+}
+
+func (cc *CommandCountCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("counting commands...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	w.Integer(int64(len(commandTable)))
+	return w.Bytes()
+}
+
+type CommandInfoCommand struct {
+	BaseCommand
+}
+
+func NewCommandInfoCommand(args [][]byte, flags []*Flag) *CommandInfoCommand {
+	return &CommandInfoCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (cc *CommandInfoCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("command info...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	if len(cc.args) == 0 {
+		specs := sortedCommands()
+		w.ArrayLen(len(specs))
+		for _, cs := range specs {
+			writeCommandInfo(w, cs)
+		}
+		return w.Bytes()
+	}
+
+	w.ArrayLen(len(cc.args))
+	for _, n := range cc.args {
+		cs, ok := lookupCommandOrSubcommand(n)
+		if !ok {
+			w.Null()
+			continue
+		}
+		writeCommandInfo(w, cs)
+	}
+
+	return w.Bytes()
+}
+
+// lookupCommandOrSubcommand resolves both plain names and the
+// container|subcommand form
+func lookupCommandOrSubcommand(name []byte) (*CommandSpec, bool) {
+	c, sub, found := bytes.Cut(name, []byte("|"))
+	cs, ok := lookupCommand(c)
+	if !ok || !found {
+		return cs, ok
+	}
+
+	return cs.subcommand(sub)
+}
+
+// https://redis.io/docs/latest/commands/command/
+func writeCommandInfo(w *ReplyWriter, cs *CommandSpec) {
+	w.ArrayLen(10)
+	w.BulkStringString(cs.FullName())
+	w.Integer(int64(cs.Arity))
+
+	w.SetLen(len(cs.Flags))
+	for _, f := range cs.Flags {
+		w.SimpleString(f)
+	}
+
+	w.Integer(int64(cs.FirstKey))
+	w.Integer(int64(cs.LastKey))
+	w.Integer(int64(cs.Step))
+
+	w.SetLen(len(cs.ACLCategories))
+	for _, c := range cs.ACLCategories {
+		w.SimpleString(c)
+	}
+
+	// tips
+	w.ArrayLen(0)
+
+	if cs.FirstKey > 0 {
+		w.ArrayLen(1)
+		writeKeySpec(w, cs)
+	} else {
+		w.ArrayLen(0)
+	}
+
+	w.ArrayLen(len(cs.Subcommands))
+	for _, sc := range cs.Subcommands {
+		writeCommandInfo(w, sc)
+	}
+}
+
+// writeKeySpec describes the legacy first/last/step key range as a single
+// index based key specification
+func writeKeySpec(w *ReplyWriter, cs *CommandSpec) {
+	flags := []string{"RW", "UPDATE"}
+	if cs.HasFlag(FLAG_READONLY) {
+		flags = []string{"RO", "ACCESS"}
+	}
+
+	lastKey := cs.LastKey
+	if lastKey >= 0 {
+		lastKey -= cs.FirstKey
+	}
+
+	w.MapLen(3)
+	w.BulkStringString("flags")
+	w.SetLen(len(flags))
+	for _, f := range flags {
+		w.SimpleString(f)
+	}
+
+	w.BulkStringString("begin_search")
+	w.MapLen(2)
+	w.BulkStringString("type")
+	w.BulkStringString("index")
+	w.BulkStringString("spec")
+	w.MapLen(1)
+	w.BulkStringString("index")
+	w.Integer(int64(cs.FirstKey))
+
+	w.BulkStringString("find_keys")
+	w.MapLen(2)
+	w.BulkStringString("type")
+	w.BulkStringString("range")
+	w.BulkStringString("spec")
+	w.MapLen(3)
+	w.BulkStringString("lastkey")
+	w.Integer(int64(lastKey))
+	w.BulkStringString("keystep")
+	w.Integer(int64(cs.Step))
+	w.BulkStringString("limit")
+	w.Integer(0)
+}
+
+type CommandDocsCommand struct {
+	BaseCommand
+}
+
+func NewCommandDocsCommand(args [][]byte, flags []*Flag) *CommandDocsCommand {
+	return &CommandDocsCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (cc *CommandDocsCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("command docs...")
+
+	var specs []*CommandSpec
+	if len(cc.args) == 0 {
+		specs = sortedCommands()
+	} else {
+		for _, n := range cc.args {
+			if cs, ok := lookupCommandOrSubcommand(n); ok {
+				specs = append(specs, cs)
+			}
+		}
+	}
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	w.MapLen(len(specs))
+	for _, cs := range specs {
+		w.BulkStringString(cs.FullName())
+		writeCommandDocs(w, cs)
+	}
+
+	return w.Bytes()
+}
+
+func writeCommandDocs(w *ReplyWriter, cs *CommandSpec) {
+	n := 3
+	if len(cs.Subcommands) > 0 {
+		n++
+	}
+
+	w.MapLen(n)
+	w.BulkStringString("summary")
+	w.SimpleString(cs.Summary)
+	w.BulkStringString("since")
+	w.SimpleString(cs.Since)
+	w.BulkStringString("group")
+	w.SimpleString(cs.Group)
+
+	if len(cs.Subcommands) > 0 {
+		w.BulkStringString("subcommands")
+		w.MapLen(len(cs.Subcommands))
+		for _, sc := range cs.Subcommands {
+			w.BulkStringString(sc.FullName())
+			writeCommandDocs(w, sc)
+		}
+	}
+}
+
+type CommandListCommand struct {
+	BaseCommand
+}
+
+func NewCommandListCommand(args [][]byte, flags []*Flag) *CommandListCommand {
+	return &CommandListCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (cc *CommandListCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("listing commands...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	tw := NewReplyWriter(rc.Client.Protocol())
+	l := 0
+	for _, cs := range sortedCommands() {
+		for _, s := range append([]*CommandSpec{cs}, cs.Subcommands...) {
+			if !cc.matches(s) {
+				continue
+			}
+			tw.BulkStringString(s.FullName())
+			l++
+		}
+	}
+
+	w.ArrayLen(l)
+	w.Append(tw)
+	return w.Bytes()
+}
+
+func (cc *CommandListCommand) matches(cs *CommandSpec) bool {
+	for _, f := range cc.flags {
+		switch f.name {
+		case MODULE:
+			// there is no module support, no command belongs to a module
+			return false
+		case ACLCAT:
+			return cs.HasACLCategory(f.value)
+		case PATTERN:
+			return util.GlobMatch([]byte(f.value), []byte(cs.FullName()), true)
+		}
+	}
+
+	return true
+}
+
+type CommandGetKeysCommand struct {
+	BaseCommand
+}
+
+func NewCommandGetKeysCommand(args [][]byte, flags []*Flag) *CommandGetKeysCommand {
+	return &CommandGetKeysCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (cc *CommandGetKeysCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting command keys...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	cs, ok := lookupCommand(cc.args[0])
+	if !ok {
+		w.Error(customerror.InvalidCommandError{})
+		return w.Bytes()
+	}
+
+	if len(cs.Subcommands) > 0 && len(cc.args) > 1 {
+		if sc, ok := cs.subcommand(cc.args[1]); ok {
+			cs = sc
+		}
+	}
+
+	if !cs.checkArity(len(cc.args)) {
+		w.Error(customerror.InvalidCommandArgumentsError{})
+		return w.Bytes()
+	}
+
+	pos := cs.keyPositions(cc.args)
+	if len(pos) == 0 {
+		w.Error(customerror.NoKeyArgumentsError{})
+		return w.Bytes()
+	}
+
+	w.ArrayLen(len(pos))
+	for _, p := range pos {
+		w.BulkString(cc.args[p])
 	}
 
-	return buf.Bytes()
+	return w.Bytes()
 }
 
 type ErrorCommand struct {
@@ -295,6 +733,8 @@ func NewErrorCommand(err error) *ErrorCommand {
 	}
 }
 
-func (ec *ErrorCommand) Execute(_ *data.RedisContext) []byte {
-	return writeSimpleError(ec.err)
+func (ec *ErrorCommand) Execute(rc *data.RedisContext) []byte {
+	w := NewReplyWriter(rc.Client.Protocol())
+	w.Error(ec.err)
+	return w.Bytes()
 }
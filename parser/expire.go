@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/JanitSri/codecrafters-build-your-own-redis/data"
+)
+
+// https://github.com/redis/redis/blob/unstable/src/expire.c
+const (
+	// keys sampled from the volatile keys on every iteration of the cycle
+	ACTIVE_EXPIRE_CYCLE_KEYS_PER_LOOP = 20
+	// percentage of each tick the cycle may spend holding the execution
+	// lock to expire keys, redis budgets cpu time instead
+	ACTIVE_EXPIRE_CYCLE_SLOW_TIME_PERC = 25
+	// the cycle stops once at most this percentage of the sampled keys
+	// were already expired
+	ACTIVE_EXPIRE_CYCLE_ACCEPTABLE_STALE = 10
+)
+
+// ActiveExpire removes expired keys and hash fields in the background so
+// the ones that are never accessed again do not stay in memory forever
+type ActiveExpire struct {
+	stalePerc float64
+	// time spent expiring across all cycles, kept as a duration so the
+	// cycles shorter than a millisecond still add up
+	elapsed time.Duration
+}
+
+func NewActiveExpire() *ActiveExpire {
+	return &ActiveExpire{}
+}
+
+// Cycle samples random keys with an expiry and deletes the expired ones,
+// it repeats while more than ACTIVE_EXPIRE_CYCLE_ACCEPTABLE_STALE percent
+// of the sample was expired and the time budget allows. Hashes with field
+// expiries are then sampled the same way within what is left of the
+// budget. The execution lock is only held for one sample at a time so
+// clients are served in between, the budget is the wall time spent holding
+// it rather than the cpu time redis measures
+func (ae *ActiveExpire) Cycle(rc *data.RedisContext) {
+	hz := rc.DataStore.Config().Hz()
+	limit := time.Second * ACTIVE_EXPIRE_CYCLE_SLOW_TIME_PERC / time.Duration(hz) / 100
+
+	var elapsed time.Duration
+	step := func(sample func(*data.RedisContext) (int, int)) (int, int) {
+		rc.Lock()
+		defer rc.Unlock()
+
+		start := time.Now()
+		n, e := sample(rc)
+		elapsed += time.Since(start)
+		return n, e
+	}
+
+	var sampled, expired int
+	var capped bool
+	for {
+		n, e := step(ae.sample)
+		sampled += n
+		expired += e
+
+		if n == 0 || e*100 <= n*ACTIVE_EXPIRE_CYCLE_ACCEPTABLE_STALE {
+			break
+		}
+
+		if capped = elapsed > limit; capped {
+			break
+		}
+	}
+
+	for !capped {
+		n, e := step(ae.sampleFields)
+
+		if n == 0 || e*100 <= n*ACTIVE_EXPIRE_CYCLE_ACCEPTABLE_STALE {
+			break
+		}
+
+		capped = elapsed > limit
+	}
+
+	// a moving average of the percentage of keys found expired, it hints
+	// how many expired keys are still in memory
+	var perc float64
+	if sampled > 0 {
+		perc = float64(expired) / float64(sampled)
+	}
+	ae.stalePerc = perc*0.05 + ae.stalePerc*0.95
+
+	rc.Lock()
+	stats := rc.RedisInfo.Stats
+	if capped {
+		stats.ExpiredTimeCapReachedCount++
+	}
+	stats.ExpiredStalePerc = fmt.Sprintf("%.2f", ae.stalePerc*100)
+	ae.elapsed += elapsed
+	stats.ExpireCycleLockedMilliseconds = ae.elapsed.Milliseconds()
+	rc.Unlock()
+}
+
+// sample checks ACTIVE_EXPIRE_CYCLE_KEYS_PER_LOOP keys and reports how many
+// were sampled and how many of them were deleted
+func (ae *ActiveExpire) sample(rc *data.RedisContext) (int, int) {
+	n := min(rc.DataStore.VolatileLen(), ACTIVE_EXPIRE_CYCLE_KEYS_PER_LOOP)
+
+	var expired int
+	for _, k := range rc.DataStore.SampleVolatile(n) {
+		if v, ok := rc.DataStore.Get(k); ok && v.IsExpired() && expireKey(rc, k) {
+			expired++
+		}
+	}
+
+	return n, expired
+}
+
+// sampleFields checks ACTIVE_EXPIRE_CYCLE_KEYS_PER_LOOP hashes with field
+// expiries and reports how many were sampled and how many of them had
+// expired fields
+func (ae *ActiveExpire) sampleFields(rc *data.RedisContext) (int, int) {
+	fs, ok := rc.DataStore.(data.FieldVolatileSampler)
+	if !ok {
+		return 0, 0
+	}
+
+	n := min(fs.FieldVolatileLen(), ACTIVE_EXPIRE_CYCLE_KEYS_PER_LOOP)
+
+	var expired int
+	for _, k := range fs.SampleFieldVolatile(n) {
+		v, ok := rc.DataStore.Get(k)
+		if !ok {
+			continue
+		}
+
+		if h, isHash := v.Value().(*data.Hash); isHash && expireHashFields(rc, k, h) > 0 {
+			expired++
+		}
+	}
+
+	return n, expired
+}
+
+// expireKey deletes a key whose expiry passed and counts it as expired,
+// clients tracking the key are told it changed
+func expireKey(rc *data.RedisContext, key []byte) bool {
+	if !rc.DataStore.Delete(key) {
+		return false
+	}
+
+	rc.RedisInfo.Stats.ExpiredKeys++
+	// no client caused the change so even NOLOOP clients are told
+	signalModifiedKey(rc.ForClient(nil), key)
+	return true
+}
+
+// expireHashFields deletes the fields of the hash whose expiry passed,
+// counts them as expired and returns how many there were. The key is
+// deleted once the hash has no fields left
+func expireHashFields(rc *data.RedisContext, key []byte, h *data.Hash) int {
+	n := h.ExpireFields(time.Now())
+	if n == 0 {
+		return 0
+	}
+
+	rc.RedisInfo.Stats.ExpiredSubkeys += int64(n)
+	if h.Len() == 0 {
+		rc.DataStore.Delete(key)
+	} else {
+		rc.DataStore.ReindexFieldExpiry(key)
+	}
+
+	signalModifiedKey(rc.ForClient(nil), key)
+	return n
+}
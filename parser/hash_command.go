@@ -0,0 +1,772 @@
+package parser
+
+import (
+	"log"
+	"math"
+	"math/big"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JanitSri/codecrafters-build-your-own-redis/customerror"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/data"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/util"
+)
+
+// https://redis.io/docs/latest/commands/hset/
+func parseHSetCmd(args [][]byte) Command {
+	if len(args)%2 != 0 {
+		return NewErrorCommand(customerror.WrongNumberOfArgumentsError{Cmd: string(args[0])})
+	}
+
+	return NewHSetCommand(args[1:], nil)
+}
+
+func parseHSetNXCmd(args [][]byte) Command {
+	return NewHSetCommand(args[1:4], []*Flag{NewFlag(NX, "")})
+}
+
+func parseHGetCmd(args [][]byte) Command {
+	return NewHGetCommand(args[1:3], nil)
+}
+
+func parseHMGetCmd(args [][]byte) Command {
+	return NewHMGetCommand(args[1:], nil)
+}
+
+func parseHDelCmd(args [][]byte) Command {
+	return NewHDelCommand(args[1:], nil)
+}
+
+func parseHGetAllCmd(args [][]byte) Command {
+	return NewHGetAllCommand([][]byte{args[1]}, nil)
+}
+
+func parseHKeysCmd(args [][]byte) Command {
+	return NewHKeysCommand([][]byte{args[1]}, nil)
+}
+
+func parseHValsCmd(args [][]byte) Command {
+	return NewHValsCommand([][]byte{args[1]}, nil)
+}
+
+func parseHExistsCmd(args [][]byte) Command {
+	return NewHExistsCommand(args[1:3], nil)
+}
+
+func parseHLenCmd(args [][]byte) Command {
+	return NewHLenCommand([][]byte{args[1]}, nil)
+}
+
+func parseHStrLenCmd(args [][]byte) Command {
+	return NewHStrLenCommand(args[1:3], nil)
+}
+
+func parseHIncrByCmd(args [][]byte) Command {
+	if _, ok := util.ParseStrictInt(args[3]); !ok {
+		return NewErrorCommand(customerror.NotIntegerError{})
+	}
+
+	return NewHIncrByCommand(args[1:4], nil)
+}
+
+func parseHIncrByFloatCmd(args [][]byte) Command {
+	if f, ok := parseLongDouble(args[3]); !ok || f.IsInf() {
+		return NewErrorCommand(customerror.NotFloatError{})
+	}
+
+	return NewHIncrByFloatCommand(args[1:4], nil)
+}
+
+// https://redis.io/docs/latest/commands/hrandfield/
+func parseHRandFieldCmd(args [][]byte) Command {
+	if len(args) > 4 {
+		return NewErrorCommand(customerror.SyntaxError{})
+	}
+
+	var flags []*Flag
+	if len(args) > 2 {
+		n, ok := util.ParseStrictInt(args[2])
+		if !ok || n == math.MinInt64 {
+			return NewErrorCommand(customerror.NotIntegerError{})
+		}
+		flags = append(flags, NewFlag(COUNT, string(args[2])))
+
+		if len(args) == 4 {
+			if strings.ToUpper(string(args[3])) != WITHVALUES {
+				return NewErrorCommand(customerror.SyntaxError{})
+			}
+
+			// every field is sent with its value so the reply can't be
+			// allowed to overflow
+			if n < -math.MaxInt64/2 {
+				return NewErrorCommand(customerror.ValueOutOfRangeError{})
+			}
+			flags = append(flags, NewFlag(WITHVALUES, ""))
+		}
+	}
+
+	return NewHRandFieldCommand([][]byte{args[1]}, flags)
+}
+
+// lookupHash returns the hash stored at key, exists is false when the key
+// is missing or expired
+func lookupHash(rc *data.RedisContext, key []byte) (*data.Hash, bool, error) {
+	v, ok := lookupKey(rc, key)
+	if !ok {
+		return nil, false, nil
+	}
+
+	h, isHash := v.Value().(*data.Hash)
+	if !isHash {
+		return nil, true, customerror.WrongTypeError{}
+	}
+
+	return h, true, nil
+}
+
+// lookupOrCreateHash returns the hash stored at key, an empty hash is
+// stored first when the key is missing
+func lookupOrCreateHash(rc *data.RedisContext, key []byte) (*data.Hash, error) {
+	h, exists, err := lookupHash(rc, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		h = data.NewHash()
+		rc.DataStore.Set(key, data.NewRedisValue(h, time.Time{}))
+	}
+
+	return h, nil
+}
+
+// setHashField stores the value of the field and reports whether the field
+// is new, an existing field loses its expiry unless keepTTL is set. A
+// compact hash is converted once it outgrows the hash-max-listpack-*
+// limits
+func setHashField(rc *data.RedisContext, h *data.Hash, field, value []byte, keepTTL bool) bool {
+	cfg := rc.DataStore.Config()
+
+	var isNew bool
+	if keepTTL {
+		isNew = h.SetKeepTTL(field, value)
+	} else {
+		isNew = h.Set(field, value)
+	}
+
+	if h.Encoding() == data.ENCODING_LISTPACK &&
+		(int64(h.Len()) > cfg.HashMaxListpackEntries() ||
+			int64(len(field)) > cfg.HashMaxListpackValue() ||
+			int64(len(value)) > cfg.HashMaxListpackValue()) {
+		h.Convert()
+	}
+
+	return isNew
+}
+
+// hashChanged is called once a command changed the fields of a hash or
+// their expiries, a hash left without fields is deleted and the others are
+// reindexed by whether they have fields that expire
+func hashChanged(rc *data.RedisContext, key []byte, h *data.Hash) {
+	if h.Len() == 0 {
+		rc.DataStore.Delete(key)
+	} else {
+		rc.DataStore.ReindexFieldExpiry(key)
+	}
+
+	signalModifiedKey(rc, key)
+}
+
+// HSetCommand implements HSET, and HSETNX when the NX flag is set
+type HSetCommand struct {
+	BaseCommand
+}
+
+func NewHSetCommand(args [][]byte, flags []*Flag) *HSetCommand {
+	return &HSetCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (hc *HSetCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("setting hash fields...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := hc.args[0]
+	nx := len(hc.flags) > 0
+
+	h, err := lookupOrCreateHash(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if nx {
+		if _, ok := h.Get(hc.args[1]); ok {
+			w.Integer(0)
+			return w.Bytes()
+		}
+	}
+
+	added := 0
+	for i := 1; i < len(hc.args); i += 2 {
+		if setHashField(rc, h, hc.args[i], hc.args[i+1], false) {
+			added++
+		}
+	}
+	hashChanged(rc, key, h)
+
+	w.Integer(int64(added))
+	return w.Bytes()
+}
+
+type HGetCommand struct {
+	BaseCommand
+}
+
+func NewHGetCommand(args [][]byte, flags []*Flag) *HGetCommand {
+	return &HGetCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (hc *HGetCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting hash field...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	h, exists, err := lookupHash(rc, hc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.Null()
+		return w.Bytes()
+	}
+
+	v, ok := h.Get(hc.args[1])
+	if !ok {
+		w.Null()
+		return w.Bytes()
+	}
+
+	w.BulkString(v)
+	return w.Bytes()
+}
+
+type HMGetCommand struct {
+	BaseCommand
+}
+
+func NewHMGetCommand(args [][]byte, flags []*Flag) *HMGetCommand {
+	return &HMGetCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (hc *HMGetCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting hash fields...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	h, exists, err := lookupHash(rc, hc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	fields := hc.args[1:]
+	w.ArrayLen(len(fields))
+	for _, f := range fields {
+		if !exists {
+			w.Null()
+			continue
+		}
+
+		v, ok := h.Get(f)
+		if !ok {
+			w.Null()
+			continue
+		}
+		w.BulkString(v)
+	}
+
+	return w.Bytes()
+}
+
+type HDelCommand struct {
+	BaseCommand
+}
+
+func NewHDelCommand(args [][]byte, flags []*Flag) *HDelCommand {
+	return &HDelCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (hc *HDelCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("deleting hash fields...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := hc.args[0]
+	h, exists, err := lookupHash(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	deleted := 0
+	for _, f := range hc.args[1:] {
+		if h.Delete(f) {
+			deleted++
+		}
+	}
+
+	if deleted > 0 {
+		hashChanged(rc, key, h)
+	}
+
+	w.Integer(int64(deleted))
+	return w.Bytes()
+}
+
+type HGetAllCommand struct {
+	BaseCommand
+}
+
+func NewHGetAllCommand(args [][]byte, flags []*Flag) *HGetAllCommand {
+	return &HGetAllCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (hc *HGetAllCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting all hash fields...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	h, exists, err := lookupHash(rc, hc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.MapLen(0)
+		return w.Bytes()
+	}
+
+	w.MapLen(h.Len())
+	h.Iterate(func(field, value []byte) bool {
+		w.BulkString(field)
+		w.BulkString(value)
+		return true
+	})
+
+	return w.Bytes()
+}
+
+type HKeysCommand struct {
+	BaseCommand
+}
+
+func NewHKeysCommand(args [][]byte, flags []*Flag) *HKeysCommand {
+	return &HKeysCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (hc *HKeysCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting hash field names...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	h, exists, err := lookupHash(rc, hc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.ArrayLen(0)
+		return w.Bytes()
+	}
+
+	w.ArrayLen(h.Len())
+	h.Iterate(func(field, _ []byte) bool {
+		w.BulkString(field)
+		return true
+	})
+
+	return w.Bytes()
+}
+
+type HValsCommand struct {
+	BaseCommand
+}
+
+func NewHValsCommand(args [][]byte, flags []*Flag) *HValsCommand {
+	return &HValsCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (hc *HValsCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting hash values...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	h, exists, err := lookupHash(rc, hc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.ArrayLen(0)
+		return w.Bytes()
+	}
+
+	w.ArrayLen(h.Len())
+	h.Iterate(func(_, value []byte) bool {
+		w.BulkString(value)
+		return true
+	})
+
+	return w.Bytes()
+}
+
+type HExistsCommand struct {
+	BaseCommand
+}
+
+func NewHExistsCommand(args [][]byte, flags []*Flag) *HExistsCommand {
+	return &HExistsCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (hc *HExistsCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("checking hash field...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	h, exists, err := lookupHash(rc, hc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if exists {
+		if _, ok := h.Get(hc.args[1]); ok {
+			w.Integer(1)
+			return w.Bytes()
+		}
+	}
+
+	w.Integer(0)
+	return w.Bytes()
+}
+
+type HLenCommand struct {
+	BaseCommand
+}
+
+func NewHLenCommand(args [][]byte, flags []*Flag) *HLenCommand {
+	return &HLenCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (hc *HLenCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting hash length...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	h, exists, err := lookupHash(rc, hc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	w.Integer(int64(h.Len()))
+	return w.Bytes()
+}
+
+type HStrLenCommand struct {
+	BaseCommand
+}
+
+func NewHStrLenCommand(args [][]byte, flags []*Flag) *HStrLenCommand {
+	return &HStrLenCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (hc *HStrLenCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting hash value length...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	h, exists, err := lookupHash(rc, hc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if exists {
+		if v, ok := h.Get(hc.args[1]); ok {
+			w.Integer(int64(len(v)))
+			return w.Bytes()
+		}
+	}
+
+	w.Integer(0)
+	return w.Bytes()
+}
+
+type HIncrByCommand struct {
+	BaseCommand
+}
+
+func NewHIncrByCommand(args [][]byte, flags []*Flag) *HIncrByCommand {
+	return &HIncrByCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (hc *HIncrByCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("incrementing hash field...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key, field := hc.args[0], hc.args[1]
+	incr, _ := util.ParseStrictInt(hc.args[2])
+
+	h, err := lookupOrCreateHash(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	var n int64
+	if b, ok := h.Get(field); ok {
+		v, ok := util.ParseStrictInt(b)
+		if !ok {
+			w.Error(customerror.HashValueNotIntegerError{})
+			return w.Bytes()
+		}
+		n = v
+	}
+
+	if (incr < 0 && n < 0 && incr < math.MinInt64-n) || (incr > 0 && n > 0 && incr > math.MaxInt64-n) {
+		w.Error(customerror.IncrOverflowError{})
+		return w.Bytes()
+	}
+	n += incr
+
+	setHashField(rc, h, field, strconv.AppendInt(nil, n, 10), true)
+	signalModifiedKey(rc, key)
+
+	w.Integer(n)
+	return w.Bytes()
+}
+
+type HIncrByFloatCommand struct {
+	BaseCommand
+}
+
+func NewHIncrByFloatCommand(args [][]byte, flags []*Flag) *HIncrByFloatCommand {
+	return &HIncrByFloatCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (hc *HIncrByFloatCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("incrementing hash field by float...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key, field := hc.args[0], hc.args[1]
+	incr, _ := parseLongDouble(hc.args[2])
+
+	h, err := lookupOrCreateHash(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	f := new(big.Float).SetPrec(longDoublePrec)
+	if b, ok := h.Get(field); ok {
+		v, ok := parseLongDouble(b)
+		if !ok {
+			w.Error(customerror.HashValueNotFloatError{})
+			return w.Bytes()
+		}
+		f = v
+	}
+
+	f, ok := incrLongDouble(f, incr)
+	if !ok {
+		w.Error(customerror.NaNOrInfinityError{})
+		return w.Bytes()
+	}
+
+	b := formatLongDouble(f)
+	setHashField(rc, h, field, b, true)
+	signalModifiedKey(rc, key)
+
+	w.BulkString(b)
+	return w.Bytes()
+}
+
+// HRandFieldCommand returns a single random field without the COUNT flag,
+// otherwise count distinct fields or, when count is negative, that many
+// fields which may repeat
+type HRandFieldCommand struct {
+	BaseCommand
+}
+
+func NewHRandFieldCommand(args [][]byte, flags []*Flag) *HRandFieldCommand {
+	return &HRandFieldCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (hc *HRandFieldCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting random hash fields...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	hasCount := len(hc.flags) > 0
+	withValues := len(hc.flags) > 1
+
+	h, exists, err := lookupHash(rc, hc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		if hasCount {
+			w.ArrayLen(0)
+		} else {
+			w.Null()
+		}
+		return w.Bytes()
+	}
+
+	if !hasCount {
+		f, _ := h.Random()
+		w.BulkString(f)
+		return w.Bytes()
+	}
+
+	count, _ := strconv.ParseInt(hc.flags[0].value, 10, 64)
+	writePair := func(field, value []byte) {
+		// RESP3 replies each field with its value as a pair
+		if withValues && w.Protocol() == data.RESP3 {
+			w.ArrayLen(2)
+		}
+		w.BulkString(field)
+		if withValues {
+			w.BulkString(value)
+		}
+	}
+	replyLen := func(n int) {
+		if withValues && w.Protocol() != data.RESP3 {
+			n *= 2
+		}
+		w.ArrayLen(n)
+	}
+
+	// a negative count allows the same field more than once
+	if count < 0 {
+		replyLen(int(-count))
+		for range -count {
+			writePair(h.Random())
+		}
+		return w.Bytes()
+	}
+
+	if count >= int64(h.Len()) {
+		replyLen(h.Len())
+		h.Iterate(func(field, value []byte) bool {
+			writePair(field, value)
+			return true
+		})
+		return w.Bytes()
+	}
+
+	// when most fields are wanted picking at random would keep finding the
+	// same ones, a partial shuffle of every field is cheaper
+	replyLen(int(count))
+	if count*3 > int64(h.Len()) {
+		pairs := make([][2][]byte, 0, h.Len())
+		h.Iterate(func(field, value []byte) bool {
+			pairs = append(pairs, [2][]byte{field, value})
+			return true
+		})
+
+		for i := range int(count) {
+			j := i + rand.IntN(len(pairs)-i)
+			pairs[i], pairs[j] = pairs[j], pairs[i]
+			writePair(pairs[i][0], pairs[i][1])
+		}
+		return w.Bytes()
+	}
+
+	picked := make(map[string]struct{}, count)
+	for int64(len(picked)) < count {
+		f, v := h.Random()
+		if _, ok := picked[string(f)]; ok {
+			continue
+		}
+		picked[string(f)] = struct{}{}
+		writePair(f, v)
+	}
+
+	return w.Bytes()
+}
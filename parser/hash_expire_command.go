@@ -0,0 +1,618 @@
+package parser
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/JanitSri/codecrafters-build-your-own-redis/customerror"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/data"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/util"
+)
+
+// the replies of the hash field expiry commands for each field
+const (
+	HFE_NO_FIELD      = -2
+	HFE_NO_TTL        = -1
+	HFE_NOT_SET       = 0
+	HFE_SET           = 1
+	HFE_FIELD_DELETED = 2
+)
+
+func parseHExpireCmd(args [][]byte) Command {
+	return parseHExpireGenericCmd(args, EX)
+}
+
+func parseHPExpireCmd(args [][]byte) Command {
+	return parseHExpireGenericCmd(args, PX)
+}
+
+func parseHExpireAtCmd(args [][]byte) Command {
+	return parseHExpireGenericCmd(args, EXAT)
+}
+
+func parseHPExpireAtCmd(args [][]byte) Command {
+	return parseHExpireGenericCmd(args, PXAT)
+}
+
+// https://redis.io/docs/latest/commands/hexpire/
+func parseHExpireGenericCmd(args [][]byte, unit string) Command {
+	n, ok := util.ParseStrictInt(args[2])
+	if !ok {
+		return NewErrorCommand(customerror.NotIntegerError{})
+	}
+
+	if n < 0 {
+		return NewErrorCommand(customerror.NegativeExpireTimeError{})
+	}
+
+	flags := []*Flag{NewFlag(unit, string(args[2]))}
+	i := 3
+	switch c := strings.ToUpper(string(args[3])); c {
+	case NX, XX, GT, LT:
+		flags = append(flags, NewFlag(c, ""))
+		i++
+	}
+
+	fields, err := parseFields(args, i, 1)
+	if err != nil {
+		return NewErrorCommand(err)
+	}
+
+	return NewHExpireCommand(append([][]byte{args[1]}, fields...), flags)
+}
+
+func parseHTtlCmd(args [][]byte) Command {
+	return parseHTtlGenericCmd(args, EX)
+}
+
+func parseHPTtlCmd(args [][]byte) Command {
+	return parseHTtlGenericCmd(args, PX)
+}
+
+func parseHExpireTimeCmd(args [][]byte) Command {
+	return parseHTtlGenericCmd(args, EXAT)
+}
+
+func parseHPExpireTimeCmd(args [][]byte) Command {
+	return parseHTtlGenericCmd(args, PXAT)
+}
+
+func parseHTtlGenericCmd(args [][]byte, unit string) Command {
+	fields, err := parseFields(args, 2, 1)
+	if err != nil {
+		return NewErrorCommand(err)
+	}
+
+	return NewHTtlCommand(append([][]byte{args[1]}, fields...), []*Flag{NewFlag(unit, "")})
+}
+
+func parseHPersistCmd(args [][]byte) Command {
+	fields, err := parseFields(args, 2, 1)
+	if err != nil {
+		return NewErrorCommand(err)
+	}
+
+	return NewHPersistCommand(append([][]byte{args[1]}, fields...), nil)
+}
+
+// https://redis.io/docs/latest/commands/hgetex/
+func parseHGetExCmd(args [][]byte) Command {
+	flags := []*Flag{}
+	i := 2
+	for ; i < len(args); i++ {
+		f := strings.ToUpper(string(args[i]))
+		if f == PERSIST {
+			flags = append(flags, NewFlag(f, ""))
+			continue
+		}
+
+		if f != EX && f != PX && f != EXAT && f != PXAT {
+			break
+		}
+
+		if i+1 >= len(args) {
+			return NewErrorCommand(customerror.SyntaxError{})
+		}
+		if err := checkExpireTime(f, string(args[i+1]), "hgetex"); err != nil {
+			return NewErrorCommand(err)
+		}
+		flags = append(flags, NewFlag(f, string(args[i+1])))
+		i++
+	}
+
+	if len(flags) > 1 {
+		return NewErrorCommand(customerror.ExclusiveOptionsError{Options: "EX, PX, EXAT, PXAT or PERSIST"})
+	}
+
+	fields, err := parseFields(args, i, 1)
+	if err != nil {
+		return NewErrorCommand(err)
+	}
+
+	return NewHGetExCommand(append([][]byte{args[1]}, fields...), flags)
+}
+
+// https://redis.io/docs/latest/commands/hsetex/
+func parseHSetExCmd(args [][]byte) Command {
+	var cond, expire *Flag
+	i := 2
+	for ; i < len(args); i++ {
+		f := strings.ToUpper(string(args[i]))
+		if f == FNX || f == FXX {
+			if cond != nil {
+				return NewErrorCommand(customerror.ExclusiveOptionsError{Options: "FXX or FNX"})
+			}
+			cond = NewFlag(f, "")
+			continue
+		}
+
+		// the first argument that is not an option must be FIELDS
+		if f != KEEPTTL && f != EX && f != PX && f != EXAT && f != PXAT {
+			break
+		}
+
+		if expire != nil {
+			return NewErrorCommand(customerror.ExclusiveOptionsError{Options: "EX, PX, EXAT, PXAT or KEEPTTL"})
+		}
+
+		if f == KEEPTTL {
+			expire = NewFlag(f, "")
+			continue
+		}
+
+		if i+1 >= len(args) {
+			return NewErrorCommand(customerror.SyntaxError{})
+		}
+		if err := checkExpireTime(f, string(args[i+1]), "hsetex"); err != nil {
+			return NewErrorCommand(err)
+		}
+		expire = NewFlag(f, string(args[i+1]))
+		i++
+	}
+
+	fields, err := parseFields(args, i, 2)
+	if err != nil {
+		return NewErrorCommand(err)
+	}
+
+	flags := []*Flag{}
+	for _, f := range []*Flag{cond, expire} {
+		if f != nil {
+			flags = append(flags, f)
+		}
+	}
+
+	return NewHSetExCommand(append([][]byte{args[1]}, fields...), flags)
+}
+
+func parseHGetDelCmd(args [][]byte) Command {
+	fields, err := parseFields(args, 2, 1)
+	if err != nil {
+		return NewErrorCommand(err)
+	}
+
+	return NewHGetDelCommand(append([][]byte{args[1]}, fields...), nil)
+}
+
+// parseFields reads FIELDS numfields followed by the fields from args[i],
+// every field takes width arguments such as a field and its value
+func parseFields(args [][]byte, i, width int) ([][]byte, error) {
+	if i+1 >= len(args) || strings.ToUpper(string(args[i])) != FIELDS {
+		return nil, customerror.FieldsArgumentError{}
+	}
+
+	n, ok := util.ParseStrictInt(args[i+1])
+	if !ok || n <= 0 {
+		return nil, customerror.NumFieldsError{}
+	}
+
+	fields := args[i+2:]
+	if n > int64(len(fields)) || int(n)*width != len(fields) {
+		return nil, customerror.NumFieldsMismatchError{}
+	}
+
+	return fields, nil
+}
+
+// setFieldExpiry applies an expiry in unix milliseconds to a stored field,
+// an expiry that already passed deletes the field
+func setFieldExpiry(h *data.Hash, field []byte, when int64, now time.Time) int {
+	if when <= now.UnixMilli() {
+		h.Delete(field)
+		return HFE_FIELD_DELETED
+	}
+
+	h.SetFieldExpiry(field, time.UnixMilli(when))
+	return HFE_SET
+}
+
+// HExpireCommand implements HEXPIRE, HPEXPIRE, HEXPIREAT and HPEXPIREAT,
+// the first flag is the unit of the expiry as in EXPIRE
+type HExpireCommand struct {
+	BaseCommand
+}
+
+func NewHExpireCommand(args [][]byte, flags []*Flag) *HExpireCommand {
+	return &HExpireCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (hc *HExpireCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("setting hash field expiry...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key, fields := hc.args[0], hc.args[1:]
+	unit := hc.flags[0].name
+	now := time.Now()
+
+	when, ok := expireAtMillis(unit, hc.flags[0].value, now)
+	if !ok || when > data.HASH_FIELD_MAX_EXPIRE {
+		w.Error(customerror.InvalidExpireTimeError{Cmd: "h" + expireCommandName(unit)})
+		return w.Bytes()
+	}
+
+	h, exists, err := lookupHash(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	w.ArrayLen(len(fields))
+	if !exists {
+		for range fields {
+			w.Integer(HFE_NO_FIELD)
+		}
+		return w.Bytes()
+	}
+
+	var changed bool
+	for _, f := range fields {
+		if _, ok := h.Get(f); !ok {
+			w.Integer(HFE_NO_FIELD)
+			continue
+		}
+
+		if !expireConditionMet(hc.flags[1:], h.FieldExpiry(f), when) {
+			w.Integer(HFE_NOT_SET)
+			continue
+		}
+
+		w.Integer(int64(setFieldExpiry(h, f, when, now)))
+		changed = true
+	}
+
+	if changed {
+		hashChanged(rc, key, h)
+	}
+
+	return w.Bytes()
+}
+
+// HTtlCommand implements HTTL, HPTTL, HEXPIRETIME and HPEXPIRETIME, the
+// flag is the unit of the reply as in TTL
+type HTtlCommand struct {
+	BaseCommand
+}
+
+func NewHTtlCommand(args [][]byte, flags []*Flag) *HTtlCommand {
+	return &HTtlCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (hc *HTtlCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting hash field ttl...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	fields := hc.args[1:]
+	h, exists, err := lookupHash(rc, hc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	now := time.Now().UnixMilli()
+	w.ArrayLen(len(fields))
+	for _, f := range fields {
+		if !exists {
+			w.Integer(HFE_NO_FIELD)
+			continue
+		}
+
+		if _, ok := h.Get(f); !ok {
+			w.Integer(HFE_NO_FIELD)
+			continue
+		}
+
+		t := h.FieldExpiry(f)
+		if t.IsZero() {
+			w.Integer(HFE_NO_TTL)
+			continue
+		}
+
+		// seconds are rounded up so a field is never reported with a TTL of
+		// 0 before it expires
+		ms := t.UnixMilli()
+		switch hc.flags[0].name {
+		case EX:
+			w.Integer((ms - now + 999) / 1000)
+		case PX:
+			w.Integer(ms - now)
+		case EXAT:
+			w.Integer((ms + 999) / 1000)
+		default:
+			w.Integer(ms)
+		}
+	}
+
+	return w.Bytes()
+}
+
+type HPersistCommand struct {
+	BaseCommand
+}
+
+func NewHPersistCommand(args [][]byte, flags []*Flag) *HPersistCommand {
+	return &HPersistCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (hc *HPersistCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("persisting hash fields...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key, fields := hc.args[0], hc.args[1:]
+	h, exists, err := lookupHash(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	var changed bool
+	w.ArrayLen(len(fields))
+	for _, f := range fields {
+		if !exists {
+			w.Integer(HFE_NO_FIELD)
+			continue
+		}
+
+		if _, ok := h.Get(f); !ok {
+			w.Integer(HFE_NO_FIELD)
+			continue
+		}
+
+		if h.FieldExpiry(f).IsZero() {
+			w.Integer(HFE_NO_TTL)
+			continue
+		}
+
+		h.SetFieldExpiry(f, time.Time{})
+		w.Integer(HFE_SET)
+		changed = true
+	}
+
+	if changed {
+		hashChanged(rc, key, h)
+	}
+
+	return w.Bytes()
+}
+
+// HGetExCommand returns the values of fields and changes their expiry, the
+// flag, if any, is the expiry as in GETEX
+type HGetExCommand struct {
+	BaseCommand
+}
+
+func NewHGetExCommand(args [][]byte, flags []*Flag) *HGetExCommand {
+	return &HGetExCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (hc *HGetExCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting hash fields and setting expiry...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key, fields := hc.args[0], hc.args[1:]
+	now := time.Now()
+
+	var when int64
+	if len(hc.flags) > 0 && hc.flags[0].name != PERSIST {
+		var ok bool
+		when, ok = expireAtMillis(hc.flags[0].name, hc.flags[0].value, now)
+		if !ok || when > data.HASH_FIELD_MAX_EXPIRE {
+			w.Error(customerror.InvalidExpireTimeError{Cmd: "hgetex"})
+			return w.Bytes()
+		}
+	}
+
+	h, exists, err := lookupHash(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	var changed bool
+	w.ArrayLen(len(fields))
+	for _, f := range fields {
+		if !exists {
+			w.Null()
+			continue
+		}
+
+		v, ok := h.Get(f)
+		if !ok {
+			w.Null()
+			continue
+		}
+		w.BulkString(v)
+
+		switch {
+		case len(hc.flags) == 0:
+			continue
+		case hc.flags[0].name == PERSIST:
+			if h.FieldExpiry(f).IsZero() {
+				continue
+			}
+			h.SetFieldExpiry(f, time.Time{})
+		default:
+			setFieldExpiry(h, f, when, now)
+		}
+		changed = true
+	}
+
+	if changed {
+		hashChanged(rc, key, h)
+	}
+
+	return w.Bytes()
+}
+
+// HSetExCommand sets fields with an expiry, FNX and FXX set them only when
+// none or all of them exist. Without KEEPTTL or an expiry the fields lose
+// their TTL as with HSET
+type HSetExCommand struct {
+	BaseCommand
+}
+
+func NewHSetExCommand(args [][]byte, flags []*Flag) *HSetExCommand {
+	return &HSetExCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (hc *HSetExCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("setting hash fields with expiry...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key, pairs := hc.args[0], hc.args[1:]
+	now := time.Now()
+
+	var fnx, fxx, keepTTL, expire bool
+	var when int64
+	for _, f := range hc.flags {
+		switch f.name {
+		case FNX:
+			fnx = true
+		case FXX:
+			fxx = true
+		case KEEPTTL:
+			keepTTL = true
+		default:
+			var ok bool
+			when, ok = expireAtMillis(f.name, f.value, now)
+			if !ok || when > data.HASH_FIELD_MAX_EXPIRE {
+				w.Error(customerror.InvalidExpireTimeError{Cmd: "hsetex"})
+				return w.Bytes()
+			}
+			expire = true
+		}
+	}
+
+	h, exists, err := lookupHash(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if fnx || fxx {
+		for i := 0; i < len(pairs); i += 2 {
+			var found bool
+			if exists {
+				_, found = h.Get(pairs[i])
+			}
+
+			if (fnx && found) || (fxx && !found) {
+				w.Integer(0)
+				return w.Bytes()
+			}
+		}
+	}
+
+	if !exists {
+		h = data.NewHash()
+		rc.DataStore.Set(key, data.NewRedisValue(h, time.Time{}))
+	}
+
+	for i := 0; i < len(pairs); i += 2 {
+		setHashField(rc, h, pairs[i], pairs[i+1], keepTTL)
+		if expire {
+			setFieldExpiry(h, pairs[i], when, now)
+		}
+	}
+	hashChanged(rc, key, h)
+
+	w.Integer(1)
+	return w.Bytes()
+}
+
+// HGetDelCommand returns the values of fields and deletes them, the hash is
+// deleted once it has no fields left
+type HGetDelCommand struct {
+	BaseCommand
+}
+
+func NewHGetDelCommand(args [][]byte, flags []*Flag) *HGetDelCommand {
+	return &HGetDelCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (hc *HGetDelCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting and deleting hash fields...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key, fields := hc.args[0], hc.args[1:]
+	h, exists, err := lookupHash(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	var deleted bool
+	w.ArrayLen(len(fields))
+	for _, f := range fields {
+		if !exists {
+			w.Null()
+			continue
+		}
+
+		v, ok := h.Get(f)
+		if !ok {
+			w.Null()
+			continue
+		}
+
+		w.BulkString(v)
+		h.Delete(f)
+		deleted = true
+	}
+
+	if deleted {
+		hashChanged(rc, key, h)
+	}
+
+	return w.Bytes()
+}
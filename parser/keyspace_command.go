@@ -0,0 +1,624 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/JanitSri/codecrafters-build-your-own-redis/customerror"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/data"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/util"
+)
+
+// lookupKey returns the value of a key unless it is missing or expired,
+// an expired key is deleted on the way
+func lookupKey(rc *data.RedisContext, key []byte) (*data.RedisValue, bool) {
+	v, ok := rc.DataStore.Get(key)
+	if !ok {
+		return nil, false
+	}
+
+	if v.IsExpired() {
+		expireKey(rc, key)
+		return nil, false
+	}
+
+	// hash fields that expired are reclaimed on access too, a hash left
+	// without fields no longer exists
+	if h, ok := v.Value().(*data.Hash); ok && expireHashFields(rc, key, h) > 0 && h.Len() == 0 {
+		return nil, false
+	}
+
+	return v, true
+}
+
+// deleteKey removes a key and reports whether it held a live value, an
+// expired key is removed but not counted
+func deleteKey(rc *data.RedisContext, key []byte) bool {
+	_, exists := lookupKey(rc, key)
+	if !rc.DataStore.Delete(key) || !exists {
+		return false
+	}
+
+	signalModifiedKey(rc, key)
+	return true
+}
+
+func parseDelCmd(args [][]byte) Command {
+	return NewDelCommand(args[1:], nil)
+}
+
+func parseExistsCmd(args [][]byte) Command {
+	return NewExistsCommand(args[1:], nil)
+}
+
+func parseTypeCmd(args [][]byte) Command {
+	return NewTypeCommand([][]byte{args[1]}, nil)
+}
+
+func parseRenameCmd(args [][]byte) Command {
+	return NewRenameCommand([][]byte{args[1], args[2]}, nil)
+}
+
+func parseRenameNxCmd(args [][]byte) Command {
+	return NewRenameCommand([][]byte{args[1], args[2]}, []*Flag{NewFlag(NX, "")})
+}
+
+func parseCopyCmd(args [][]byte) Command {
+	flags := []*Flag{}
+	for i := 3; i < len(args); i++ {
+		f := strings.ToUpper(string(args[i]))
+		switch f {
+		case REPLACE:
+			flags = append(flags, NewFlag(f, ""))
+		case DB:
+			if i+1 >= len(args) {
+				return NewErrorCommand(customerror.SyntaxError{})
+			}
+
+			// only the default database exists
+			db, ok := util.ParseStrictInt(args[i+1])
+			if !ok {
+				return NewErrorCommand(customerror.NotIntegerError{})
+			}
+			if db != 0 {
+				return NewErrorCommand(customerror.DBIndexOutOfRangeError{})
+			}
+			i++
+		default:
+			return NewErrorCommand(customerror.SyntaxError{})
+		}
+	}
+
+	return NewCopyCommand([][]byte{args[1], args[2]}, flags)
+}
+
+func parseTouchCmd(args [][]byte) Command {
+	return NewTouchCommand(args[1:], nil)
+}
+
+func parseRandomKeyCmd(args [][]byte) Command {
+	return NewRandomKeyCommand()
+}
+
+// DelCommand implements DEL and UNLINK, values are freed by the garbage
+// collector so unlinking is no different from deleting
+type DelCommand struct {
+	BaseCommand
+}
+
+func NewDelCommand(args [][]byte, flags []*Flag) *DelCommand {
+	return &DelCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (dc *DelCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("deleting...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	var n int64
+	for _, k := range dc.args {
+		if deleteKey(rc, k) {
+			n++
+		}
+	}
+
+	w.Integer(n)
+	return w.Bytes()
+}
+
+type ExistsCommand struct {
+	BaseCommand
+}
+
+func NewExistsCommand(args [][]byte, flags []*Flag) *ExistsCommand {
+	return &ExistsCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+// https://redis.io/docs/latest/commands/exists/
+func (ec *ExistsCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("checking existence...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	// a key mentioned multiple times is counted multiple times
+	var n int64
+	for _, k := range ec.args {
+		if _, ok := lookupKey(rc, k); ok {
+			n++
+		}
+	}
+
+	w.Integer(n)
+	return w.Bytes()
+}
+
+type TypeCommand struct {
+	BaseCommand
+}
+
+func NewTypeCommand(args [][]byte, flags []*Flag) *TypeCommand {
+	return &TypeCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (tc *TypeCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting type...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	v, ok := lookupKey(rc, tc.args[0])
+	if !ok {
+		w.SimpleString(data.TYPE_NONE)
+		return w.Bytes()
+	}
+
+	w.SimpleString(v.Type())
+	return w.Bytes()
+}
+
+// RenameCommand implements RENAME, and RENAMENX when the NX flag is set
+type RenameCommand struct {
+	BaseCommand
+}
+
+func NewRenameCommand(args [][]byte, flags []*Flag) *RenameCommand {
+	return &RenameCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+// https://redis.io/docs/latest/commands/rename/
+func (rn *RenameCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("renaming...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	src, dst := rn.args[0], rn.args[1]
+	nx := len(rn.flags) > 0 && rn.flags[0].name == NX
+
+	if _, ok := lookupKey(rc, src); !ok {
+		w.Error(customerror.NoSuchKeyError{})
+		return w.Bytes()
+	}
+
+	if bytes.Equal(src, dst) {
+		if nx {
+			w.Integer(0)
+		} else {
+			w.OK()
+		}
+		return w.Bytes()
+	}
+
+	if _, ok := lookupKey(rc, dst); ok && nx {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	// the value keeps its expiry
+	rc.DataStore.Rename(src, dst)
+	signalModifiedKey(rc, src)
+	signalModifiedKey(rc, dst)
+
+	if nx {
+		w.Integer(1)
+	} else {
+		w.OK()
+	}
+	return w.Bytes()
+}
+
+type CopyCommand struct {
+	BaseCommand
+}
+
+func NewCopyCommand(args [][]byte, flags []*Flag) *CopyCommand {
+	return &CopyCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+// https://redis.io/docs/latest/commands/copy/
+func (cc *CopyCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("copying...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	src, dst := cc.args[0], cc.args[1]
+	replace := len(cc.flags) > 0 && cc.flags[0].name == REPLACE
+
+	if bytes.Equal(src, dst) {
+		w.Error(customerror.SameObjectError{})
+		return w.Bytes()
+	}
+
+	v, ok := lookupKey(rc, src)
+	if !ok {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	if _, ok := lookupKey(rc, dst); ok && !replace {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	// the copy carries the expiry over
+	rc.DataStore.Set(dst, v.Copy())
+	signalModifiedKey(rc, dst)
+
+	w.Integer(1)
+	return w.Bytes()
+}
+
+type TouchCommand struct {
+	BaseCommand
+}
+
+func NewTouchCommand(args [][]byte, flags []*Flag) *TouchCommand {
+	return &TouchCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+// TOUCH only counts the keys that exist, no access time is kept since
+// there is no eviction
+func (tc *TouchCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("touching...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	var n int64
+	for _, k := range tc.args {
+		if _, ok := lookupKey(rc, k); ok {
+			n++
+		}
+	}
+
+	w.Integer(n)
+	return w.Bytes()
+}
+
+type RandomKeyCommand struct {
+}
+
+func NewRandomKeyCommand() *RandomKeyCommand {
+	return &RandomKeyCommand{}
+}
+
+func (rk *RandomKeyCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting random key...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	// expired keys met along the way are expired like on any other access,
+	// every miss deletes a key so this ends once a live key is found or the
+	// keyspace is empty
+	for {
+		k, ok := rc.DataStore.RandomKey()
+		if !ok {
+			w.Null()
+			return w.Bytes()
+		}
+
+		if _, ok := lookupKey(rc, k); ok {
+			w.BulkString(k)
+			return w.Bytes()
+		}
+	}
+}
+
+func parseExpireCmd(args [][]byte) Command {
+	return parseExpireGenericCmd(args, EX)
+}
+
+func parsePExpireCmd(args [][]byte) Command {
+	return parseExpireGenericCmd(args, PX)
+}
+
+func parseExpireAtCmd(args [][]byte) Command {
+	return parseExpireGenericCmd(args, EXAT)
+}
+
+func parsePExpireAtCmd(args [][]byte) Command {
+	return parseExpireGenericCmd(args, PXAT)
+}
+
+// https://redis.io/docs/latest/commands/expire/
+func parseExpireGenericCmd(args [][]byte, unit string) Command {
+	if _, ok := util.ParseStrictInt(args[2]); !ok {
+		return NewErrorCommand(customerror.NotIntegerError{})
+	}
+
+	var nx, xx, gt, lt bool
+	for _, a := range args[3:] {
+		switch strings.ToUpper(string(a)) {
+		case NX:
+			nx = true
+		case XX:
+			xx = true
+		case GT:
+			gt = true
+		case LT:
+			lt = true
+		default:
+			return NewErrorCommand(customerror.InvalidExpireOptionError{Reason: fmt.Sprintf("Unsupported option %s", a)})
+		}
+	}
+
+	if nx && (xx || gt || lt) {
+		return NewErrorCommand(customerror.InvalidExpireOptionError{Reason: "NX and XX, GT or LT options at the same time are not compatible"})
+	}
+
+	if gt && lt {
+		return NewErrorCommand(customerror.InvalidExpireOptionError{Reason: "GT and LT options at the same time are not compatible"})
+	}
+
+	flags := []*Flag{NewFlag(unit, string(args[2]))}
+	for _, c := range []struct {
+		set  bool
+		name string
+	}{{nx, NX}, {xx, XX}, {gt, GT}, {lt, LT}} {
+		if c.set {
+			flags = append(flags, NewFlag(c.name, ""))
+		}
+	}
+
+	return NewExpireCommand([][]byte{args[1]}, flags)
+}
+
+func parseTtlCmd(args [][]byte) Command {
+	return NewTtlCommand([][]byte{args[1]}, []*Flag{NewFlag(EX, "")})
+}
+
+func parsePTtlCmd(args [][]byte) Command {
+	return NewTtlCommand([][]byte{args[1]}, []*Flag{NewFlag(PX, "")})
+}
+
+func parseExpireTimeCmd(args [][]byte) Command {
+	return NewTtlCommand([][]byte{args[1]}, []*Flag{NewFlag(EXAT, "")})
+}
+
+func parsePExpireTimeCmd(args [][]byte) Command {
+	return NewTtlCommand([][]byte{args[1]}, []*Flag{NewFlag(PXAT, "")})
+}
+
+func parsePersistCmd(args [][]byte) Command {
+	return NewPersistCommand([][]byte{args[1]}, nil)
+}
+
+// ExpireCommand implements EXPIRE, PEXPIRE, EXPIREAT and PEXPIREAT, the
+// first flag is the unit of the expiry as in SET
+type ExpireCommand struct {
+	BaseCommand
+}
+
+func NewExpireCommand(args [][]byte, flags []*Flag) *ExpireCommand {
+	return &ExpireCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (ec *ExpireCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("setting expiry...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := ec.args[0]
+	unit := ec.flags[0].name
+	now := time.Now()
+
+	when, ok := expireAtMillis(unit, ec.flags[0].value, now)
+	if !ok {
+		w.Error(customerror.InvalidExpireTimeError{Cmd: expireCommandName(unit)})
+		return w.Bytes()
+	}
+
+	v, exists := lookupKey(rc, key)
+	if !exists {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	if !expireConditionMet(ec.flags[1:], v.Expiry(), when) {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	// an expiry that already passed deletes the key
+	if when <= now.UnixMilli() {
+		rc.DataStore.Delete(key)
+	} else {
+		rc.DataStore.SetExpiry(key, time.UnixMilli(when))
+	}
+	signalModifiedKey(rc, key)
+
+	w.Integer(1)
+	return w.Bytes()
+}
+
+// expireConditionMet checks the NX, XX, GT and LT flags against the
+// current expiry, the zero time when there is none. Something without a
+// TTL never expires, it is greater than any expiry
+func expireConditionMet(flags []*Flag, current time.Time, when int64) bool {
+	for _, f := range flags {
+		var skip bool
+		switch f.name {
+		case NX:
+			skip = !current.IsZero()
+		case XX:
+			skip = current.IsZero()
+		case GT:
+			skip = current.IsZero() || when <= current.UnixMilli()
+		case LT:
+			skip = !current.IsZero() && when >= current.UnixMilli()
+		}
+
+		if skip {
+			return false
+		}
+	}
+
+	return true
+}
+
+// expireAtMillis converts the argument of an expire command into a unix
+// time in milliseconds, ok is false when it does not fit
+func expireAtMillis(unit, value string, now time.Time) (int64, bool) {
+	n, _ := util.ParseStrictInt([]byte(value))
+
+	if unit == EX || unit == EXAT {
+		if n > math.MaxInt64/1000 || n < math.MinInt64/1000 {
+			return 0, false
+		}
+		n *= 1000
+	}
+
+	if unit == EX || unit == PX {
+		base := now.UnixMilli()
+		if (n > 0 && base > math.MaxInt64-n) || (n < 0 && base < math.MinInt64-n) {
+			return 0, false
+		}
+		n += base
+	}
+
+	return n, true
+}
+
+func expireCommandName(unit string) string {
+	switch unit {
+	case EX:
+		return "expire"
+	case PX:
+		return "pexpire"
+	case EXAT:
+		return "expireat"
+	default:
+		return "pexpireat"
+	}
+}
+
+// TtlCommand implements TTL, PTTL, EXPIRETIME and PEXPIRETIME, the flag is
+// the unit of the reply as in SET
+type TtlCommand struct {
+	BaseCommand
+}
+
+func NewTtlCommand(args [][]byte, flags []*Flag) *TtlCommand {
+	return &TtlCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+// https://redis.io/docs/latest/commands/ttl/
+func (tc *TtlCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting ttl...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	v, exists := lookupKey(rc, tc.args[0])
+	if !exists {
+		w.Integer(-2)
+		return w.Bytes()
+	}
+
+	if v.Expiry().IsZero() {
+		w.Integer(-1)
+		return w.Bytes()
+	}
+
+	ms := v.Expiry().UnixMilli()
+	unit := tc.flags[0].name
+	if unit == EX || unit == PX {
+		ms = max(ms-time.Now().UnixMilli(), 0)
+	}
+
+	if unit == EX || unit == EXAT {
+		w.Integer((ms + 500) / 1000)
+	} else {
+		w.Integer(ms)
+	}
+	return w.Bytes()
+}
+
+type PersistCommand struct {
+	BaseCommand
+}
+
+func NewPersistCommand(args [][]byte, flags []*Flag) *PersistCommand {
+	return &PersistCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (pc *PersistCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("persisting...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := pc.args[0]
+
+	v, exists := lookupKey(rc, key)
+	if !exists || v.Expiry().IsZero() {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	rc.DataStore.SetExpiry(key, time.Time{})
+	signalModifiedKey(rc, key)
+
+	w.Integer(1)
+	return w.Bytes()
+}
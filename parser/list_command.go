@@ -0,0 +1,911 @@
+package parser
+
+import (
+	"bytes"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JanitSri/codecrafters-build-your-own-redis/customerror"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/data"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/util"
+)
+
+func parseLPushCmd(args [][]byte) Command {
+	return NewPushCommand(args[1:], []*Flag{NewFlag(LEFT, "")})
+}
+
+func parseRPushCmd(args [][]byte) Command {
+	return NewPushCommand(args[1:], []*Flag{NewFlag(RIGHT, "")})
+}
+
+func parseLPushXCmd(args [][]byte) Command {
+	return NewPushCommand(args[1:], []*Flag{NewFlag(LEFT, ""), NewFlag(XX, "")})
+}
+
+func parseRPushXCmd(args [][]byte) Command {
+	return NewPushCommand(args[1:], []*Flag{NewFlag(RIGHT, ""), NewFlag(XX, "")})
+}
+
+func parseLPopCmd(args [][]byte) Command {
+	return parsePopCmd(args, LEFT)
+}
+
+func parseRPopCmd(args [][]byte) Command {
+	return parsePopCmd(args, RIGHT)
+}
+
+// https://redis.io/docs/latest/commands/lpop/
+func parsePopCmd(args [][]byte, where string) Command {
+	if len(args) > 3 {
+		return NewErrorCommand(customerror.SyntaxError{})
+	}
+
+	flags := []*Flag{NewFlag(where, "")}
+	if len(args) == 3 {
+		n, ok := util.ParseStrictInt(args[2])
+		if !ok || n < 0 {
+			return NewErrorCommand(customerror.PositiveValueError{})
+		}
+		flags = append(flags, NewFlag(COUNT, string(args[2])))
+	}
+
+	return NewPopCommand([][]byte{args[1]}, flags)
+}
+
+func parseLRangeCmd(args [][]byte) Command {
+	if !areIntegers(args[2:4]) {
+		return NewErrorCommand(customerror.NotIntegerError{})
+	}
+
+	return NewLRangeCommand(args[1:4], nil)
+}
+
+func parseLLenCmd(args [][]byte) Command {
+	return NewLLenCommand([][]byte{args[1]}, nil)
+}
+
+func parseLIndexCmd(args [][]byte) Command {
+	if !areIntegers(args[2:3]) {
+		return NewErrorCommand(customerror.NotIntegerError{})
+	}
+
+	return NewLIndexCommand(args[1:3], nil)
+}
+
+func parseLSetCmd(args [][]byte) Command {
+	if !areIntegers(args[2:3]) {
+		return NewErrorCommand(customerror.NotIntegerError{})
+	}
+
+	return NewLSetCommand(args[1:4], nil)
+}
+
+func parseLInsertCmd(args [][]byte) Command {
+	where := strings.ToUpper(string(args[2]))
+	if where != BEFORE && where != AFTER {
+		return NewErrorCommand(customerror.SyntaxError{})
+	}
+
+	return NewLInsertCommand([][]byte{args[1], args[3], args[4]}, []*Flag{NewFlag(where, "")})
+}
+
+func parseLRemCmd(args [][]byte) Command {
+	if !areIntegers(args[2:3]) {
+		return NewErrorCommand(customerror.NotIntegerError{})
+	}
+
+	return NewLRemCommand(args[1:4], nil)
+}
+
+func parseLTrimCmd(args [][]byte) Command {
+	if !areIntegers(args[2:4]) {
+		return NewErrorCommand(customerror.NotIntegerError{})
+	}
+
+	return NewLTrimCommand(args[1:4], nil)
+}
+
+// https://redis.io/docs/latest/commands/lpos/
+func parseLPosCmd(args [][]byte) Command {
+	flags := []*Flag{}
+	for i := 3; i < len(args); i += 2 {
+		f := strings.ToUpper(string(args[i]))
+		if i+1 >= len(args) {
+			return NewErrorCommand(customerror.SyntaxError{})
+		}
+
+		n, ok := util.ParseStrictInt(args[i+1])
+		switch f {
+		case RANK:
+			if !ok || n == math.MinInt64 {
+				return NewErrorCommand(customerror.NotIntegerError{})
+			}
+			if n == 0 {
+				return NewErrorCommand(customerror.LPosRankError{})
+			}
+		case COUNT, MAXLEN:
+			if !ok || n < 0 {
+				return NewErrorCommand(customerror.NegativeOptionError{Option: f})
+			}
+		default:
+			return NewErrorCommand(customerror.SyntaxError{})
+		}
+
+		flags = append(flags, NewFlag(f, string(args[i+1])))
+	}
+
+	return NewLPosCommand(args[1:3], flags)
+}
+
+func parseLMoveCmd(args [][]byte) Command {
+	from := strings.ToUpper(string(args[3]))
+	to := strings.ToUpper(string(args[4]))
+	if !isListEnd(from) || !isListEnd(to) {
+		return NewErrorCommand(customerror.SyntaxError{})
+	}
+
+	return NewLMoveCommand(args[1:3], []*Flag{NewFlag(from, ""), NewFlag(to, "")})
+}
+
+func parseRPopLPushCmd(args [][]byte) Command {
+	return NewLMoveCommand(args[1:3], []*Flag{NewFlag(RIGHT, ""), NewFlag(LEFT, "")})
+}
+
+// https://redis.io/docs/latest/commands/lmpop/
+func parseLMPopCmd(args [][]byte) Command {
+	keys, flags, err := parseMPopArgs(args[1:])
+	if err != nil {
+		return NewErrorCommand(err)
+	}
+
+	return NewLMPopCommand(keys, flags)
+}
+
+// parseMPopArgs parses numkeys key [key ...] LEFT|RIGHT [COUNT count], it
+// returns the keys and the flags for the end and the count
+func parseMPopArgs(args [][]byte) ([][]byte, []*Flag, error) {
+	numKeys, ok := util.ParseStrictInt(args[0])
+	if !ok || numKeys <= 0 {
+		return nil, nil, customerror.NumKeysError{}
+	}
+
+	if numKeys > int64(len(args)-1) {
+		return nil, nil, customerror.NumKeysTooManyError{}
+	}
+
+	keys := args[1 : 1+numKeys]
+	rest := args[1+numKeys:]
+	if len(rest) == 0 {
+		return nil, nil, customerror.SyntaxError{}
+	}
+
+	where := strings.ToUpper(string(rest[0]))
+	if !isListEnd(where) {
+		return nil, nil, customerror.SyntaxError{}
+	}
+
+	flags := []*Flag{NewFlag(where, ""), NewFlag(COUNT, "1")}
+	rest = rest[1:]
+	if len(rest) > 0 {
+		if len(rest) != 2 || strings.ToUpper(string(rest[0])) != COUNT {
+			return nil, nil, customerror.SyntaxError{}
+		}
+
+		n, ok := util.ParseStrictInt(rest[1])
+		if !ok || n <= 0 {
+			return nil, nil, customerror.PositiveCountError{}
+		}
+		flags[1].value = string(rest[1])
+	}
+
+	return keys, flags, nil
+}
+
+// mpopKeys finds the keys of LMPOP and the other commands that take
+// numkeys followed by the keys, first is the index of numkeys
+func mpopKeys(first int) func(args [][]byte) []int {
+	return func(args [][]byte) []int {
+		if first >= len(args) {
+			return nil
+		}
+
+		n, ok := util.ParseStrictInt(args[first])
+		if !ok || n <= 0 || n > int64(len(args)-first-1) {
+			return nil
+		}
+
+		var pos []int
+		for i := range int(n) {
+			pos = append(pos, first+1+i)
+		}
+		return pos
+	}
+}
+
+func areIntegers(args [][]byte) bool {
+	for _, a := range args {
+		if _, ok := util.ParseStrictInt(a); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isListEnd(s string) bool {
+	return s == LEFT || s == RIGHT
+}
+
+// lookupList returns the list stored at key, exists is false when the key
+// is missing and the error is WRONGTYPE when it holds another type
+func lookupList(rc *data.RedisContext, key []byte) (*data.Quicklist, bool, error) {
+	v, ok := lookupKey(rc, key)
+	if !ok {
+		return nil, false, nil
+	}
+
+	ql, isList := v.Value().(*data.Quicklist)
+	if !isList {
+		return nil, true, customerror.WrongTypeError{}
+	}
+
+	return ql, true, nil
+}
+
+// popList removes an element from one end of the list, the key is deleted
+// once the list is empty
+func popList(rc *data.RedisContext, key []byte, ql *data.Quicklist, where string) []byte {
+	var v []byte
+	if where == LEFT {
+		v, _ = ql.PopHead()
+	} else {
+		v, _ = ql.PopTail()
+	}
+
+	if ql.Len() == 0 {
+		rc.DataStore.Delete(key)
+	}
+
+	return v
+}
+
+func pushList(ql *data.Quicklist, where string, v []byte) {
+	if where == LEFT {
+		ql.PushHead(v)
+	} else {
+		ql.PushTail(v)
+	}
+}
+
+// listRange converts start and end, that may be negative, into indexes of
+// a list of n elements the way LRANGE does, ok is false for an empty range
+func listRange(start, end int64, n int) (int, int, bool) {
+	if start < 0 {
+		start += int64(n)
+	}
+	if end < 0 {
+		end += int64(n)
+	}
+	start = max(start, 0)
+
+	if start > end || start >= int64(n) {
+		return 0, 0, false
+	}
+
+	return int(start), int(min(end, int64(n-1))), true
+}
+
+// PushCommand implements LPUSH and RPUSH, and LPUSHX and RPUSHX when the
+// XX flag is set
+type PushCommand struct {
+	BaseCommand
+}
+
+func NewPushCommand(args [][]byte, flags []*Flag) *PushCommand {
+	return &PushCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (pc *PushCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("pushing...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := pc.args[0]
+	where := pc.flags[0].name
+	xx := len(pc.flags) > 1
+
+	ql, exists, err := lookupList(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		if xx {
+			w.Integer(0)
+			return w.Bytes()
+		}
+
+		ql = data.NewQuicklist()
+		rc.DataStore.Set(key, data.NewRedisValue(ql, time.Time{}))
+	}
+
+	for _, e := range pc.args[1:] {
+		pushList(ql, where, e)
+	}
+	signalModifiedKey(rc, key)
+
+	w.Integer(int64(ql.Len()))
+	return w.Bytes()
+}
+
+// PopCommand implements LPOP and RPOP, with the COUNT flag the reply is an
+// array
+type PopCommand struct {
+	BaseCommand
+}
+
+func NewPopCommand(args [][]byte, flags []*Flag) *PopCommand {
+	return &PopCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (pc *PopCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("popping...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := pc.args[0]
+	where := pc.flags[0].name
+	hasCount := len(pc.flags) > 1
+
+	ql, exists, err := lookupList(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		if hasCount {
+			w.NullArray()
+		} else {
+			w.Null()
+		}
+		return w.Bytes()
+	}
+
+	if !hasCount {
+		w.BulkString(popList(rc, key, ql, where))
+		signalModifiedKey(rc, key)
+		return w.Bytes()
+	}
+
+	count, _ := strconv.Atoi(pc.flags[1].value)
+	count = min(count, ql.Len())
+	w.ArrayLen(count)
+	for range count {
+		w.BulkString(popList(rc, key, ql, where))
+	}
+	if count > 0 {
+		signalModifiedKey(rc, key)
+	}
+
+	return w.Bytes()
+}
+
+type LRangeCommand struct {
+	BaseCommand
+}
+
+func NewLRangeCommand(args [][]byte, flags []*Flag) *LRangeCommand {
+	return &LRangeCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (lc *LRangeCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting list range...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	ql, exists, err := lookupList(rc, lc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.ArrayLen(0)
+		return w.Bytes()
+	}
+
+	start, _ := util.ParseStrictInt(lc.args[1])
+	end, _ := util.ParseStrictInt(lc.args[2])
+	from, to, ok := listRange(start, end, ql.Len())
+	if !ok {
+		w.ArrayLen(0)
+		return w.Bytes()
+	}
+
+	w.ArrayLen(to - from + 1)
+	ql.Range(from, to, func(v []byte) {
+		w.BulkString(v)
+	})
+	return w.Bytes()
+}
+
+type LLenCommand struct {
+	BaseCommand
+}
+
+func NewLLenCommand(args [][]byte, flags []*Flag) *LLenCommand {
+	return &LLenCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (lc *LLenCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting list length...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	ql, exists, err := lookupList(rc, lc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	w.Integer(int64(ql.Len()))
+	return w.Bytes()
+}
+
+type LIndexCommand struct {
+	BaseCommand
+}
+
+func NewLIndexCommand(args [][]byte, flags []*Flag) *LIndexCommand {
+	return &LIndexCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (lc *LIndexCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting list element...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	ql, exists, err := lookupList(rc, lc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	i, _ := util.ParseStrictInt(lc.args[1])
+	if !exists || i < math.MinInt || i > math.MaxInt {
+		w.Null()
+		return w.Bytes()
+	}
+
+	v, ok := ql.Index(int(i))
+	if !ok {
+		w.Null()
+		return w.Bytes()
+	}
+
+	w.BulkString(v)
+	return w.Bytes()
+}
+
+type LSetCommand struct {
+	BaseCommand
+}
+
+func NewLSetCommand(args [][]byte, flags []*Flag) *LSetCommand {
+	return &LSetCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (lc *LSetCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("setting list element...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := lc.args[0]
+
+	ql, exists, err := lookupList(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.Error(customerror.NoSuchKeyError{})
+		return w.Bytes()
+	}
+
+	i, _ := util.ParseStrictInt(lc.args[1])
+	if i < math.MinInt || i > math.MaxInt || !ql.Set(int(i), lc.args[2]) {
+		w.Error(customerror.IndexOutOfRangeError{})
+		return w.Bytes()
+	}
+	signalModifiedKey(rc, key)
+
+	w.OK()
+	return w.Bytes()
+}
+
+type LInsertCommand struct {
+	BaseCommand
+}
+
+func NewLInsertCommand(args [][]byte, flags []*Flag) *LInsertCommand {
+	return &LInsertCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (lc *LInsertCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("inserting into list...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key, pivot, elem := lc.args[0], lc.args[1], lc.args[2]
+
+	ql, exists, err := lookupList(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	if !ql.Insert(pivot, elem, lc.flags[0].name == AFTER) {
+		w.Integer(-1)
+		return w.Bytes()
+	}
+	signalModifiedKey(rc, key)
+
+	w.Integer(int64(ql.Len()))
+	return w.Bytes()
+}
+
+type LRemCommand struct {
+	BaseCommand
+}
+
+func NewLRemCommand(args [][]byte, flags []*Flag) *LRemCommand {
+	return &LRemCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (lc *LRemCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("removing from list...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := lc.args[0]
+
+	ql, exists, err := lookupList(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	// a count beyond the length of the list is the same as removing all
+	count, _ := util.ParseStrictInt(lc.args[1])
+	count = max(min(count, int64(ql.Len())), -int64(ql.Len()))
+
+	n := ql.Remove(int(count), lc.args[2])
+	if n > 0 {
+		if ql.Len() == 0 {
+			rc.DataStore.Delete(key)
+		}
+		signalModifiedKey(rc, key)
+	}
+
+	w.Integer(int64(n))
+	return w.Bytes()
+}
+
+type LTrimCommand struct {
+	BaseCommand
+}
+
+func NewLTrimCommand(args [][]byte, flags []*Flag) *LTrimCommand {
+	return &LTrimCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (lc *LTrimCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("trimming list...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := lc.args[0]
+
+	ql, exists, err := lookupList(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.OK()
+		return w.Bytes()
+	}
+
+	start, _ := util.ParseStrictInt(lc.args[1])
+	end, _ := util.ParseStrictInt(lc.args[2])
+	from, to, ok := listRange(start, end, ql.Len())
+	if !ok {
+		rc.DataStore.Delete(key)
+		signalModifiedKey(rc, key)
+		w.OK()
+		return w.Bytes()
+	}
+
+	if from > 0 || to < ql.Len()-1 {
+		ql.Trim(from, to)
+		signalModifiedKey(rc, key)
+	}
+
+	w.OK()
+	return w.Bytes()
+}
+
+type LPosCommand struct {
+	BaseCommand
+}
+
+func NewLPosCommand(args [][]byte, flags []*Flag) *LPosCommand {
+	return &LPosCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+// https://redis.io/docs/latest/commands/lpos/
+func (lc *LPosCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("finding list position...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	elem := lc.args[1]
+
+	var rank, count, maxLen int64 = 1, 1, 0
+	hasCount := false
+	for _, f := range lc.flags {
+		n, _ := strconv.ParseInt(f.value, 10, 64)
+		switch f.name {
+		case RANK:
+			rank = n
+		case COUNT:
+			count = n
+			hasCount = true
+		case MAXLEN:
+			maxLen = n
+		}
+	}
+
+	ql, exists, err := lookupList(rc, lc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	// a negative rank searches from the tail, skipping the first matches
+	// until the rank is reached
+	var found []int64
+	if exists {
+		reverse := rank < 0
+		skip := max(rank, -rank) - 1
+		var checked int64
+		ql.Iterate(reverse, func(i int, v []byte) bool {
+			if maxLen != 0 && checked >= maxLen {
+				return false
+			}
+			checked++
+
+			if !bytes.Equal(v, elem) {
+				return true
+			}
+			if skip > 0 {
+				skip--
+				return true
+			}
+
+			found = append(found, int64(i))
+			return count == 0 || int64(len(found)) < count
+		})
+	}
+
+	if !hasCount {
+		if len(found) == 0 {
+			w.Null()
+		} else {
+			w.Integer(found[0])
+		}
+		return w.Bytes()
+	}
+
+	w.ArrayLen(len(found))
+	for _, i := range found {
+		w.Integer(i)
+	}
+	return w.Bytes()
+}
+
+// LMoveCommand implements LMOVE and RPOPLPUSH, the flags are the end of the
+// source to pop from and the end of the destination to push to
+type LMoveCommand struct {
+	BaseCommand
+}
+
+func NewLMoveCommand(args [][]byte, flags []*Flag) *LMoveCommand {
+	return &LMoveCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (lc *LMoveCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("moving list element...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	v, err := moveListElement(rc, lc.args[0], lc.args[1], lc.flags[0].name, lc.flags[1].name)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if v == nil {
+		w.Null()
+		return w.Bytes()
+	}
+
+	w.BulkString(v)
+	return w.Bytes()
+}
+
+// moveListElement pops an element from src and pushes it to dst, the
+// element is nil when src is missing. Both keys are checked before
+// anything is modified
+func moveListElement(rc *data.RedisContext, src, dst []byte, from, to string) ([]byte, error) {
+	sl, exists, err := lookupList(rc, src)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	dl, exists, err := lookupList(rc, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	var v []byte
+	if from == LEFT {
+		v, _ = sl.PopHead()
+	} else {
+		v, _ = sl.PopTail()
+	}
+
+	// when src and dst are the same key the element goes back to the same
+	// list, so the key must not be deleted before it is pushed
+	if !exists {
+		dl = data.NewQuicklist()
+		rc.DataStore.Set(dst, data.NewRedisValue(dl, time.Time{}))
+	}
+	pushList(dl, to, v)
+
+	if sl.Len() == 0 {
+		rc.DataStore.Delete(src)
+	}
+	signalModifiedKey(rc, src)
+	signalModifiedKey(rc, dst)
+
+	return v, nil
+}
+
+type LMPopCommand struct {
+	BaseCommand
+}
+
+func NewLMPopCommand(args [][]byte, flags []*Flag) *LMPopCommand {
+	return &LMPopCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (lc *LMPopCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("popping from lists...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	served, err := mpopLists(rc, w, lc.args, lc.flags)
+	if err != nil {
+		w.Error(err)
+	} else if !served {
+		w.NullArray()
+	}
+
+	return w.Bytes()
+}
+
+// mpopLists pops from the first non empty list of keys and writes the key
+// with the elements popped, it reports false and writes nothing when every
+// list is empty. The error is WRONGTYPE when a key met before that list is
+// not a list
+func mpopLists(rc *data.RedisContext, w *ReplyWriter, keys [][]byte, flags []*Flag) (bool, error) {
+	where := flags[0].name
+	count, _ := strconv.Atoi(flags[1].value)
+
+	for _, key := range keys {
+		ql, exists, err := lookupList(rc, key)
+		if err != nil {
+			return false, err
+		}
+		if !exists {
+			continue
+		}
+
+		n := min(count, ql.Len())
+		w.ArrayLen(2)
+		w.BulkString(key)
+		w.ArrayLen(n)
+		for range n {
+			w.BulkString(popList(rc, key, ql, where))
+		}
+		signalModifiedKey(rc, key)
+		return true, nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"log"
+
+	"github.com/JanitSri/codecrafters-build-your-own-redis/data"
+)
+
+func parseSubscribeCmd(args [][]byte) Command {
+	return NewSubscribeCommand(args[1:], nil)
+}
+
+func parseUnsubscribeCmd(args [][]byte) Command {
+	return NewUnsubscribeCommand(args[1:], nil)
+}
+
+func parsePublishCmd(args [][]byte) Command {
+	return NewPublishCommand(args[1:], nil)
+}
+
+type SubscribeCommand struct {
+	BaseCommand
+}
+
+func NewSubscribeCommand(args [][]byte, flags []*Flag) *SubscribeCommand {
+	return &SubscribeCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (sc *SubscribeCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("subscribing...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	for _, ch := range sc.args {
+		n := rc.Client.Subscribe(ch)
+		w.PushLen(3)
+		w.BulkStringString("subscribe")
+		w.BulkString(ch)
+		w.Integer(int64(n))
+	}
+
+	return w.Bytes()
+}
+
+type UnsubscribeCommand struct {
+	BaseCommand
+}
+
+func NewUnsubscribeCommand(args [][]byte, flags []*Flag) *UnsubscribeCommand {
+	return &UnsubscribeCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (uc *UnsubscribeCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("unsubscribing...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	chs := uc.args
+	if len(chs) == 0 {
+		chs = rc.Client.Subscriptions()
+	}
+
+	if len(chs) == 0 {
+		w.PushLen(3)
+		w.BulkStringString("unsubscribe")
+		w.Null()
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	for _, ch := range chs {
+		n := rc.Client.Unsubscribe(ch)
+		w.PushLen(3)
+		w.BulkStringString("unsubscribe")
+		w.BulkString(ch)
+		w.Integer(int64(n))
+	}
+
+	return w.Bytes()
+}
+
+type PublishCommand struct {
+	BaseCommand
+}
+
+func NewPublishCommand(args [][]byte, flags []*Flag) *PublishCommand {
+	return &PublishCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (pc *PublishCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("publishing...")
+
+	ch, msg := pc.args[0], pc.args[1]
+
+	n := 0
+	for _, c := range rc.Clients.All() {
+		if !c.IsSubscribed(ch) {
+			continue
+		}
+
+		m := NewReplyWriter(c.Protocol())
+		m.PushLen(3)
+		m.BulkStringString("message")
+		m.BulkString(ch)
+		m.BulkString(msg)
+		c.Push(m.Bytes())
+		n++
+	}
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	w.Integer(int64(n))
+	return w.Bytes()
+}
@@ -1,9 +1,11 @@
 package parser
 
 import (
+	"bytes"
 	"encoding/binary"
-	"fmt"
+	"hash/crc64"
 	"log"
+	"math"
 	"strconv"
 	"time"
 
@@ -12,123 +14,1000 @@ import (
 )
 
 // https://rdb.fnordig.de/file_format.html
-func ParseRBDFile(b []byte) map[string]*data.RedisValue {
-	pairs := make(map[string]*data.RedisValue)
+// https://github.com/redis/redis/blob/unstable/src/rdb.h
+const (
+	RDB_MAGIC       = "REDIS"
+	RDB_VERSION     = 12
+	RDB_HEADER_SIZE = 9
+
+	RDB_OPCODE_SLOT_INFO     = 0xF4
+	RDB_OPCODE_FREQ          = 0xF8
+	RDB_OPCODE_IDLE          = 0xF9
+	RDB_OPCODE_AUX           = 0xFA
+	RDB_OPCODE_RESIZEDB      = 0xFB
+	RDB_OPCODE_EXPIRETIME_MS = 0xFC
+	RDB_OPCODE_EXPIRETIME    = 0xFD
+	RDB_OPCODE_SELECTDB      = 0xFE
+	RDB_OPCODE_EOF           = 0xFF
+
+	RDB_TYPE_STRING           = 0
+	RDB_TYPE_LIST             = 1
+	RDB_TYPE_SET              = 2
+	RDB_TYPE_ZSET             = 3
+	RDB_TYPE_HASH             = 4
+	RDB_TYPE_ZSET_2           = 5
+	RDB_TYPE_SET_INTSET       = 11
+	RDB_TYPE_HASH_LISTPACK    = 16
+	RDB_TYPE_ZSET_LISTPACK    = 17
+	RDB_TYPE_LIST_QUICKLIST_2 = 18
+	RDB_TYPE_SET_LISTPACK     = 20
+
+	// hashes with field expiries, the PRE_GA types were written by
+	// Redis 7.4 without the minimum expiry of the hash
+	RDB_TYPE_HASH_METADATA_PRE_GA    = 22
+	RDB_TYPE_HASH_LISTPACK_EX_PRE_GA = 23
+	RDB_TYPE_HASH_METADATA           = 24
+	RDB_TYPE_HASH_LISTPACK_EX        = 25
+
+	// how a quicklist node is stored
+	RDB_QUICKLIST_NODE_PLAIN  = 1
+	RDB_QUICKLIST_NODE_PACKED = 2
 
-	// skip the header section -- will always be 'REDIS0011'
-	i := 9
+	// https://github.com/redis/redis/blob/unstable/src/listpack.c
+	LISTPACK_HEADER_SIZE = 6
+	LISTPACK_EOF         = 0xFF
+
+	// https://github.com/redis/redis/blob/unstable/src/intset.c, the
+	// encoding is the size of each integer in bytes
+	INTSET_HEADER_SIZE = 8
+	INTSET_ENC_INT16   = 2
+	INTSET_ENC_INT32   = 4
+	INTSET_ENC_INT64   = 8
+
+	// the length byte of a score stored as a string by RDB_TYPE_ZSET that
+	// stands for a score that has no digits
+	RDB_SCORE_NAN    = 253
+	RDB_SCORE_POSINF = 254
+	RDB_SCORE_NEGINF = 255
+
+	RDB_ENCVAL   = 3
+	RDB_6BITLEN  = 0
+	RDB_14BITLEN = 1
+	RDB_32BITLEN = 0x80
+	RDB_64BITLEN = 0x81
+
+	RDB_ENC_INT8  = 0
+	RDB_ENC_INT16 = 1
+	RDB_ENC_INT32 = 2
+	RDB_ENC_LZF   = 3
+)
+
+// the CRC-64/Jones polynomial used by Redis in its reflected form
+var rdbCRCTable = crc64.MakeTable(0x95AC9329AC4BC9B5)
+
+// rdbReader walks a dump file keeping the offset and the opcode being
+// decoded so every failure can say where the file stopped making sense
+type rdbReader struct {
+	b      []byte
+	pos    int
+	opcode byte
+}
+
+func (r *rdbReader) fail(reason string) error {
+	return customerror.RDBError{Offset: r.pos, Opcode: r.opcode, Reason: reason}
+}
+
+func (r *rdbReader) readByte() (byte, error) {
+	if r.pos >= len(r.b) {
+		return 0, r.fail("unexpected end of file")
+	}
+
+	c := r.b[r.pos]
+	r.pos++
+	return c, nil
+}
 
-	for b[i] != 0xFF {
-		if b[i] == 0xFA {
-			// parse metadata section
-			// contains zero or more "metadata subsections," which each specify a single metadata attribute
+func (r *rdbReader) readN(n int) ([]byte, error) {
+	if n < 0 || n > len(r.b)-r.pos {
+		return nil, r.fail("unexpected end of file")
+	}
+
+	p := r.b[r.pos : r.pos+n]
+	r.pos += n
+	return p, nil
+}
+
+// readLength decodes a length, encoded is true when the two most
+// significant bits are 11 and the value is one of the special encodings
+func (r *rdbReader) readLength() (n uint64, encoded bool, err error) {
+	c, err := r.readByte()
+	if err != nil {
+		return 0, false, err
+	}
+
+	switch c >> 6 {
+	case RDB_6BITLEN:
+		return uint64(c & 0x3F), false, nil
+	case RDB_14BITLEN:
+		next, err := r.readByte()
+		if err != nil {
+			return 0, false, err
+		}
+		return uint64(c&0x3F)<<8 | uint64(next), false, nil
+	case RDB_ENCVAL:
+		return uint64(c & 0x3F), true, nil
+	}
+
+	switch c {
+	case RDB_32BITLEN:
+		p, err := r.readN(4)
+		if err != nil {
+			return 0, false, err
+		}
+		return uint64(binary.BigEndian.Uint32(p)), false, nil
+	case RDB_64BITLEN:
+		p, err := r.readN(8)
+		if err != nil {
+			return 0, false, err
+		}
+		return binary.BigEndian.Uint64(p), false, nil
+	}
+
+	r.pos--
+	return 0, false, r.fail("unknown length encoding")
+}
+
+// readCount is a length that counts something, such as the keys of a
+// database, so it can never be a special encoding
+func (r *rdbReader) readCount() (uint64, error) {
+	n, encoded, err := r.readLength()
+	if err != nil {
+		return 0, err
+	}
+
+	if encoded {
+		r.pos--
+		return 0, r.fail("unexpected string encoding in length")
+	}
+
+	return n, nil
+}
+
+func (r *rdbReader) readString() ([]byte, error) {
+	n, encoded, err := r.readLength()
+	if err != nil {
+		return nil, err
+	}
+
+	if !encoded {
+		if n > uint64(len(r.b)-r.pos) {
+			return nil, r.fail("string length exceeds the file size")
+		}
+
+		p, err := r.readN(int(n))
+		return bytes.Clone(p), err
+	}
+
+	// integers as a string
+	switch n {
+	case RDB_ENC_INT8:
+		c, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		return strconv.AppendInt(nil, int64(int8(c)), 10), nil
+	case RDB_ENC_INT16:
+		p, err := r.readN(2)
+		if err != nil {
+			return nil, err
+		}
+		return strconv.AppendInt(nil, int64(int16(binary.LittleEndian.Uint16(p))), 10), nil
+	case RDB_ENC_INT32:
+		p, err := r.readN(4)
+		if err != nil {
+			return nil, err
+		}
+		return strconv.AppendInt(nil, int64(int32(binary.LittleEndian.Uint32(p))), 10), nil
+	case RDB_ENC_LZF:
+		return r.readLZFString()
+	}
+
+	r.pos--
+	return nil, r.fail("unknown string encoding")
+}
+
+func (r *rdbReader) readLZFString() ([]byte, error) {
+	clen, err := r.readCount()
+	if err != nil {
+		return nil, err
+	}
+
+	ulen, err := r.readCount()
+	if err != nil {
+		return nil, err
+	}
+
+	if clen > uint64(len(r.b)-r.pos) {
+		return nil, r.fail("compressed length exceeds the file size")
+	}
+
+	start := r.pos
+	p, _ := r.readN(int(clen))
+
+	out, ok := lzfDecompress(p, ulen)
+	if !ok {
+		r.pos = start
+		return nil, r.fail("invalid LZF compressed string")
+	}
+
+	return out, nil
+}
+
+// lzfDecompress expands a string compressed by Redis with liblzf, ok is
+// false when the data is malformed or does not expand to exactly ulen
+func lzfDecompress(in []byte, ulen uint64) ([]byte, bool) {
+	// a back reference copies at most 264 bytes from 3 input bytes
+	if ulen > uint64(len(in))*88+88 {
+		return nil, false
+	}
+
+	out := make([]byte, 0, ulen)
+	for i := 0; i < len(in); {
+		ctrl := int(in[i])
+		i++
+
+		if ctrl < 1<<5 {
+			// literal run of ctrl+1 bytes
+			n := ctrl + 1
+			if i+n > len(in) {
+				return nil, false
+			}
+			out = append(out, in[i:i+n]...)
+			i += n
+			continue
+		}
+
+		// back reference
+		n := ctrl >> 5
+		if n == 7 {
+			if i >= len(in) {
+				return nil, false
+			}
+			n += int(in[i])
 			i++
-			mki, mk := parseString(b, i)
-			i = mki
+		}
+		if i >= len(in) {
+			return nil, false
+		}
+		ref := len(out) - ((ctrl&0x1F)<<8 | int(in[i])) - 1
+		i++
+		if ref < 0 {
+			return nil, false
+		}
+
+		// the reference may overlap the bytes being written
+		for j := 0; j < n+2; j++ {
+			out = append(out, out[ref+j])
+		}
+	}
+
+	if uint64(len(out)) != ulen {
+		return nil, false
+	}
+
+	return out, true
+}
 
-			mvi, mv := parseString(b, i)
-			i = mvi
+// ParseRBDFile decodes a dump file into the keys it holds, keys that are
+// already expired are left out. Any malformed or unsupported content is
+// reported as a customerror.RDBError and no keys are returned
+func ParseRBDFile(b []byte) (map[string]*data.RedisValue, error) {
+	pairs := make(map[string]*data.RedisValue)
+	r := &rdbReader{b: b}
+
+	header, err := r.readN(RDB_HEADER_SIZE)
+	if err != nil || !bytes.HasPrefix(header, []byte(RDB_MAGIC)) {
+		r.pos = 0
+		return nil, r.fail("wrong signature")
+	}
+
+	version, err := strconv.Atoi(string(header[len(RDB_MAGIC):]))
+	if err != nil || version < 1 || version > RDB_VERSION {
+		r.pos = len(RDB_MAGIC)
+		return nil, r.fail("unsupported RDB version " + string(header[len(RDB_MAGIC):]))
+	}
+
+	var expiry time.Time
+	for {
+		start := r.pos
+		opcode, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		r.opcode = opcode
+
+		switch opcode {
+		case RDB_OPCODE_EOF:
+			if err := r.verifyChecksum(version); err != nil {
+				return nil, err
+			}
+			return pairs, nil
 
+		case RDB_OPCODE_AUX:
+			mk, err := r.readString()
+			if err != nil {
+				return nil, err
+			}
+			mv, err := r.readString()
+			if err != nil {
+				return nil, err
+			}
 			log.Printf("metadata key: %s, metadata value: %s", mk, mv)
 
-		} else if b[i] == 0xFE {
-			// parse database section
-			// contains zero or more "database subsections," which each describe a single database
-
-			// skip the index of the database & 0xFB
-			i += 3
-
-			// represents the size of the hash table that stores keys and values
-			j := int(b[i])
-			// skip size of the hash table that stores the expires of the keys
-			i += 2
-
-			for j > 0 {
-				if b[i] == 0x00 {
-					i = parseType(i, b, pairs, time.Time{})
-				} else if b[i] == 0xFC {
-					i++
-					// expire time expressed in milliseconds, stored as an 8-byte unsigned long
-					ms_start := i
-					mss := 8
-					i += mss
-					mse := b[ms_start:i]
-					e := binary.LittleEndian.Uint64(mse)
-
-					i = parseType(i, b, pairs, time.Unix(int64(e/1000), int64(e%1000)*int64(time.Millisecond)))
-				} else if b[i] == 0xFD {
-					i++
-					// expire time expressed in seconds, stored as an 4-byte unsigned integer
-					ms_start := i
-					mss := 4
-					i += mss
-					mse := b[ms_start:i]
-					e := binary.LittleEndian.Uint32(mse)
-
-					i = parseType(i, b, pairs, time.Unix(int64(e), 0))
+		case RDB_OPCODE_SELECTDB:
+			if _, err := r.readCount(); err != nil {
+				return nil, err
+			}
+
+		case RDB_OPCODE_RESIZEDB:
+			// the sizes of the key and expire hash tables, only a hint
+			if _, err := r.readCount(); err != nil {
+				return nil, err
+			}
+			if _, err := r.readCount(); err != nil {
+				return nil, err
+			}
+
+		case RDB_OPCODE_SLOT_INFO:
+			// slot id, slot size and expires slot size, only used by cluster
+			for range 3 {
+				if _, err := r.readCount(); err != nil {
+					return nil, err
 				}
-				j--
 			}
+
+		case RDB_OPCODE_EXPIRETIME_MS:
+			// expire time expressed in milliseconds, stored as an 8-byte unsigned long
+			p, err := r.readN(8)
+			if err != nil {
+				return nil, err
+			}
+			expiry = time.UnixMilli(int64(binary.LittleEndian.Uint64(p)))
+
+		case RDB_OPCODE_EXPIRETIME:
+			// expire time expressed in seconds, stored as an 4-byte unsigned integer
+			p, err := r.readN(4)
+			if err != nil {
+				return nil, err
+			}
+			expiry = time.Unix(int64(binary.LittleEndian.Uint32(p)), 0)
+
+		case RDB_OPCODE_FREQ:
+			// LFU frequency of the next key, eviction is not supported
+			if _, err := r.readByte(); err != nil {
+				return nil, err
+			}
+
+		case RDB_OPCODE_IDLE:
+			// LRU idle time of the next key, eviction is not supported
+			if _, err := r.readCount(); err != nil {
+				return nil, err
+			}
+
+		default:
+			r.pos = start
+			key, val, err := r.readObject(opcode)
+			if err != nil {
+				return nil, err
+			}
+
+			if val == nil {
+				expiry = time.Time{}
+				continue
+			}
+
+			var rv *data.RedisValue
+			if b, ok := val.([]byte); ok {
+				rv = data.NewStringValue(b, expiry)
+			} else {
+				rv = data.NewRedisValue(val, expiry)
+			}
+			if !rv.IsExpired() {
+				pairs[string(key)] = rv
+			}
+			expiry = time.Time{}
 		}
 	}
+}
+
+// readObject decodes a key and its value, t is the value type byte that
+// precedes the key
+func (r *rdbReader) readObject(t byte) ([]byte, any, error) {
+	start := r.pos
+	r.pos++
+
+	key, err := r.readString()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var val any
+	switch t {
+	case RDB_TYPE_STRING:
+		val, err = r.readString()
+	case RDB_TYPE_LIST:
+		val, err = r.readList()
+	case RDB_TYPE_LIST_QUICKLIST_2:
+		val, err = r.readQuicklist()
+	case RDB_TYPE_HASH:
+		val, err = r.readHash()
+	case RDB_TYPE_HASH_LISTPACK:
+		val, err = r.readHashListpack()
+	case RDB_TYPE_HASH_METADATA, RDB_TYPE_HASH_METADATA_PRE_GA:
+		val, err = r.readHashMetadata(t == RDB_TYPE_HASH_METADATA)
+	case RDB_TYPE_HASH_LISTPACK_EX, RDB_TYPE_HASH_LISTPACK_EX_PRE_GA:
+		val, err = r.readHashListpackEx(t == RDB_TYPE_HASH_LISTPACK_EX)
+	case RDB_TYPE_SET:
+		val, err = r.readSet()
+	case RDB_TYPE_SET_INTSET:
+		val, err = r.readSetIntset()
+	case RDB_TYPE_SET_LISTPACK:
+		val, err = r.readSetListpack()
+	case RDB_TYPE_ZSET, RDB_TYPE_ZSET_2:
+		val, err = r.readZSet(t == RDB_TYPE_ZSET_2)
+	case RDB_TYPE_ZSET_LISTPACK:
+		val, err = r.readZSetListpack()
+	default:
+		r.pos = start
+		return nil, nil, r.fail("unsupported value type")
+	}
 
-	return pairs
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// an empty collection is not a key, it is skipped like Redis does
+	if c, ok := val.(interface{ Len() int }); ok && c.Len() == 0 {
+		val = nil
+	}
+
+	return key, val, nil
 }
 
-func parseType(i int, b []byte, pairs map[string]*data.RedisValue, exp time.Time) int {
-	switch b[i] {
-	case 0x00:
-		i++
-		ki, key := parseString(b, i)
-		i = ki
+// readList decodes a list stored as a plain sequence of strings
+func (r *rdbReader) readList() (*data.Quicklist, error) {
+	n, err := r.readCount()
+	if err != nil {
+		return nil, err
+	}
+
+	ql := data.NewQuicklist()
+	for range n {
+		v, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+		ql.PushTail(v)
+	}
 
-		vi, val := parseString(b, i)
-		i = vi
+	return ql, nil
+}
+
+// readQuicklist decodes a list stored as its quicklist nodes, each node is
+// either a single element or a listpack of elements
+func (r *rdbReader) readQuicklist() (*data.Quicklist, error) {
+	n, err := r.readCount()
+	if err != nil {
+		return nil, err
+	}
 
-		rv := data.NewRedisValue(val, exp)
-		if !rv.IsExpired() {
-			pairs[fmt.Sprintf("%v", key)] = rv
+	ql := data.NewQuicklist()
+	for range n {
+		container, err := r.readCount()
+		if err != nil {
+			return nil, err
 		}
 
-	default:
-		log.Fatal(customerror.InvalidRDBValueTypeError{})
+		start := r.pos
+		b, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+
+		switch container {
+		case RDB_QUICKLIST_NODE_PLAIN:
+			ql.PushTail(b)
+		case RDB_QUICKLIST_NODE_PACKED:
+			entries, ok := decodeListpack(b)
+			if !ok {
+				r.pos = start
+				return nil, r.fail("invalid listpack")
+			}
+			for _, e := range entries {
+				ql.PushTail(e)
+			}
+		default:
+			r.pos = start
+			return nil, r.fail("unknown quicklist container")
+		}
 	}
 
-	return i
+	return ql, nil
 }
 
-func parseString(b []byte, i int) (int, any) {
-	var out any
-	if b[i] == 0xC0 || b[i] == 0xC2 {
-		// integers as a string
-		le := b[i] & 0x3F // the last six bits
-		i++
-		if le == 0 {
-			// 0 indicates that an 8 bit integer
-			i8 := int8(b[i])
-			out = strconv.FormatInt(int64(i8), 10)
-			i++
-		} else if le == 1 {
-			// 1 indicates that a 16 bit integer
-			size := 2
-			n := b[i : size+i]
-			i16 := int16(binary.LittleEndian.Uint16(n))
-			out = strconv.FormatInt(int64(i16), 10)
-			i += size
-		} else if le == 2 {
-			// 2 indicates that a 32 bit integer
-			size := 4
-			n := b[i : size+i]
-			i32 := int32(binary.LittleEndian.Uint32(n))
-			out = strconv.FormatInt(int64(i32), 10)
-			i += size
-		}
-	} else {
-		// length prefixed string
-		s := i
-		i += int(b[i]) + 1
-		out = string(b[s+1 : i])
-	}
-
-	return i, out
+// readHash decodes a hash stored as a sequence of field value pairs, it
+// keeps the dict encoding it had when it was saved
+func (r *rdbReader) readHash() (*data.Hash, error) {
+	n, err := r.readCount()
+	if err != nil {
+		return nil, err
+	}
+
+	h := data.NewHash()
+	h.Convert()
+	for range n {
+		field, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+		h.Set(field, value)
+	}
+
+	return h, nil
+}
+
+// readHashListpack decodes a compact hash stored as a single listpack of
+// fields each followed by its value
+func (r *rdbReader) readHashListpack() (*data.Hash, error) {
+	start := r.pos
+	b, err := r.readString()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, ok := decodeListpack(b)
+	if !ok || len(entries)%2 != 0 {
+		r.pos = start
+		return nil, r.fail("invalid listpack")
+	}
+
+	h := data.NewHash()
+	for i := 0; i < len(entries); i += 2 {
+		h.Set(entries[i], entries[i+1])
+	}
+
+	return h, nil
+}
+
+// readHashMetadata decodes a hash whose fields may expire, stored as a
+// sequence of expiry, field and value. An expiry of 0 means the field has
+// none, otherwise it is relative to the minimum expiry of the hash that
+// comes first or, in the PRE_GA format, absolute. Fields that already
+// expired are left out
+func (r *rdbReader) readHashMetadata(hasMinExpire bool) (*data.Hash, error) {
+	var minExpire uint64
+	if hasMinExpire {
+		p, err := r.readN(8)
+		if err != nil {
+			return nil, err
+		}
+		minExpire = binary.LittleEndian.Uint64(p)
+	}
+
+	n, err := r.readCount()
+	if err != nil {
+		return nil, err
+	}
+
+	h := data.NewHash()
+	h.Convert()
+	now := time.Now().UnixMilli()
+	for range n {
+		ttl, err := r.readCount()
+		if err != nil {
+			return nil, err
+		}
+
+		field, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+
+		when := ttl
+		if hasMinExpire && ttl != 0 {
+			when = ttl + minExpire - 1
+		}
+
+		if when != 0 && int64(when) <= now {
+			continue
+		}
+
+		h.Set(field, value)
+		if when != 0 {
+			h.SetFieldExpiry(field, time.UnixMilli(int64(when)))
+		}
+	}
+
+	return h, nil
+}
+
+// readHashListpackEx decodes a compact hash whose fields may expire, a
+// listpack of field, value and expiry in unix milliseconds or 0 when the
+// field has none. The minimum expiry of the hash comes first except in the
+// PRE_GA format. Fields that already expired are left out
+func (r *rdbReader) readHashListpackEx(hasMinExpire bool) (*data.Hash, error) {
+	if hasMinExpire {
+		if _, err := r.readN(8); err != nil {
+			return nil, err
+		}
+	}
+
+	start := r.pos
+	b, err := r.readString()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, ok := decodeListpack(b)
+	if !ok || len(entries)%3 != 0 {
+		r.pos = start
+		return nil, r.fail("invalid listpack")
+	}
+
+	h := data.NewHash()
+	now := time.Now().UnixMilli()
+	for i := 0; i < len(entries); i += 3 {
+		when, err := strconv.ParseInt(string(entries[i+2]), 10, 64)
+		if err != nil {
+			r.pos = start
+			return nil, r.fail("invalid hash field expiry")
+		}
+
+		if when != 0 && when <= now {
+			continue
+		}
+
+		h.Set(entries[i], entries[i+1])
+		if when != 0 {
+			h.SetFieldExpiry(entries[i], time.UnixMilli(when))
+		}
+	}
+
+	return h, nil
+}
+
+// readSet decodes a set stored as a sequence of members, it keeps the
+// dict encoding it had when it was saved
+func (r *rdbReader) readSet() (*data.Set, error) {
+	n, err := r.readCount()
+	if err != nil {
+		return nil, err
+	}
+
+	s := data.NewSet()
+	s.Convert(data.ENCODING_HASHTABLE)
+	for range n {
+		m, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+		s.Add(m)
+	}
+
+	return s, nil
+}
+
+// readSetIntset decodes a set of integers stored as an intset
+func (r *rdbReader) readSetIntset() (*data.Set, error) {
+	start := r.pos
+	b, err := r.readString()
+	if err != nil {
+		return nil, err
+	}
+
+	ints, ok := decodeIntset(b)
+	if !ok {
+		r.pos = start
+		return nil, r.fail("invalid intset")
+	}
+
+	s := data.NewSet()
+	for _, n := range ints {
+		s.Add(strconv.AppendInt(nil, n, 10))
+	}
+
+	return s, nil
+}
+
+// readSetListpack decodes a compact set stored as a listpack of members
+func (r *rdbReader) readSetListpack() (*data.Set, error) {
+	start := r.pos
+	b, err := r.readString()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, ok := decodeListpack(b)
+	if !ok {
+		r.pos = start
+		return nil, r.fail("invalid listpack")
+	}
+
+	s := data.NewSet()
+	s.Convert(data.ENCODING_LISTPACK)
+	for _, e := range entries {
+		s.Add(e)
+	}
+
+	return s, nil
+}
+
+// readZSet decodes a sorted set stored as a sequence of member and score,
+// the score is a little endian float64 or, in the older format, a string
+func (r *rdbReader) readZSet(binaryScores bool) (*data.ZSet, error) {
+	n, err := r.readCount()
+	if err != nil {
+		return nil, err
+	}
+
+	z := data.NewZSet()
+	for range n {
+		m, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+
+		var score float64
+		if binaryScores {
+			p, err := r.readN(8)
+			if err != nil {
+				return nil, err
+			}
+			score = math.Float64frombits(binary.LittleEndian.Uint64(p))
+		} else if score, err = r.readStringScore(); err != nil {
+			return nil, err
+		}
+
+		z.Add(m, score)
+	}
+
+	return z, nil
+}
+
+// readStringScore decodes a score stored as its length followed by its
+// digits, the infinities have a length of their own
+func (r *rdbReader) readStringScore() (float64, error) {
+	start := r.pos
+	n, err := r.readByte()
+	if err != nil {
+		return 0, err
+	}
+
+	switch n {
+	case RDB_SCORE_NAN:
+		return math.NaN(), nil
+	case RDB_SCORE_POSINF:
+		return math.Inf(1), nil
+	case RDB_SCORE_NEGINF:
+		return math.Inf(-1), nil
+	}
+
+	p, err := r.readN(int(n))
+	if err != nil {
+		return 0, err
+	}
+
+	score, err := strconv.ParseFloat(string(p), 64)
+	if err != nil {
+		r.pos = start
+		return 0, r.fail("invalid sorted set score")
+	}
+
+	return score, nil
+}
+
+// readZSetListpack decodes a compact sorted set stored as a listpack of
+// members each followed by its score
+func (r *rdbReader) readZSetListpack() (*data.ZSet, error) {
+	start := r.pos
+	b, err := r.readString()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, ok := decodeListpack(b)
+	if !ok || len(entries)%2 != 0 {
+		r.pos = start
+		return nil, r.fail("invalid listpack")
+	}
+
+	z := data.NewZSet()
+	for i := 0; i < len(entries); i += 2 {
+		score, err := strconv.ParseFloat(string(entries[i+1]), 64)
+		if err != nil {
+			r.pos = start
+			return nil, r.fail("invalid sorted set score")
+		}
+		z.Add(entries[i], score)
+	}
+
+	return z, nil
+}
+
+// decodeIntset returns the integers of an intset, a little endian header of
+// the size of each integer and their count followed by the integers in
+// ascending order. ok is false when the intset is malformed
+func decodeIntset(b []byte) ([]int64, bool) {
+	if len(b) < INTSET_HEADER_SIZE {
+		return nil, false
+	}
+
+	enc := int(binary.LittleEndian.Uint32(b))
+	n := int(binary.LittleEndian.Uint32(b[4:]))
+	if (enc != INTSET_ENC_INT16 && enc != INTSET_ENC_INT32 && enc != INTSET_ENC_INT64) ||
+		len(b) != INTSET_HEADER_SIZE+n*enc {
+		return nil, false
+	}
+
+	ints := make([]int64, n)
+	for i := range ints {
+		p := b[INTSET_HEADER_SIZE+i*enc:]
+		switch enc {
+		case INTSET_ENC_INT16:
+			ints[i] = int64(int16(binary.LittleEndian.Uint16(p)))
+		case INTSET_ENC_INT32:
+			ints[i] = int64(int32(binary.LittleEndian.Uint32(p)))
+		default:
+			ints[i] = int64(binary.LittleEndian.Uint64(p))
+		}
+	}
+
+	return ints, true
+}
+
+// decodeListpack returns the elements of a listpack, integers are turned
+// back into their string form. ok is false when the listpack is malformed
+func decodeListpack(b []byte) ([][]byte, bool) {
+	if len(b) < LISTPACK_HEADER_SIZE+1 || int(binary.LittleEndian.Uint32(b)) != len(b) {
+		return nil, false
+	}
+
+	var entries [][]byte
+	p := b[LISTPACK_HEADER_SIZE:]
+	for len(p) > 0 && p[0] != LISTPACK_EOF {
+		var e []byte
+		var n int
+		c := p[0]
+
+		// the encoding is in the most significant bits of the first byte,
+		// n is the size of the encoding and data without the backlen
+		switch {
+		case c&0x80 == 0:
+			e, n = strconv.AppendInt(nil, int64(c&0x7F), 10), 1
+		case c&0xC0 == 0x80:
+			l := int(c & 0x3F)
+			n = 1 + l
+			if len(p) < n {
+				return nil, false
+			}
+			e = bytes.Clone(p[1:n])
+		case c&0xE0 == 0xC0:
+			if len(p) < 2 {
+				return nil, false
+			}
+			v := int64(c&0x1F)<<8 | int64(p[1])
+			if v >= 1<<12 {
+				v -= 1 << 13
+			}
+			e, n = strconv.AppendInt(nil, v, 10), 2
+		case c&0xF0 == 0xE0:
+			if len(p) < 2 {
+				return nil, false
+			}
+			l := int(c&0x0F)<<8 | int(p[1])
+			n = 2 + l
+			if len(p) < n {
+				return nil, false
+			}
+			e = bytes.Clone(p[2:n])
+		case c == 0xF0:
+			if len(p) < 5 {
+				return nil, false
+			}
+			l := int(binary.LittleEndian.Uint32(p[1:]))
+			n = 5 + l
+			if l < 0 || len(p) < n {
+				return nil, false
+			}
+			e = bytes.Clone(p[5:n])
+		case c >= 0xF1 && c <= 0xF4:
+			// 16, 24, 32 and 64 bit signed integers
+			size := []int{2, 3, 4, 8}[c-0xF1]
+			n = 1 + size
+			if len(p) < n {
+				return nil, false
+			}
+			var u uint64
+			for i := size; i >= 1; i-- {
+				u = u<<8 | uint64(p[i])
+			}
+			shift := 64 - 8*size
+			e = strconv.AppendInt(nil, int64(u<<shift)>>shift, 10)
+		default:
+			return nil, false
+		}
+
+		backlen := listpackBacklen(n)
+		if len(p) < n+backlen {
+			return nil, false
+		}
+
+		entries = append(entries, e)
+		p = p[n+backlen:]
+	}
+
+	// the total size in the header puts the EOF byte last
+	if len(p) != 1 {
+		return nil, false
+	}
+
+	return entries, true
+}
+
+// listpackBacklen is the size of the backlen that follows an entry of n
+// bytes, it stores n in 7 bit groups
+func listpackBacklen(n int) int {
+	switch {
+	case n <= 127:
+		return 1
+	case n < 16383:
+		return 2
+	case n < 2097151:
+		return 3
+	case n < 268435455:
+		return 4
+	}
+
+	return 5
+}
+
+// verifyChecksum checks the CRC64 that follows the EOF opcode since
+// version 5, a checksum of zero means it was disabled when saving
+func (r *rdbReader) verifyChecksum(version int) error {
+	if version < 5 {
+		return nil
+	}
+
+	end := r.pos
+	p, err := r.readN(8)
+	if err != nil {
+		return err
+	}
+
+	want := binary.LittleEndian.Uint64(p)
+	if want == 0 {
+		return nil
+	}
+
+	if got := rdbChecksum(r.b[:end]); got != want {
+		r.pos = end
+		return r.fail("checksum mismatch")
+	}
+
+	return nil
+}
+
+func rdbChecksum(b []byte) uint64 {
+	// crc64 inverts the crc before and after, Redis does neither
+	return ^crc64.Update(^uint64(0), rdbCRCTable, b)
 }
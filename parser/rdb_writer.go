@@ -0,0 +1,368 @@
+package parser
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/JanitSri/codecrafters-build-your-own-redis/data"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/util"
+)
+
+// rdbWriter encodes a dump file in the format ParseRBDFile decodes
+type rdbWriter struct {
+	b []byte
+}
+
+func (w *rdbWriter) writeByte(c byte) {
+	w.b = append(w.b, c)
+}
+
+func (w *rdbWriter) writeMillis(ms int64) {
+	w.b = binary.LittleEndian.AppendUint64(w.b, uint64(ms))
+}
+
+// writeLength uses the smallest of the length encodings readLength decodes
+func (w *rdbWriter) writeLength(n uint64) {
+	switch {
+	case n < 1<<6:
+		w.writeByte(RDB_6BITLEN<<6 | byte(n))
+	case n < 1<<14:
+		w.writeByte(RDB_14BITLEN<<6 | byte(n>>8))
+		w.writeByte(byte(n))
+	case n <= math.MaxUint32:
+		w.writeByte(RDB_32BITLEN)
+		w.b = binary.BigEndian.AppendUint32(w.b, uint32(n))
+	default:
+		w.writeByte(RDB_64BITLEN)
+		w.b = binary.BigEndian.AppendUint64(w.b, n)
+	}
+}
+
+func (w *rdbWriter) writeString(b []byte) {
+	w.writeLength(uint64(len(b)))
+	w.b = append(w.b, b...)
+}
+
+// EncodeRDBFile serializes every key of the store that is not expired into
+// a dump file
+func EncodeRDBFile(ds data.DataStore) []byte {
+	w := &rdbWriter{}
+	w.b = fmt.Appendf(w.b, "%s%04d", RDB_MAGIC, RDB_VERSION)
+
+	for _, aux := range [][2]string{
+		{"redis-ver", SERVER_VERSION},
+		{"redis-bits", "64"},
+		{"ctime", strconv.FormatInt(time.Now().Unix(), 10)},
+		{"aof-base", "0"},
+	} {
+		w.writeByte(RDB_OPCODE_AUX)
+		w.writeString([]byte(aux[0]))
+		w.writeString([]byte(aux[1]))
+	}
+
+	keys := ds.Keys()
+	w.writeByte(RDB_OPCODE_SELECTDB)
+	w.writeLength(0)
+	w.writeByte(RDB_OPCODE_RESIZEDB)
+	w.writeLength(uint64(len(keys)))
+	w.writeLength(uint64(ds.VolatileLen()))
+
+	for _, k := range keys {
+		v, ok := ds.Get(k)
+		if !ok || v.IsExpired() {
+			continue
+		}
+
+		if !v.Expiry().IsZero() {
+			w.writeByte(RDB_OPCODE_EXPIRETIME_MS)
+			w.writeMillis(v.Expiry().UnixMilli())
+		}
+		w.writeObject(k, v)
+	}
+
+	w.writeByte(RDB_OPCODE_EOF)
+	w.b = binary.LittleEndian.AppendUint64(w.b, rdbChecksum(w.b))
+	return w.b
+}
+
+// writeObject encodes the value type, the key and the value
+func (w *rdbWriter) writeObject(key []byte, v *data.RedisValue) {
+	if b, ok := v.Bytes(); ok {
+		w.writeByte(RDB_TYPE_STRING)
+		w.writeString(key)
+		w.writeString(b)
+		return
+	}
+
+	switch o := v.Value().(type) {
+	case *data.Quicklist:
+		w.writeByte(RDB_TYPE_LIST_QUICKLIST_2)
+		w.writeString(key)
+		w.writeQuicklist(o)
+	case *data.Hash:
+		w.writeHash(key, o)
+	case *data.Set:
+		w.writeSet(key, o)
+	case *data.ZSet:
+		w.writeZSet(key, o)
+	default:
+		log.Printf("skipping key %s of type %s that can't be saved\n", key, v.Type())
+	}
+}
+
+// writeQuicklist stores the list as packed nodes of the same size limits
+// as the quicklist uses in memory
+func (w *rdbWriter) writeQuicklist(ql *data.Quicklist) {
+	var nodes [][][]byte
+	var node [][]byte
+	var size int
+	ql.Iterate(false, func(_ int, v []byte) bool {
+		if len(node) > 0 && (len(node) == data.QUICKLIST_NODE_MAX_ENTRIES || size+len(v) > data.QUICKLIST_NODE_MAX_BYTES) {
+			nodes = append(nodes, node)
+			node, size = nil, 0
+		}
+		node = append(node, v)
+		size += len(v)
+		return true
+	})
+	if len(node) > 0 {
+		nodes = append(nodes, node)
+	}
+
+	w.writeLength(uint64(len(nodes)))
+	for _, n := range nodes {
+		w.writeLength(RDB_QUICKLIST_NODE_PACKED)
+		w.writeString(encodeListpack(n))
+	}
+}
+
+// writeHash stores a compact hash as a listpack and the others as field
+// value pairs, a hash with field expiries stores the expiry of each field
+// relative to the minimum one
+func (w *rdbWriter) writeHash(key []byte, h *data.Hash) {
+	if !h.HasFieldExpiry() {
+		var pairs [][]byte
+		if h.Encoding() == data.ENCODING_LISTPACK {
+			h.Iterate(func(field, value []byte) bool {
+				pairs = append(pairs, field, value)
+				return true
+			})
+
+			w.writeByte(RDB_TYPE_HASH_LISTPACK)
+			w.writeString(key)
+			w.writeString(encodeListpack(pairs))
+			return
+		}
+
+		w.writeByte(RDB_TYPE_HASH)
+		w.writeString(key)
+		w.writeLength(uint64(h.Len()))
+		h.Iterate(func(field, value []byte) bool {
+			w.writeString(field)
+			w.writeString(value)
+			return true
+		})
+		return
+	}
+
+	var minExpire int64 = math.MaxInt64
+	h.Iterate(func(field, _ []byte) bool {
+		if t := h.FieldExpiry(field); !t.IsZero() {
+			minExpire = min(minExpire, t.UnixMilli())
+		}
+		return true
+	})
+
+	w.writeByte(RDB_TYPE_HASH_METADATA)
+	w.writeString(key)
+	w.writeMillis(minExpire)
+	w.writeLength(uint64(h.Len()))
+	h.Iterate(func(field, value []byte) bool {
+		var ttl uint64
+		if t := h.FieldExpiry(field); !t.IsZero() {
+			ttl = uint64(t.UnixMilli()-minExpire) + 1
+		}
+
+		w.writeLength(ttl)
+		w.writeString(field)
+		w.writeString(value)
+		return true
+	})
+}
+
+// writeSet stores a set with the encoding it has in memory
+func (w *rdbWriter) writeSet(key []byte, s *data.Set) {
+	var members [][]byte
+	s.Iterate(func(m []byte) bool {
+		members = append(members, m)
+		return true
+	})
+
+	switch s.Encoding() {
+	case data.ENCODING_INTSET:
+		w.writeByte(RDB_TYPE_SET_INTSET)
+		w.writeString(key)
+		w.writeString(encodeIntset(members))
+	case data.ENCODING_LISTPACK:
+		w.writeByte(RDB_TYPE_SET_LISTPACK)
+		w.writeString(key)
+		w.writeString(encodeListpack(members))
+	default:
+		w.writeByte(RDB_TYPE_SET)
+		w.writeString(key)
+		w.writeLength(uint64(len(members)))
+		for _, m := range members {
+			w.writeString(m)
+		}
+	}
+}
+
+// writeZSet stores a sorted set with binary scores, from the highest score
+// down like Redis does so loading inserts every member at the head
+func (w *rdbWriter) writeZSet(key []byte, z *data.ZSet) {
+	w.writeByte(RDB_TYPE_ZSET_2)
+	w.writeString(key)
+	w.writeLength(uint64(z.Len()))
+	z.RangeByRank(0, z.Len()-1, true, func(member []byte, score float64) bool {
+		w.writeString(member)
+		w.b = binary.LittleEndian.AppendUint64(w.b, math.Float64bits(score))
+		return true
+	})
+}
+
+// encodeIntset packs integers given in ascending order with the smallest
+// size that fits all of them
+func encodeIntset(members [][]byte) []byte {
+	ints := make([]int64, len(members))
+	enc := INTSET_ENC_INT16
+	for i, m := range members {
+		n, _ := util.ParseStrictInt(m)
+		ints[i] = n
+		switch {
+		case n < math.MinInt32 || n > math.MaxInt32:
+			enc = INTSET_ENC_INT64
+		case (n < math.MinInt16 || n > math.MaxInt16) && enc == INTSET_ENC_INT16:
+			enc = INTSET_ENC_INT32
+		}
+	}
+
+	b := binary.LittleEndian.AppendUint32(nil, uint32(enc))
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(ints)))
+	for _, n := range ints {
+		switch enc {
+		case INTSET_ENC_INT16:
+			b = binary.LittleEndian.AppendUint16(b, uint16(n))
+		case INTSET_ENC_INT32:
+			b = binary.LittleEndian.AppendUint32(b, uint32(n))
+		default:
+			b = binary.LittleEndian.AppendUint64(b, uint64(n))
+		}
+	}
+
+	return b
+}
+
+// encodeListpack packs the elements the way Redis does, strings that are
+// the canonical form of an integer are stored as the smallest integer
+// encoding that fits
+func encodeListpack(entries [][]byte) []byte {
+	b := make([]byte, LISTPACK_HEADER_SIZE)
+	for _, e := range entries {
+		start := len(b)
+		if n, ok := util.ParseStrictInt(e); ok {
+			b = appendListpackInt(b, n)
+		} else {
+			b = appendListpackString(b, e)
+		}
+		b = appendListpackBacklen(b, len(b)-start)
+	}
+	b = append(b, LISTPACK_EOF)
+
+	binary.LittleEndian.PutUint32(b, uint32(len(b)))
+	binary.LittleEndian.PutUint16(b[4:], uint16(min(len(entries), math.MaxUint16)))
+	return b
+}
+
+func appendListpackInt(b []byte, n int64) []byte {
+	switch {
+	case n >= 0 && n <= 127:
+		return append(b, byte(n))
+	case n >= -4096 && n <= 4095:
+		u := uint16(n) & 0x1FFF
+		return append(b, 0xC0|byte(u>>8), byte(u))
+	case n >= math.MinInt16 && n <= math.MaxInt16:
+		return binary.LittleEndian.AppendUint16(append(b, 0xF1), uint16(n))
+	case n >= -1<<23 && n < 1<<23:
+		u := uint32(n)
+		return append(b, 0xF2, byte(u), byte(u>>8), byte(u>>16))
+	case n >= math.MinInt32 && n <= math.MaxInt32:
+		return binary.LittleEndian.AppendUint32(append(b, 0xF3), uint32(n))
+	}
+
+	return binary.LittleEndian.AppendUint64(append(b, 0xF4), uint64(n))
+}
+
+func appendListpackString(b, s []byte) []byte {
+	switch n := len(s); {
+	case n < 64:
+		b = append(b, 0x80|byte(n))
+	case n < 4096:
+		b = append(b, 0xE0|byte(n>>8), byte(n))
+	default:
+		b = binary.LittleEndian.AppendUint32(append(b, 0xF0), uint32(n))
+	}
+
+	return append(b, s...)
+}
+
+// appendListpackBacklen stores the size of an entry so the listpack can be
+// walked backward, 7 bits per byte with the most significant ones first
+func appendListpackBacklen(b []byte, n int) []byte {
+	size := listpackBacklen(n)
+	for i := size - 1; i >= 0; i-- {
+		c := byte(n>>(7*i)) & 0x7F
+		if i < size-1 {
+			c |= 0x80
+		}
+		b = append(b, c)
+	}
+
+	return b
+}
+
+// SaveRDBFile writes the dataset to a temporary file that then replaces
+// the configured dump file, so a failed save never leaves it half written
+func SaveRDBFile(ds data.DataStore) error {
+	dir, _ := ds.GetConfig("dir")
+	fn, _ := ds.GetConfig("dbfilename")
+	if dir == "" || fn == "" {
+		return errors.New("dir and dbfilename are not configured")
+	}
+
+	tmp := filepath.Join(dir, fmt.Sprintf("temp-%d.rdb", os.Getpid()))
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(EncodeRDBFile(ds))
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, filepath.Join(dir, fn))
+}
@@ -0,0 +1,2138 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/JanitSri/codecrafters-build-your-own-redis/customerror"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/data"
+)
+
+// CommandSpec declares a command the way COMMAND INFO reports it, the
+// scanner checks the arity and the key positions before the command is
+// parsed so parse functions only deal with the command's own options
+//
+// https://redis.io/docs/latest/commands/command-info/
+type CommandSpec struct {
+	Name          string
+	Arity         int
+	Flags         []string
+	ACLCategories []string
+	FirstKey      int
+	LastKey       int
+	Step          int
+	Group         string
+	Since         string
+	Summary       string
+	Subcommands   []*CommandSpec
+	parse         func(args [][]byte) Command
+	parent        *CommandSpec
+	// movableKeys finds the keys of commands flagged movablekeys, whose key
+	// positions depend on the other arguments
+	movableKeys func(args [][]byte) []int
+}
+
+// FullName is the name used by COMMAND LIST, subcommands are reported as
+// container|subcommand
+func (cs *CommandSpec) FullName() string {
+	if cs.parent != nil {
+		return cs.parent.Name + "|" + cs.Name
+	}
+
+	return cs.Name
+}
+
+// checkArity validates the number of arguments including the command name,
+// a negative arity means at least that many arguments
+func (cs *CommandSpec) checkArity(n int) bool {
+	if cs.Arity < 0 {
+		return n >= -cs.Arity
+	}
+
+	return n == cs.Arity
+}
+
+func (cs *CommandSpec) HasFlag(f string) bool {
+	for _, cf := range cs.Flags {
+		if cf == f {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (cs *CommandSpec) HasACLCategory(c string) bool {
+	for _, cc := range cs.ACLCategories {
+		if strings.EqualFold(cc, c) || strings.EqualFold(cc, "@"+c) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (cs *CommandSpec) subcommand(name []byte) (*CommandSpec, bool) {
+	for _, sc := range cs.Subcommands {
+		if strings.EqualFold(sc.Name, string(name)) {
+			return sc, true
+		}
+	}
+
+	return nil, false
+}
+
+// keyPositions returns the indexes of the key arguments, args includes the
+// command name, subcommand keys are counted from the container name
+func (cs *CommandSpec) keyPositions(args [][]byte) []int {
+	if cs.movableKeys != nil {
+		return cs.movableKeys(args)
+	}
+
+	if cs.FirstKey <= 0 {
+		return nil
+	}
+
+	last := cs.LastKey
+	if last < 0 {
+		last = len(args) + last
+	}
+
+	var pos []int
+	for i := cs.FirstKey; i <= last && i < len(args); i += cs.Step {
+		pos = append(pos, i)
+	}
+
+	return pos
+}
+
+// call wraps a parsed command with its spec so the rules shared by every
+// command are applied around its execution
+type call struct {
+	spec *CommandSpec
+	args [][]byte
+	cmd  Command
+}
+
+// commands a RESP2 client may send once it is subscribed to a channel
+var pubSubAllowed = map[string]bool{
+	"subscribe":    true,
+	"unsubscribe":  true,
+	"psubscribe":   true,
+	"punsubscribe": true,
+	"ssubscribe":   true,
+	"sunsubscribe": true,
+	"ping":         true,
+	"quit":         true,
+	"reset":        true,
+}
+
+func (c *call) Execute(rc *data.RedisContext) []byte {
+	if rc.Client.Protocol() == data.RESP2 && rc.Client.SubscriptionCount() > 0 && !pubSubAllowed[c.spec.FullName()] {
+		w := NewReplyWriter(rc.Client.Protocol())
+		w.Error(customerror.PubSubContextError{Cmd: c.spec.FullName()})
+		return w.Bytes()
+	}
+
+	b := c.cmd.Execute(rc)
+
+	// clients blocked on the keys the command pushed to are served before
+	// any other command runs
+	rc.Blocking.ServeReady()
+
+	if c.spec.HasFlag(FLAG_READONLY) {
+		rememberKeys(rc, c.spec, c.args)
+	}
+
+	// CLIENT CACHING only applies to the command that follows it
+	if c.spec.FullName() != "client|caching" {
+		rc.Client.SetCaching(false)
+	}
+
+	return b
+}
+
+var commandTable = map[string]*CommandSpec{}
+
+func registerCommands(specs ...*CommandSpec) {
+	for _, cs := range specs {
+		for _, sc := range cs.Subcommands {
+			sc.parent = cs
+		}
+		commandTable[cs.Name] = cs
+	}
+}
+
+func lookupCommand(name []byte) (*CommandSpec, bool) {
+	cs, ok := commandTable[strings.ToLower(string(name))]
+	return cs, ok
+}
+
+// sortedCommands returns every registered command ordered by name so the
+// introspection replies are stable
+func sortedCommands() []*CommandSpec {
+	specs := make([]*CommandSpec, 0, len(commandTable))
+	for _, cs := range commandTable {
+		specs = append(specs, cs)
+	}
+
+	sort.Slice(specs, func(i, j int) bool {
+		return specs[i].Name < specs[j].Name
+	})
+
+	return specs
+}
+
+func init() {
+	registerCommands(
+		&CommandSpec{
+			Name:          "ping",
+			Arity:         -1,
+			Flags:         []string{FLAG_FAST},
+			ACLCategories: []string{ACL_FAST, ACL_CONNECTION},
+			Group:         "connection",
+			Since:         "1.0.0",
+			Summary:       "Returns the server's liveliness response.",
+			parse:         parsePingCmd,
+		},
+		&CommandSpec{
+			Name:          "echo",
+			Arity:         2,
+			Flags:         []string{FLAG_FAST},
+			ACLCategories: []string{ACL_FAST, ACL_CONNECTION},
+			Group:         "connection",
+			Since:         "1.0.0",
+			Summary:       "Returns the given string.",
+			parse:         parseEchoCmd,
+		},
+		&CommandSpec{
+			Name:          "hello",
+			Arity:         -1,
+			Flags:         []string{FLAG_NOSCRIPT, FLAG_LOADING, FLAG_STALE, FLAG_FAST, FLAG_NO_AUTH, FLAG_ALLOW_BUSY},
+			ACLCategories: []string{ACL_FAST, ACL_CONNECTION},
+			Group:         "connection",
+			Since:         "6.0.0",
+			Summary:       "Handshakes with the Redis server.",
+			parse:         parseHelloCmd,
+		},
+		&CommandSpec{
+			Name:          "get",
+			Arity:         2,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_STRING, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "string",
+			Since:         "1.0.0",
+			Summary:       "Returns the string value of a key.",
+			parse:         parseGetCmd,
+		},
+		&CommandSpec{
+			Name:          "set",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM},
+			ACLCategories: []string{ACL_WRITE, ACL_STRING, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "string",
+			Since:         "1.0.0",
+			Summary:       "Sets the string value of a key, ignoring its type. The key is created if it doesn't exist.",
+			parse:         parseSetCmd,
+		},
+		&CommandSpec{
+			Name:          "incr",
+			Arity:         2,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_STRING, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "string",
+			Since:         "1.0.0",
+			Summary:       "Increments the integer value of a key by one. Uses 0 as initial value if the key doesn't exist.",
+			parse:         parseIncrCmd,
+		},
+		&CommandSpec{
+			Name:          "decr",
+			Arity:         2,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_STRING, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "string",
+			Since:         "1.0.0",
+			Summary:       "Decrements the integer value of a key by one. Uses 0 as initial value if the key doesn't exist.",
+			parse:         parseDecrCmd,
+		},
+		&CommandSpec{
+			Name:          "incrby",
+			Arity:         3,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_STRING, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "string",
+			Since:         "1.0.0",
+			Summary:       "Increments the integer value of a key by a number. Uses 0 as initial value if the key doesn't exist.",
+			parse:         parseIncrByCmd,
+		},
+		&CommandSpec{
+			Name:          "decrby",
+			Arity:         3,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_STRING, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "string",
+			Since:         "1.0.0",
+			Summary:       "Decrements a number from the integer value of a key. Uses 0 as initial value if the key doesn't exist.",
+			parse:         parseDecrByCmd,
+		},
+		&CommandSpec{
+			Name:          "incrbyfloat",
+			Arity:         3,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_STRING, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "string",
+			Since:         "2.6.0",
+			Summary:       "Increment the floating point value of a key by a number. Uses 0 as initial value if the key doesn't exist.",
+			parse:         parseIncrByFloatCmd,
+		},
+		&CommandSpec{
+			Name:          "append",
+			Arity:         3,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM},
+			ACLCategories: []string{ACL_WRITE, ACL_STRING, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "string",
+			Since:         "2.0.0",
+			Summary:       "Appends a string to the value of a key. Creates the key if it doesn't exist.",
+			parse:         parseAppendCmd,
+		},
+		&CommandSpec{
+			Name:          "strlen",
+			Arity:         2,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_STRING, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "string",
+			Since:         "2.2.0",
+			Summary:       "Returns the length of a string value.",
+			parse:         parseStrlenCmd,
+		},
+		&CommandSpec{
+			Name:          "getrange",
+			Arity:         4,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_STRING, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "string",
+			Since:         "2.4.0",
+			Summary:       "Returns a substring of the string stored at a key.",
+			parse:         parseGetRangeCmd,
+		},
+		&CommandSpec{
+			Name:          "setrange",
+			Arity:         4,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM},
+			ACLCategories: []string{ACL_WRITE, ACL_STRING, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "string",
+			Since:         "2.2.0",
+			Summary:       "Overwrites a part of a string value with another by an offset. Creates the key if it doesn't exist.",
+			parse:         parseSetRangeCmd,
+		},
+		&CommandSpec{
+			Name:          "getdel",
+			Arity:         2,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_STRING, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "string",
+			Since:         "6.2.0",
+			Summary:       "Returns the string value of a key after deleting the key.",
+			parse:         parseGetDelCmd,
+		},
+		&CommandSpec{
+			Name:          "getex",
+			Arity:         -2,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_STRING, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "string",
+			Since:         "6.2.0",
+			Summary:       "Returns the string value of a key after setting its expiration time.",
+			parse:         parseGetExCmd,
+		},
+		&CommandSpec{
+			Name:          "getset",
+			Arity:         3,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_STRING, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "string",
+			Since:         "1.0.0",
+			Summary:       "Returns the previous string value of a key after setting it to a new value.",
+			parse:         parseGetSetCmd,
+		},
+		&CommandSpec{
+			Name:          "mget",
+			Arity:         -2,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_STRING, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       -1,
+			Step:          1,
+			Group:         "string",
+			Since:         "1.0.0",
+			Summary:       "Atomically returns the string values of one or more keys.",
+			parse:         parseMGetCmd,
+		},
+		&CommandSpec{
+			Name:          "mset",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM},
+			ACLCategories: []string{ACL_WRITE, ACL_STRING, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       -1,
+			Step:          2,
+			Group:         "string",
+			Since:         "1.0.1",
+			Summary:       "Atomically creates or modifies the string values of one or more keys.",
+			parse:         parseMSetCmd,
+		},
+		&CommandSpec{
+			Name:          "msetnx",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM},
+			ACLCategories: []string{ACL_WRITE, ACL_STRING, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       -1,
+			Step:          2,
+			Group:         "string",
+			Since:         "1.0.1",
+			Summary:       "Atomically modifies the string values of one or more keys only when all keys don't exist.",
+			parse:         parseMSetNxCmd,
+		},
+		&CommandSpec{
+			Name:          "setnx",
+			Arity:         3,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_STRING, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "string",
+			Since:         "1.0.0",
+			Summary:       "Set the string value of a key only when the key doesn't exist.",
+			parse:         parseSetNxCmd,
+		},
+		&CommandSpec{
+			Name:          "setex",
+			Arity:         4,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM},
+			ACLCategories: []string{ACL_WRITE, ACL_STRING, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "string",
+			Since:         "2.0.0",
+			Summary:       "Sets the string value and expiration time of a key. Creates the key if it doesn't exist.",
+			parse:         parseSetExCmd,
+		},
+		&CommandSpec{
+			Name:          "psetex",
+			Arity:         4,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM},
+			ACLCategories: []string{ACL_WRITE, ACL_STRING, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "string",
+			Since:         "2.6.0",
+			Summary:       "Sets both string value and expiration time in milliseconds of a key. The key is created if it doesn't exist.",
+			parse:         parsePSetExCmd,
+		},
+		&CommandSpec{
+			Name:          "lcs",
+			Arity:         -3,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_STRING, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       2,
+			Step:          1,
+			Group:         "string",
+			Since:         "7.0.0",
+			Summary:       "Finds the longest common substring.",
+			parse:         parseLcsCmd,
+		},
+		&CommandSpec{
+			Name:          "setbit",
+			Arity:         4,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM},
+			ACLCategories: []string{ACL_WRITE, ACL_BITMAP, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "bitmap",
+			Since:         "2.2.0",
+			Summary:       "Sets or clears the bit at offset of the string value. Creates the key if it doesn't exist.",
+			parse:         parseSetBitCmd,
+		},
+		&CommandSpec{
+			Name:          "getbit",
+			Arity:         3,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_BITMAP, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "bitmap",
+			Since:         "2.2.0",
+			Summary:       "Returns a bit value by offset.",
+			parse:         parseGetBitCmd,
+		},
+		&CommandSpec{
+			Name:          "bitcount",
+			Arity:         -2,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_BITMAP, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "bitmap",
+			Since:         "2.6.0",
+			Summary:       "Counts the number of set bits (population counting) in a string.",
+			parse:         parseBitCountCmd,
+		},
+		&CommandSpec{
+			Name:          "bitpos",
+			Arity:         -3,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_BITMAP, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "bitmap",
+			Since:         "2.8.7",
+			Summary:       "Finds the first set (1) or clear (0) bit in a string.",
+			parse:         parseBitPosCmd,
+		},
+		&CommandSpec{
+			Name:          "bitop",
+			Arity:         -4,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM},
+			ACLCategories: []string{ACL_WRITE, ACL_BITMAP, ACL_SLOW},
+			FirstKey:      2,
+			LastKey:       -1,
+			Step:          1,
+			Group:         "bitmap",
+			Since:         "2.6.0",
+			Summary:       "Performs bitwise operations on multiple strings, and stores the result.",
+			parse:         parseBitOpCmd,
+		},
+		&CommandSpec{
+			Name:          "bitfield",
+			Arity:         -2,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM},
+			ACLCategories: []string{ACL_WRITE, ACL_BITMAP, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "bitmap",
+			Since:         "3.2.0",
+			Summary:       "Performs arbitrary bitfield integer operations on strings.",
+			parse:         parseBitfieldCmd,
+		},
+		&CommandSpec{
+			Name:          "bitfield_ro",
+			Arity:         -2,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_BITMAP, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "bitmap",
+			Since:         "6.0.0",
+			Summary:       "Performs arbitrary read-only bitfield integer operations on strings.",
+			parse:         parseBitfieldRoCmd,
+		},
+		&CommandSpec{
+			Name:          "del",
+			Arity:         -2,
+			Flags:         []string{FLAG_WRITE},
+			ACLCategories: []string{ACL_KEYSPACE, ACL_WRITE, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       -1,
+			Step:          1,
+			Group:         "generic",
+			Since:         "1.0.0",
+			Summary:       "Deletes one or more keys.",
+			parse:         parseDelCmd,
+		},
+		&CommandSpec{
+			Name:          "unlink",
+			Arity:         -2,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_KEYSPACE, ACL_WRITE, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       -1,
+			Step:          1,
+			Group:         "generic",
+			Since:         "4.0.0",
+			Summary:       "Asynchronously deletes one or more keys.",
+			parse:         parseDelCmd,
+		},
+		&CommandSpec{
+			Name:          "exists",
+			Arity:         -2,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_KEYSPACE, ACL_READ, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       -1,
+			Step:          1,
+			Group:         "generic",
+			Since:         "1.0.0",
+			Summary:       "Determines whether one or more keys exist.",
+			parse:         parseExistsCmd,
+		},
+		&CommandSpec{
+			Name:          "type",
+			Arity:         2,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_KEYSPACE, ACL_READ, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "generic",
+			Since:         "1.0.0",
+			Summary:       "Determines the type of value stored at a key.",
+			parse:         parseTypeCmd,
+		},
+		&CommandSpec{
+			Name:          "rename",
+			Arity:         3,
+			Flags:         []string{FLAG_WRITE},
+			ACLCategories: []string{ACL_KEYSPACE, ACL_WRITE, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       2,
+			Step:          1,
+			Group:         "generic",
+			Since:         "1.0.0",
+			Summary:       "Renames a key and overwrites the destination.",
+			parse:         parseRenameCmd,
+		},
+		&CommandSpec{
+			Name:          "renamenx",
+			Arity:         3,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_KEYSPACE, ACL_WRITE, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       2,
+			Step:          1,
+			Group:         "generic",
+			Since:         "1.0.0",
+			Summary:       "Renames a key only when the target key name doesn't exist.",
+			parse:         parseRenameNxCmd,
+		},
+		&CommandSpec{
+			Name:          "copy",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM},
+			ACLCategories: []string{ACL_KEYSPACE, ACL_WRITE, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       2,
+			Step:          1,
+			Group:         "generic",
+			Since:         "6.2.0",
+			Summary:       "Copies the value of a key to a new key.",
+			parse:         parseCopyCmd,
+		},
+		&CommandSpec{
+			Name:          "touch",
+			Arity:         -2,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_KEYSPACE, ACL_READ, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       -1,
+			Step:          1,
+			Group:         "generic",
+			Since:         "3.2.1",
+			Summary:       "Returns the number of existing keys out of those specified after updating the time they were last accessed.",
+			parse:         parseTouchCmd,
+		},
+		&CommandSpec{
+			Name:          "randomkey",
+			Arity:         1,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_KEYSPACE, ACL_READ, ACL_SLOW},
+			Group:         "generic",
+			Since:         "1.0.0",
+			Summary:       "Returns a random key name from the database.",
+			parse:         parseRandomKeyCmd,
+		},
+		&CommandSpec{
+			Name:          "expire",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_KEYSPACE, ACL_WRITE, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "generic",
+			Since:         "1.0.0",
+			Summary:       "Sets the expiration time of a key in seconds.",
+			parse:         parseExpireCmd,
+		},
+		&CommandSpec{
+			Name:          "pexpire",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_KEYSPACE, ACL_WRITE, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "generic",
+			Since:         "2.6.0",
+			Summary:       "Sets the expiration time of a key in milliseconds.",
+			parse:         parsePExpireCmd,
+		},
+		&CommandSpec{
+			Name:          "expireat",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_KEYSPACE, ACL_WRITE, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "generic",
+			Since:         "1.2.0",
+			Summary:       "Sets the expiration time of a key to a Unix timestamp.",
+			parse:         parseExpireAtCmd,
+		},
+		&CommandSpec{
+			Name:          "pexpireat",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_KEYSPACE, ACL_WRITE, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "generic",
+			Since:         "2.6.0",
+			Summary:       "Sets the expiration time of a key to a Unix milliseconds timestamp.",
+			parse:         parsePExpireAtCmd,
+		},
+		&CommandSpec{
+			Name:          "ttl",
+			Arity:         2,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_KEYSPACE, ACL_READ, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "generic",
+			Since:         "1.0.0",
+			Summary:       "Returns the expiration time in seconds of a key.",
+			parse:         parseTtlCmd,
+		},
+		&CommandSpec{
+			Name:          "pttl",
+			Arity:         2,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_KEYSPACE, ACL_READ, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "generic",
+			Since:         "2.6.0",
+			Summary:       "Returns the expiration time in milliseconds of a key.",
+			parse:         parsePTtlCmd,
+		},
+		&CommandSpec{
+			Name:          "expiretime",
+			Arity:         2,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_KEYSPACE, ACL_READ, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "generic",
+			Since:         "7.0.0",
+			Summary:       "Returns the expiration time of a key as a Unix timestamp.",
+			parse:         parseExpireTimeCmd,
+		},
+		&CommandSpec{
+			Name:          "pexpiretime",
+			Arity:         2,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_KEYSPACE, ACL_READ, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "generic",
+			Since:         "7.0.0",
+			Summary:       "Returns the expiration time of a key as a Unix milliseconds timestamp.",
+			parse:         parsePExpireTimeCmd,
+		},
+		&CommandSpec{
+			Name:          "persist",
+			Arity:         2,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_KEYSPACE, ACL_WRITE, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "generic",
+			Since:         "2.2.0",
+			Summary:       "Removes the expiration time of a key.",
+			parse:         parsePersistCmd,
+		},
+		&CommandSpec{
+			Name:          "scan",
+			Arity:         -2,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_KEYSPACE, ACL_READ, ACL_SLOW},
+			Group:         "generic",
+			Since:         "2.8.0",
+			Summary:       "Iterates over the key names in the database.",
+			parse:         parseScanCmd,
+		},
+		&CommandSpec{
+			Name:          "hset",
+			Arity:         -4,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "2.0.0",
+			Summary:       "Creates or modifies the value of a field in a hash.",
+			parse:         parseHSetCmd,
+		},
+		&CommandSpec{
+			Name:          "hsetnx",
+			Arity:         4,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "2.0.0",
+			Summary:       "Sets the value of a field in a hash only when the field doesn't exist.",
+			parse:         parseHSetNXCmd,
+		},
+		&CommandSpec{
+			Name:          "hget",
+			Arity:         3,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "2.0.0",
+			Summary:       "Returns the value of a field in a hash.",
+			parse:         parseHGetCmd,
+		},
+		&CommandSpec{
+			Name:          "hmget",
+			Arity:         -3,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "2.0.0",
+			Summary:       "Returns the values of all fields in a hash.",
+			parse:         parseHMGetCmd,
+		},
+		&CommandSpec{
+			Name:          "hdel",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "2.0.0",
+			Summary:       "Deletes one or more fields and their values from a hash. Deletes the hash if no fields remain.",
+			parse:         parseHDelCmd,
+		},
+		&CommandSpec{
+			Name:          "hgetall",
+			Arity:         2,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_HASH, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "2.0.0",
+			Summary:       "Returns all fields and values in a hash.",
+			parse:         parseHGetAllCmd,
+		},
+		&CommandSpec{
+			Name:          "hkeys",
+			Arity:         2,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_HASH, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "2.0.0",
+			Summary:       "Returns all fields in a hash.",
+			parse:         parseHKeysCmd,
+		},
+		&CommandSpec{
+			Name:          "hvals",
+			Arity:         2,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_HASH, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "2.0.0",
+			Summary:       "Returns all values in a hash.",
+			parse:         parseHValsCmd,
+		},
+		&CommandSpec{
+			Name:          "hexists",
+			Arity:         3,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "2.0.0",
+			Summary:       "Determines whether a field exists in a hash.",
+			parse:         parseHExistsCmd,
+		},
+		&CommandSpec{
+			Name:          "hlen",
+			Arity:         2,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "2.0.0",
+			Summary:       "Returns the number of fields in a hash.",
+			parse:         parseHLenCmd,
+		},
+		&CommandSpec{
+			Name:          "hstrlen",
+			Arity:         3,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "3.2.0",
+			Summary:       "Returns the length of the value of a field.",
+			parse:         parseHStrLenCmd,
+		},
+		&CommandSpec{
+			Name:          "hincrby",
+			Arity:         4,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "2.0.0",
+			Summary:       "Increments the integer value of a field in a hash by a number. Uses 0 as initial value if the field doesn't exist.",
+			parse:         parseHIncrByCmd,
+		},
+		&CommandSpec{
+			Name:          "hincrbyfloat",
+			Arity:         4,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "2.6.0",
+			Summary:       "Increments the floating point value of a field by a number. Uses 0 as initial value if the field doesn't exist.",
+			parse:         parseHIncrByFloatCmd,
+		},
+		&CommandSpec{
+			Name:          "hrandfield",
+			Arity:         -2,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_HASH, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "6.2.0",
+			Summary:       "Returns one or more random fields from a hash.",
+			parse:         parseHRandFieldCmd,
+		},
+		&CommandSpec{
+			Name:          "hexpire",
+			Arity:         -6,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "7.4.0",
+			Summary:       "Set expiry for hash field using relative time to expire (seconds)",
+			parse:         parseHExpireCmd,
+		},
+		&CommandSpec{
+			Name:          "hpexpire",
+			Arity:         -6,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "7.4.0",
+			Summary:       "Set expiry for hash field using relative time to expire (milliseconds)",
+			parse:         parseHPExpireCmd,
+		},
+		&CommandSpec{
+			Name:          "hexpireat",
+			Arity:         -6,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "7.4.0",
+			Summary:       "Set expiry for hash field using an absolute Unix timestamp (seconds)",
+			parse:         parseHExpireAtCmd,
+		},
+		&CommandSpec{
+			Name:          "hpexpireat",
+			Arity:         -6,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "7.4.0",
+			Summary:       "Set expiry for hash field using an absolute Unix timestamp (milliseconds)",
+			parse:         parseHPExpireAtCmd,
+		},
+		&CommandSpec{
+			Name:          "httl",
+			Arity:         -5,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "7.4.0",
+			Summary:       "Returns the TTL in seconds of a hash field.",
+			parse:         parseHTtlCmd,
+		},
+		&CommandSpec{
+			Name:          "hpttl",
+			Arity:         -5,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "7.4.0",
+			Summary:       "Returns the TTL in milliseconds of a hash field.",
+			parse:         parseHPTtlCmd,
+		},
+		&CommandSpec{
+			Name:          "hexpiretime",
+			Arity:         -5,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "7.4.0",
+			Summary:       "Returns the expiration time of a hash field as a Unix timestamp, in seconds.",
+			parse:         parseHExpireTimeCmd,
+		},
+		&CommandSpec{
+			Name:          "hpexpiretime",
+			Arity:         -5,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "7.4.0",
+			Summary:       "Returns the expiration time of a hash field as a Unix timestamp, in msec.",
+			parse:         parseHPExpireTimeCmd,
+		},
+		&CommandSpec{
+			Name:          "hpersist",
+			Arity:         -5,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "7.4.0",
+			Summary:       "Removes the expiration time for each specified field",
+			parse:         parseHPersistCmd,
+		},
+		&CommandSpec{
+			Name:          "hgetex",
+			Arity:         -5,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "8.0.0",
+			Summary:       "Get the value of one or more fields of a given hash key, and optionally set their expiration.",
+			parse:         parseHGetExCmd,
+		},
+		&CommandSpec{
+			Name:          "hsetex",
+			Arity:         -6,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "8.0.0",
+			Summary:       "Set the value of one or more fields of a given hash key, and optionally set their expiration.",
+			parse:         parseHSetExCmd,
+		},
+		&CommandSpec{
+			Name:          "hgetdel",
+			Arity:         -5,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_HASH, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "8.0.0",
+			Summary:       "Returns the value of a field and deletes it from the hash.",
+			parse:         parseHGetDelCmd,
+		},
+		&CommandSpec{
+			Name:          "hscan",
+			Arity:         -3,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_HASH, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "hash",
+			Since:         "2.8.0",
+			Summary:       "Iterates over fields and values of a hash.",
+			parse:         parseHScanCmd,
+		},
+		&CommandSpec{
+			Name:          "sadd",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_SET, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "set",
+			Since:         "1.0.0",
+			Summary:       "Adds one or more members to a set. Creates the key if it doesn't exist.",
+			parse:         parseSAddCmd,
+		},
+		&CommandSpec{
+			Name:          "srem",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_SET, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "set",
+			Since:         "1.0.0",
+			Summary:       "Removes one or more members from a set. Deletes the set if the last member was removed.",
+			parse:         parseSRemCmd,
+		},
+		&CommandSpec{
+			Name:          "smembers",
+			Arity:         2,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_SET, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "set",
+			Since:         "1.0.0",
+			Summary:       "Returns all members of a set.",
+			parse:         parseSMembersCmd,
+		},
+		&CommandSpec{
+			Name:          "sismember",
+			Arity:         3,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_SET, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "set",
+			Since:         "1.0.0",
+			Summary:       "Determines whether a member belongs to a set.",
+			parse:         parseSIsMemberCmd,
+		},
+		&CommandSpec{
+			Name:          "smismember",
+			Arity:         -3,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_SET, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "set",
+			Since:         "6.2.0",
+			Summary:       "Determines whether multiple members belong to a set.",
+			parse:         parseSMIsMemberCmd,
+		},
+		&CommandSpec{
+			Name:          "scard",
+			Arity:         2,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_SET, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "set",
+			Since:         "1.0.0",
+			Summary:       "Returns the number of members in a set.",
+			parse:         parseSCardCmd,
+		},
+		&CommandSpec{
+			Name:          "spop",
+			Arity:         -2,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_SET, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "set",
+			Since:         "1.0.0",
+			Summary:       "Returns one or more random members from a set after removing them. Deletes the set if the last member was popped.",
+			parse:         parseSPopCmd,
+		},
+		&CommandSpec{
+			Name:          "srandmember",
+			Arity:         -2,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_SET, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "set",
+			Since:         "1.0.0",
+			Summary:       "Get one or multiple random members from a set",
+			parse:         parseSRandMemberCmd,
+		},
+		&CommandSpec{
+			Name:          "smove",
+			Arity:         4,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_SET, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       2,
+			Step:          1,
+			Group:         "set",
+			Since:         "1.0.0",
+			Summary:       "Moves a member from one set to another.",
+			parse:         parseSMoveCmd,
+		},
+		&CommandSpec{
+			Name:          "sinter",
+			Arity:         -2,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_SET, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       -1,
+			Step:          1,
+			Group:         "set",
+			Since:         "1.0.0",
+			Summary:       "Returns the intersect of multiple sets.",
+			parse:         parseSInterCmd,
+		},
+		&CommandSpec{
+			Name:          "sintercard",
+			Arity:         -3,
+			Flags:         []string{FLAG_READONLY, FLAG_MOVABLEKEYS},
+			ACLCategories: []string{ACL_READ, ACL_SET, ACL_SLOW},
+			Group:         "set",
+			Since:         "7.0.0",
+			Summary:       "Returns the number of members of the intersect of multiple sets.",
+			parse:         parseSInterCardCmd,
+			movableKeys:   mpopKeys(1),
+		},
+		&CommandSpec{
+			Name:          "sinterstore",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM},
+			ACLCategories: []string{ACL_WRITE, ACL_SET, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       -1,
+			Step:          1,
+			Group:         "set",
+			Since:         "1.0.0",
+			Summary:       "Stores the intersect of multiple sets in a key.",
+			parse:         parseSInterStoreCmd,
+		},
+		&CommandSpec{
+			Name:          "sunion",
+			Arity:         -2,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_SET, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       -1,
+			Step:          1,
+			Group:         "set",
+			Since:         "1.0.0",
+			Summary:       "Returns the union of multiple sets.",
+			parse:         parseSUnionCmd,
+		},
+		&CommandSpec{
+			Name:          "sunionstore",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM},
+			ACLCategories: []string{ACL_WRITE, ACL_SET, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       -1,
+			Step:          1,
+			Group:         "set",
+			Since:         "1.0.0",
+			Summary:       "Stores the union of multiple sets in a key.",
+			parse:         parseSUnionStoreCmd,
+		},
+		&CommandSpec{
+			Name:          "sdiff",
+			Arity:         -2,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_SET, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       -1,
+			Step:          1,
+			Group:         "set",
+			Since:         "1.0.0",
+			Summary:       "Returns the difference of multiple sets.",
+			parse:         parseSDiffCmd,
+		},
+		&CommandSpec{
+			Name:          "sdiffstore",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM},
+			ACLCategories: []string{ACL_WRITE, ACL_SET, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       -1,
+			Step:          1,
+			Group:         "set",
+			Since:         "1.0.0",
+			Summary:       "Stores the difference of multiple sets in a key.",
+			parse:         parseSDiffStoreCmd,
+		},
+		&CommandSpec{
+			Name:          "sscan",
+			Arity:         -3,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_SET, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "set",
+			Since:         "2.8.0",
+			Summary:       "Iterates over members of a set.",
+			parse:         parseSScanCmd,
+		},
+		&CommandSpec{
+			Name:          "zadd",
+			Arity:         -4,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_SORTEDSET, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "sorted-set",
+			Since:         "1.2.0",
+			Summary:       "Adds one or more members to a sorted set, or updates their scores. Creates the key if it doesn't exist.",
+			parse:         parseZAddCmd,
+		},
+		&CommandSpec{
+			Name:          "zincrby",
+			Arity:         4,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_SORTEDSET, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "sorted-set",
+			Since:         "1.2.0",
+			Summary:       "Increments the score of a member in a sorted set.",
+			parse:         parseZIncrByCmd,
+		},
+		&CommandSpec{
+			Name:          "zrem",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_SORTEDSET, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "sorted-set",
+			Since:         "1.2.0",
+			Summary:       "Removes one or more members from a sorted set. Deletes the sorted set if all members were removed.",
+			parse:         parseZRemCmd,
+		},
+		&CommandSpec{
+			Name:          "zscore",
+			Arity:         3,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_SORTEDSET, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "sorted-set",
+			Since:         "1.2.0",
+			Summary:       "Returns the score of a member in a sorted set.",
+			parse:         parseZScoreCmd,
+		},
+		&CommandSpec{
+			Name:          "zmscore",
+			Arity:         -3,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_SORTEDSET, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "sorted-set",
+			Since:         "6.2.0",
+			Summary:       "Returns the score of one or more members in a sorted set.",
+			parse:         parseZMScoreCmd,
+		},
+		&CommandSpec{
+			Name:          "zcard",
+			Arity:         2,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_SORTEDSET, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "sorted-set",
+			Since:         "1.2.0",
+			Summary:       "Returns the number of members in a sorted set.",
+			parse:         parseZCardCmd,
+		},
+		&CommandSpec{
+			Name:          "zcount",
+			Arity:         4,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_SORTEDSET, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "sorted-set",
+			Since:         "2.0.0",
+			Summary:       "Returns the count of members in a sorted set that have scores within a range.",
+			parse:         parseZCountCmd,
+		},
+		&CommandSpec{
+			Name:          "zlexcount",
+			Arity:         4,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_SORTEDSET, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "sorted-set",
+			Since:         "2.8.9",
+			Summary:       "Returns the number of members in a sorted set within a lexicographical range.",
+			parse:         parseZLexCountCmd,
+		},
+		&CommandSpec{
+			Name:          "zrank",
+			Arity:         -3,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_SORTEDSET, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "sorted-set",
+			Since:         "2.0.0",
+			Summary:       "Returns the index of a member in a sorted set ordered by ascending scores.",
+			parse:         parseZRankCmd,
+		},
+		&CommandSpec{
+			Name:          "zrevrank",
+			Arity:         -3,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_SORTEDSET, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "sorted-set",
+			Since:         "2.0.0",
+			Summary:       "Returns the index of a member in a sorted set ordered by descending scores.",
+			parse:         parseZRevRankCmd,
+		},
+		&CommandSpec{
+			Name:          "zrange",
+			Arity:         -4,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_SORTEDSET, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "sorted-set",
+			Since:         "1.2.0",
+			Summary:       "Returns members in a sorted set within a range of indexes.",
+			parse:         parseZRangeCmd,
+		},
+		&CommandSpec{
+			Name:          "zrevrange",
+			Arity:         -4,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_SORTEDSET, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "sorted-set",
+			Since:         "1.2.0",
+			Summary:       "Returns members in a sorted set within a range of indexes in reverse order.",
+			parse:         parseZRevRangeCmd,
+		},
+		&CommandSpec{
+			Name:          "zrangebyscore",
+			Arity:         -4,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_SORTEDSET, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "sorted-set",
+			Since:         "1.0.5",
+			Summary:       "Returns members in a sorted set within a range of scores.",
+			parse:         parseZRangeByScoreCmd,
+		},
+		&CommandSpec{
+			Name:          "zrevrangebyscore",
+			Arity:         -4,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_SORTEDSET, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "sorted-set",
+			Since:         "2.2.0",
+			Summary:       "Returns members in a sorted set within a range of scores in reverse order.",
+			parse:         parseZRevRangeByScoreCmd,
+		},
+		&CommandSpec{
+			Name:          "zrangebylex",
+			Arity:         -4,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_SORTEDSET, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "sorted-set",
+			Since:         "2.8.9",
+			Summary:       "Returns members in a sorted set within a lexicographical range.",
+			parse:         parseZRangeByLexCmd,
+		},
+		&CommandSpec{
+			Name:          "zrevrangebylex",
+			Arity:         -4,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_SORTEDSET, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "sorted-set",
+			Since:         "2.8.9",
+			Summary:       "Returns members in a sorted set within a lexicographical range in reverse order.",
+			parse:         parseZRevRangeByLexCmd,
+		},
+		&CommandSpec{
+			Name:          "zremrangebyrank",
+			Arity:         4,
+			Flags:         []string{FLAG_WRITE},
+			ACLCategories: []string{ACL_WRITE, ACL_SORTEDSET, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "sorted-set",
+			Since:         "2.0.0",
+			Summary:       "Removes members in a sorted set within a range of indexes. Deletes the sorted set if all members were removed.",
+			parse:         parseZRemRangeByRankCmd,
+		},
+		&CommandSpec{
+			Name:          "zremrangebyscore",
+			Arity:         4,
+			Flags:         []string{FLAG_WRITE},
+			ACLCategories: []string{ACL_WRITE, ACL_SORTEDSET, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "sorted-set",
+			Since:         "1.2.0",
+			Summary:       "Removes members in a sorted set within a range of scores. Deletes the sorted set if all members were removed.",
+			parse:         parseZRemRangeByScoreCmd,
+		},
+		&CommandSpec{
+			Name:          "zremrangebylex",
+			Arity:         4,
+			Flags:         []string{FLAG_WRITE},
+			ACLCategories: []string{ACL_WRITE, ACL_SORTEDSET, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "sorted-set",
+			Since:         "2.8.9",
+			Summary:       "Removes members in a sorted set within a lexicographical range. Deletes the sorted set if all members were removed.",
+			parse:         parseZRemRangeByLexCmd,
+		},
+		&CommandSpec{
+			Name:          "zscan",
+			Arity:         -3,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_SORTEDSET, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "sorted-set",
+			Since:         "2.8.0",
+			Summary:       "Iterates over members and scores of a sorted set.",
+			parse:         parseZScanCmd,
+		},
+		&CommandSpec{
+			Name:          "lpush",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_LIST, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "list",
+			Since:         "1.0.0",
+			Summary:       "Prepends one or more elements to a list. Creates the key if it doesn't exist.",
+			parse:         parseLPushCmd,
+		},
+		&CommandSpec{
+			Name:          "rpush",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_LIST, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "list",
+			Since:         "1.0.0",
+			Summary:       "Appends one or more elements to a list. Creates the key if it doesn't exist.",
+			parse:         parseRPushCmd,
+		},
+		&CommandSpec{
+			Name:          "lpushx",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_LIST, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "list",
+			Since:         "2.2.0",
+			Summary:       "Prepends one or more elements to a list only when the list exists.",
+			parse:         parseLPushXCmd,
+		},
+		&CommandSpec{
+			Name:          "rpushx",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_LIST, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "list",
+			Since:         "2.2.0",
+			Summary:       "Appends an element to a list only when the list exists.",
+			parse:         parseRPushXCmd,
+		},
+		&CommandSpec{
+			Name:          "lpop",
+			Arity:         -2,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_LIST, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "list",
+			Since:         "1.0.0",
+			Summary:       "Returns the first elements in a list after removing it. Deletes the list if the last element was popped.",
+			parse:         parseLPopCmd,
+		},
+		&CommandSpec{
+			Name:          "rpop",
+			Arity:         -2,
+			Flags:         []string{FLAG_WRITE, FLAG_FAST},
+			ACLCategories: []string{ACL_WRITE, ACL_LIST, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "list",
+			Since:         "1.0.0",
+			Summary:       "Returns and removes the last elements of the list. Deletes the list if the last element was popped.",
+			parse:         parseRPopCmd,
+		},
+		&CommandSpec{
+			Name:          "lrange",
+			Arity:         4,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_LIST, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "list",
+			Since:         "1.0.0",
+			Summary:       "Returns a range of elements from a list.",
+			parse:         parseLRangeCmd,
+		},
+		&CommandSpec{
+			Name:          "llen",
+			Arity:         2,
+			Flags:         []string{FLAG_READONLY, FLAG_FAST},
+			ACLCategories: []string{ACL_READ, ACL_LIST, ACL_FAST},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "list",
+			Since:         "1.0.0",
+			Summary:       "Returns the length of a list.",
+			parse:         parseLLenCmd,
+		},
+		&CommandSpec{
+			Name:          "lindex",
+			Arity:         3,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_LIST, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "list",
+			Since:         "1.0.0",
+			Summary:       "Returns an element from a list by its index.",
+			parse:         parseLIndexCmd,
+		},
+		&CommandSpec{
+			Name:          "lset",
+			Arity:         4,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM},
+			ACLCategories: []string{ACL_WRITE, ACL_LIST, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "list",
+			Since:         "1.0.0",
+			Summary:       "Sets the value of an element in a list by its index.",
+			parse:         parseLSetCmd,
+		},
+		&CommandSpec{
+			Name:          "linsert",
+			Arity:         5,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM},
+			ACLCategories: []string{ACL_WRITE, ACL_LIST, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "list",
+			Since:         "2.2.0",
+			Summary:       "Inserts an element before or after another element in a list.",
+			parse:         parseLInsertCmd,
+		},
+		&CommandSpec{
+			Name:          "lrem",
+			Arity:         4,
+			Flags:         []string{FLAG_WRITE},
+			ACLCategories: []string{ACL_WRITE, ACL_LIST, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "list",
+			Since:         "1.0.0",
+			Summary:       "Removes elements from a list. Deletes the list if the last element was removed.",
+			parse:         parseLRemCmd,
+		},
+		&CommandSpec{
+			Name:          "ltrim",
+			Arity:         4,
+			Flags:         []string{FLAG_WRITE},
+			ACLCategories: []string{ACL_WRITE, ACL_LIST, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "list",
+			Since:         "1.0.0",
+			Summary:       "Removes elements from both ends a list. Deletes the list if all elements were trimmed.",
+			parse:         parseLTrimCmd,
+		},
+		&CommandSpec{
+			Name:          "lpos",
+			Arity:         -3,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_READ, ACL_LIST, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       1,
+			Step:          1,
+			Group:         "list",
+			Since:         "6.0.6",
+			Summary:       "Returns the index of matching elements in a list.",
+			parse:         parseLPosCmd,
+		},
+		&CommandSpec{
+			Name:          "lmove",
+			Arity:         5,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM},
+			ACLCategories: []string{ACL_WRITE, ACL_LIST, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       2,
+			Step:          1,
+			Group:         "list",
+			Since:         "6.2.0",
+			Summary:       "Returns an element after popping it from one list and pushing it to another. Deletes the list if the last element was moved.",
+			parse:         parseLMoveCmd,
+		},
+		&CommandSpec{
+			Name:          "rpoplpush",
+			Arity:         3,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM},
+			ACLCategories: []string{ACL_WRITE, ACL_LIST, ACL_SLOW},
+			FirstKey:      1,
+			LastKey:       2,
+			Step:          1,
+			Group:         "list",
+			Since:         "1.2.0",
+			Summary:       "Returns the last element of a list after removing and pushing it to another list. Deletes the list if the last element was popped.",
+			parse:         parseRPopLPushCmd,
+		},
+		&CommandSpec{
+			Name:          "lmpop",
+			Arity:         -4,
+			Flags:         []string{FLAG_WRITE, FLAG_MOVABLEKEYS},
+			ACLCategories: []string{ACL_WRITE, ACL_LIST, ACL_SLOW},
+			Group:         "list",
+			Since:         "7.0.0",
+			Summary:       "Returns multiple elements from a list after removing them. Deletes the list if the last element was popped.",
+			parse:         parseLMPopCmd,
+			movableKeys:   mpopKeys(1),
+		},
+		&CommandSpec{
+			Name:          "blpop",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_BLOCKING},
+			ACLCategories: []string{ACL_WRITE, ACL_LIST, ACL_SLOW, ACL_BLOCKING},
+			FirstKey:      1,
+			LastKey:       -2,
+			Step:          1,
+			Group:         "list",
+			Since:         "2.0.0",
+			Summary:       "Removes and returns the first element in a list. Blocks until an element is available otherwise. Deletes the list if the last element was popped.",
+			parse:         parseBLPopCmd,
+		},
+		&CommandSpec{
+			Name:          "brpop",
+			Arity:         -3,
+			Flags:         []string{FLAG_WRITE, FLAG_BLOCKING},
+			ACLCategories: []string{ACL_WRITE, ACL_LIST, ACL_SLOW, ACL_BLOCKING},
+			FirstKey:      1,
+			LastKey:       -2,
+			Step:          1,
+			Group:         "list",
+			Since:         "2.0.0",
+			Summary:       "Removes and returns the last element in a list. Blocks until an element is available otherwise. Deletes the list if the last element was popped.",
+			parse:         parseBRPopCmd,
+		},
+		&CommandSpec{
+			Name:          "blmove",
+			Arity:         6,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_BLOCKING},
+			ACLCategories: []string{ACL_WRITE, ACL_LIST, ACL_SLOW, ACL_BLOCKING},
+			FirstKey:      1,
+			LastKey:       2,
+			Step:          1,
+			Group:         "list",
+			Since:         "6.2.0",
+			Summary:       "Pops an element from a list, pushes it to another list and returns it. Blocks until an element is available otherwise. Deletes the list if the last element was moved.",
+			parse:         parseBLMoveCmd,
+		},
+		&CommandSpec{
+			Name:          "brpoplpush",
+			Arity:         4,
+			Flags:         []string{FLAG_WRITE, FLAG_DENYOOM, FLAG_BLOCKING},
+			ACLCategories: []string{ACL_WRITE, ACL_LIST, ACL_SLOW, ACL_BLOCKING},
+			FirstKey:      1,
+			LastKey:       2,
+			Step:          1,
+			Group:         "list",
+			Since:         "2.2.0",
+			Summary:       "Pops an element from a list, pushes it to another list and returns it. Block until an element is available otherwise. Deletes the list if the last element was popped.",
+			parse:         parseBRPopLPushCmd,
+		},
+		&CommandSpec{
+			Name:          "blmpop",
+			Arity:         -5,
+			Flags:         []string{FLAG_WRITE, FLAG_BLOCKING, FLAG_MOVABLEKEYS},
+			ACLCategories: []string{ACL_WRITE, ACL_LIST, ACL_SLOW, ACL_BLOCKING},
+			Group:         "list",
+			Since:         "7.0.0",
+			Summary:       "Pops the first element from one of multiple lists. Blocks until an element is available otherwise. Deletes the list if the last element was popped.",
+			parse:         parseBLMPopCmd,
+			movableKeys:   mpopKeys(2),
+		},
+		&CommandSpec{
+			Name:          "keys",
+			Arity:         2,
+			Flags:         []string{FLAG_READONLY},
+			ACLCategories: []string{ACL_KEYSPACE, ACL_READ, ACL_SLOW, ACL_DANGEROUS},
+			Group:         "generic",
+			Since:         "1.0.0",
+			Summary:       "Returns all key names that match a pattern.",
+			parse:         parseKeysCmd,
+		},
+		&CommandSpec{
+			Name:          "save",
+			Arity:         1,
+			Flags:         []string{FLAG_ADMIN, FLAG_NOSCRIPT},
+			ACLCategories: []string{ACL_ADMIN, ACL_SLOW, ACL_DANGEROUS},
+			Group:         "server",
+			Since:         "1.0.0",
+			Summary:       "Synchronously saves the database(s) to disk.",
+			parse:         parseSaveCmd,
+		},
+		&CommandSpec{
+			Name:          "info",
+			Arity:         -1,
+			Flags:         []string{FLAG_LOADING, FLAG_STALE},
+			ACLCategories: []string{ACL_SLOW, ACL_DANGEROUS},
+			Group:         "server",
+			Since:         "1.0.0",
+			Summary:       "Returns information and statistics about the server.",
+			parse:         parseInfoCmd,
+		},
+		&CommandSpec{
+			Name:          "config",
+			Arity:         -2,
+			ACLCategories: []string{ACL_SLOW},
+			Group:         "server",
+			Since:         "2.0.0",
+			Summary:       "A container for server configuration commands.",
+			Subcommands: []*CommandSpec{
+				{
+					Name:          "get",
+					Arity:         -3,
+					Flags:         []string{FLAG_ADMIN, FLAG_NOSCRIPT, FLAG_LOADING, FLAG_STALE},
+					ACLCategories: []string{ACL_ADMIN, ACL_SLOW, ACL_DANGEROUS},
+					Group:         "server",
+					Since:         "2.0.0",
+					Summary:       "Returns the effective values of configuration parameters.",
+					parse:         parseConfigGetCmd,
+				},
+			},
+		},
+		&CommandSpec{
+			Name:          "command",
+			Arity:         -1,
+			Flags:         []string{FLAG_LOADING, FLAG_STALE},
+			ACLCategories: []string{ACL_SLOW, ACL_CONNECTION},
+			Group:         "server",
+			Since:         "2.8.13",
+			Summary:       "Returns detailed information about all commands.",
+			parse:         parseCommandCmd,
+			Subcommands: []*CommandSpec{
+				{
+					Name:          "count",
+					Arity:         2,
+					Flags:         []string{FLAG_LOADING, FLAG_STALE},
+					ACLCategories: []string{ACL_SLOW, ACL_CONNECTION},
+					Group:         "server",
+					Since:         "2.8.13",
+					Summary:       "Returns a count of commands.",
+					parse:         parseCommandCountCmd,
+				},
+				{
+					Name:          "docs",
+					Arity:         -2,
+					Flags:         []string{FLAG_LOADING, FLAG_STALE},
+					ACLCategories: []string{ACL_SLOW, ACL_CONNECTION},
+					Group:         "server",
+					Since:         "7.0.0",
+					Summary:       "Returns documentary information about one, multiple or all commands.",
+					parse:         parseCommandDocsCmd,
+				},
+				{
+					Name:          "getkeys",
+					Arity:         -3,
+					Flags:         []string{FLAG_LOADING, FLAG_STALE},
+					ACLCategories: []string{ACL_SLOW, ACL_CONNECTION},
+					Group:         "server",
+					Since:         "2.8.13",
+					Summary:       "Extracts the key names from an arbitrary command.",
+					parse:         parseCommandGetKeysCmd,
+				},
+				{
+					Name:          "info",
+					Arity:         -2,
+					Flags:         []string{FLAG_LOADING, FLAG_STALE},
+					ACLCategories: []string{ACL_SLOW, ACL_CONNECTION},
+					Group:         "server",
+					Since:         "2.8.13",
+					Summary:       "Returns information about one, multiple or all commands.",
+					parse:         parseCommandInfoCmd,
+				},
+				{
+					Name:          "list",
+					Arity:         -2,
+					Flags:         []string{FLAG_LOADING, FLAG_STALE},
+					ACLCategories: []string{ACL_SLOW, ACL_CONNECTION},
+					Group:         "server",
+					Since:         "7.0.0",
+					Summary:       "Returns a list of command names.",
+					parse:         parseCommandListCmd,
+				},
+			},
+		},
+		&CommandSpec{
+			Name:          "client",
+			Arity:         -2,
+			ACLCategories: []string{ACL_SLOW},
+			Group:         "connection",
+			Since:         "2.4.0",
+			Summary:       "A container for client connection commands.",
+			Subcommands: []*CommandSpec{
+				{
+					Name:          "caching",
+					Arity:         3,
+					Flags:         []string{FLAG_NOSCRIPT, FLAG_LOADING, FLAG_STALE},
+					ACLCategories: []string{ACL_SLOW, ACL_CONNECTION},
+					Group:         "connection",
+					Since:         "6.0.0",
+					Summary:       "Instructs the server whether to track the keys in the next request.",
+					parse:         parseClientCachingCmd,
+				},
+				{
+					Name:          "getredir",
+					Arity:         2,
+					Flags:         []string{FLAG_NOSCRIPT, FLAG_LOADING, FLAG_STALE},
+					ACLCategories: []string{ACL_SLOW, ACL_CONNECTION},
+					Group:         "connection",
+					Since:         "6.0.0",
+					Summary:       "Returns the client ID to which the connection's tracking notifications are redirected.",
+					parse:         parseClientGetRedirCmd,
+				},
+				{
+					Name:          "id",
+					Arity:         2,
+					Flags:         []string{FLAG_NOSCRIPT, FLAG_LOADING, FLAG_STALE},
+					ACLCategories: []string{ACL_SLOW, ACL_CONNECTION},
+					Group:         "connection",
+					Since:         "5.0.0",
+					Summary:       "Returns the unique client ID of the connection.",
+					parse:         parseClientIdCmd,
+				},
+				{
+					Name:          "tracking",
+					Arity:         -3,
+					Flags:         []string{FLAG_NOSCRIPT, FLAG_LOADING, FLAG_STALE},
+					ACLCategories: []string{ACL_SLOW, ACL_CONNECTION},
+					Group:         "connection",
+					Since:         "6.0.0",
+					Summary:       "Controls server-assisted client-side caching for the connection.",
+					parse:         parseClientTrackingCmd,
+				},
+				{
+					Name:          "trackinginfo",
+					Arity:         2,
+					Flags:         []string{FLAG_NOSCRIPT, FLAG_LOADING, FLAG_STALE},
+					ACLCategories: []string{ACL_SLOW, ACL_CONNECTION},
+					Group:         "connection",
+					Since:         "6.2.0",
+					Summary:       "Returns information about server-assisted client-side caching for the connection.",
+					parse:         parseClientTrackingInfoCmd,
+				},
+			},
+		},
+		&CommandSpec{
+			Name:          "subscribe",
+			Arity:         -2,
+			Flags:         []string{FLAG_PUBSUB, FLAG_NOSCRIPT, FLAG_LOADING, FLAG_STALE},
+			ACLCategories: []string{ACL_PUBSUB, ACL_SLOW},
+			Group:         "pubsub",
+			Since:         "2.0.0",
+			Summary:       "Listens for messages published to channels.",
+			parse:         parseSubscribeCmd,
+		},
+		&CommandSpec{
+			Name:          "unsubscribe",
+			Arity:         -1,
+			Flags:         []string{FLAG_PUBSUB, FLAG_NOSCRIPT, FLAG_LOADING, FLAG_STALE},
+			ACLCategories: []string{ACL_PUBSUB, ACL_SLOW},
+			Group:         "pubsub",
+			Since:         "2.0.0",
+			Summary:       "Stops listening to messages posted to channels.",
+			parse:         parseUnsubscribeCmd,
+		},
+		&CommandSpec{
+			Name:          "publish",
+			Arity:         3,
+			Flags:         []string{FLAG_PUBSUB, FLAG_LOADING, FLAG_STALE, FLAG_FAST},
+			ACLCategories: []string{ACL_PUBSUB, ACL_FAST},
+			Group:         "pubsub",
+			Since:         "2.0.0",
+			Summary:       "Posts a message to a channel.",
+			parse:         parsePublishCmd,
+		},
+	)
+}
@@ -0,0 +1,223 @@
+package parser
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/JanitSri/codecrafters-build-your-own-redis/customerror"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/data"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/util"
+)
+
+// ReplyWriter encodes replies in the protocol negotiated by the client,
+// RESP3 only types fall back to their closest RESP2 representation
+//
+// https://redis.io/docs/latest/develop/reference/protocol-spec/
+type ReplyWriter struct {
+	buf   bytes.Buffer
+	proto int
+}
+
+func NewReplyWriter(proto int) *ReplyWriter {
+	return &ReplyWriter{
+		proto: proto,
+	}
+}
+
+func (w *ReplyWriter) Protocol() int {
+	return w.proto
+}
+
+func (w *ReplyWriter) Bytes() []byte {
+	return w.buf.Bytes()
+}
+
+func (w *ReplyWriter) Len() int {
+	return w.buf.Len()
+}
+
+// Append copies the replies written to o, used when the length of an
+// aggregate is only known after its elements have been written
+func (w *ReplyWriter) Append(o *ReplyWriter) {
+	w.buf.Write(o.Bytes())
+}
+
+func (w *ReplyWriter) header(t string, n int) {
+	w.buf.WriteString(t)
+	w.buf.WriteString(strconv.Itoa(n))
+	w.buf.WriteString(REDIS_TERMINATOR)
+}
+
+func (w *ReplyWriter) SimpleString(s string) {
+	w.buf.WriteString(SIMPLE_STRING)
+	w.buf.WriteString(s)
+	w.buf.WriteString(REDIS_TERMINATOR)
+}
+
+func (w *ReplyWriter) OK() {
+	w.buf.WriteString(OK)
+}
+
+// Error writes the error prefixed with its Redis error code, newlines are
+// not allowed in simple errors so they are replaced by spaces
+func (w *ReplyWriter) Error(err error) {
+	em := strings.NewReplacer("\r", " ", "\n", " ").Replace(err.Error())
+
+	w.buf.WriteString(SIMPLE_ERROR)
+	w.buf.WriteString(customerror.Code(err))
+	w.buf.WriteString(" ")
+	w.buf.WriteString(em)
+	w.buf.WriteString(REDIS_TERMINATOR)
+}
+
+func (w *ReplyWriter) BulkError(err error) {
+	if w.proto != data.RESP3 {
+		w.Error(err)
+		return
+	}
+
+	em := customerror.Code(err) + " " + err.Error()
+	w.header(BULK_ERROR, len(em))
+	w.buf.WriteString(em)
+	w.buf.WriteString(REDIS_TERMINATOR)
+}
+
+func (w *ReplyWriter) Integer(n int64) {
+	w.buf.WriteString(INTEGER)
+	w.buf.WriteString(strconv.FormatInt(n, 10))
+	w.buf.WriteString(REDIS_TERMINATOR)
+}
+
+func (w *ReplyWriter) BulkString(b []byte) {
+	w.header(BULK_STRING, len(b))
+	w.buf.Write(b)
+	w.buf.WriteString(REDIS_TERMINATOR)
+}
+
+func (w *ReplyWriter) BulkStringString(s string) {
+	w.header(BULK_STRING, len(s))
+	w.buf.WriteString(s)
+	w.buf.WriteString(REDIS_TERMINATOR)
+}
+
+func (w *ReplyWriter) Null() {
+	if w.proto == data.RESP3 {
+		w.buf.WriteString(RESP3_NULL)
+		return
+	}
+
+	w.buf.WriteString(NULL_BULK_STRING)
+}
+
+func (w *ReplyWriter) NullArray() {
+	if w.proto == data.RESP3 {
+		w.buf.WriteString(RESP3_NULL)
+		return
+	}
+
+	w.buf.WriteString(NULL_ARRAY)
+}
+
+func (w *ReplyWriter) ArrayLen(n int) {
+	w.header(ARRAY, n)
+}
+
+// MapLen writes the header for n key/value pairs, RESP2 has no map type so
+// the pairs are flattened into an array of 2*n elements
+func (w *ReplyWriter) MapLen(n int) {
+	if w.proto == data.RESP3 {
+		w.header(MAPS, n)
+		return
+	}
+
+	w.header(ARRAY, n*2)
+}
+
+func (w *ReplyWriter) SetLen(n int) {
+	if w.proto == data.RESP3 {
+		w.header(SETS, n)
+		return
+	}
+
+	w.header(ARRAY, n)
+}
+
+// AttributeLen writes the header of n attribute pairs that annotate the
+// reply that follows, RESP2 clients have no way to skip them so nothing is
+// written and the caller must not write the pairs either
+func (w *ReplyWriter) AttributeLen(n int) bool {
+	if w.proto != data.RESP3 {
+		return false
+	}
+
+	w.header(ATTRIBUTES, n)
+	return true
+}
+
+// PushLen writes the header of an out of band message, RESP2 clients
+// receive them as a plain array the way pub/sub messages are delivered
+func (w *ReplyWriter) PushLen(n int) {
+	if w.proto == data.RESP3 {
+		w.header(PUSH, n)
+		return
+	}
+
+	w.header(ARRAY, n)
+}
+
+func (w *ReplyWriter) Double(f float64) {
+	if w.proto == data.RESP3 {
+		w.buf.WriteString(DOUBLE)
+		w.buf.WriteString(util.FormatDouble(f))
+		w.buf.WriteString(REDIS_TERMINATOR)
+		return
+	}
+
+	w.BulkStringString(util.FormatDouble(f))
+}
+
+func (w *ReplyWriter) Boolean(b bool) {
+	if w.proto == data.RESP3 {
+		if b {
+			w.buf.WriteString(BOOLEAN + "t" + REDIS_TERMINATOR)
+		} else {
+			w.buf.WriteString(BOOLEAN + "f" + REDIS_TERMINATOR)
+		}
+		return
+	}
+
+	if b {
+		w.Integer(1)
+	} else {
+		w.Integer(0)
+	}
+}
+
+// BigNumber writes an integer that does not fit in 64 bits, s must be a
+// valid base 10 number
+func (w *ReplyWriter) BigNumber(s string) {
+	if w.proto == data.RESP3 {
+		w.buf.WriteString(BIG_NUMBER)
+		w.buf.WriteString(s)
+		w.buf.WriteString(REDIS_TERMINATOR)
+		return
+	}
+
+	w.BulkStringString(s)
+}
+
+// VerbatimString writes text with a three letter format hint, such as txt
+// or mkd, RESP2 clients get the text as a regular bulk string
+func (w *ReplyWriter) VerbatimString(format string, b []byte) {
+	if w.proto != data.RESP3 {
+		w.BulkString(b)
+		return
+	}
+
+	w.header(VERBATIM_STRING, len(format)+1+len(b))
+	w.buf.WriteString(format)
+	w.buf.WriteString(":")
+	w.buf.Write(b)
+	w.buf.WriteString(REDIS_TERMINATOR)
+}
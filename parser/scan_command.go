@@ -0,0 +1,259 @@
+package parser
+
+import (
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/JanitSri/codecrafters-build-your-own-redis/customerror"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/data"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/util"
+)
+
+// how many elements a scan call tries to return when COUNT is not given
+const DEFAULT_SCAN_COUNT = 10
+
+// the type names SCAN accepts for its TYPE option
+var scanTypes = map[string]bool{
+	data.TYPE_STRING: true,
+	data.TYPE_LIST:   true,
+	data.TYPE_HASH:   true,
+	data.TYPE_SET:    true,
+	data.TYPE_ZSET:   true,
+	data.TYPE_STREAM: true,
+}
+
+func parseScanCmd(args [][]byte) Command {
+	flags, err := parseScanOptions(args[1], args[2:], TYPE)
+	if err != nil {
+		return NewErrorCommand(err)
+	}
+
+	return NewScanCommand([][]byte{args[1]}, flags)
+}
+
+func parseHScanCmd(args [][]byte) Command {
+	return parseCollectionScanCmd(args, data.TYPE_HASH, NOVALUES)
+}
+
+func parseSScanCmd(args [][]byte) Command {
+	return parseCollectionScanCmd(args, data.TYPE_SET, "")
+}
+
+func parseZScanCmd(args [][]byte) Command {
+	return parseCollectionScanCmd(args, data.TYPE_ZSET, NOSCORES)
+}
+
+func parseCollectionScanCmd(args [][]byte, typ, extra string) Command {
+	flags, err := parseScanOptions(args[2], args[3:], extra)
+	if err != nil {
+		return NewErrorCommand(err)
+	}
+
+	return NewCollectionScanCommand(typ, [][]byte{args[1], args[2]}, flags)
+}
+
+// parseScanOptions validates the cursor and the MATCH and COUNT options
+// shared by every scan command, extra is the one option only some of them
+// accept
+//
+// https://redis.io/docs/latest/commands/scan/
+func parseScanOptions(cursor []byte, args [][]byte, extra string) ([]*Flag, error) {
+	if _, err := strconv.ParseUint(string(cursor), 10, 64); err != nil {
+		return nil, customerror.InvalidCursorError{}
+	}
+
+	flags := []*Flag{}
+	for i := 0; i < len(args); i++ {
+		f := strings.ToUpper(string(args[i]))
+		switch {
+		case f == MATCH || f == COUNT || (f == TYPE && extra == TYPE):
+			if i+1 >= len(args) {
+				return nil, customerror.SyntaxError{}
+			}
+			v := string(args[i+1])
+			i++
+
+			if f == COUNT {
+				n, err := strconv.Atoi(v)
+				if err != nil {
+					return nil, customerror.NotIntegerError{}
+				}
+				if n < 1 {
+					return nil, customerror.SyntaxError{}
+				}
+			}
+
+			if f == TYPE && !scanTypes[strings.ToLower(v)] {
+				return nil, customerror.UnknownTypeNameError{Name: v}
+			}
+
+			flags = append(flags, NewFlag(f, v))
+		case f == extra && extra != TYPE:
+			flags = append(flags, NewFlag(f, ""))
+		default:
+			return nil, customerror.SyntaxError{}
+		}
+	}
+
+	return flags, nil
+}
+
+// scanOptions reads the flags back, a repeated option overrides the
+// previous one
+func scanOptions(flags []*Flag) (match []byte, count int, typ string, noValues bool) {
+	count = DEFAULT_SCAN_COUNT
+	for _, f := range flags {
+		switch f.name {
+		case MATCH:
+			match = []byte(f.value)
+		case COUNT:
+			count, _ = strconv.Atoi(f.value)
+		case TYPE:
+			typ = strings.ToLower(f.value)
+		case NOVALUES, NOSCORES:
+			noValues = true
+		}
+	}
+
+	// matching everything is the same as not matching
+	if string(match) == "*" {
+		match = nil
+	}
+
+	return match, count, typ, noValues
+}
+
+// scanSteps is how many steps a scan call may take to find count elements,
+// ten times the count saturating so a huge COUNT does not wrap around
+func scanSteps(count int) int {
+	if count > math.MaxInt/10 {
+		return math.MaxInt
+	}
+
+	return count * 10
+}
+
+// writeScanReply writes the cursor to continue from followed by the
+// elements found
+func writeScanReply(w *ReplyWriter, cursor uint64, elems [][]byte) {
+	w.ArrayLen(2)
+	w.BulkStringString(strconv.FormatUint(cursor, 10))
+	w.ArrayLen(len(elems))
+	for _, e := range elems {
+		w.BulkString(e)
+	}
+}
+
+type ScanCommand struct {
+	BaseCommand
+}
+
+func NewScanCommand(args [][]byte, flags []*Flag) *ScanCommand {
+	return &ScanCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (sc *ScanCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("scanning...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	cursor, _ := strconv.ParseUint(string(sc.args[0]), 10, 64)
+	match, count, typ, _ := scanOptions(sc.flags)
+
+	// COUNT is only a hint, empty buckets are skipped up to ten times the
+	// count so a sparse keyspace still returns something
+	var keys [][]byte
+	for i, steps := 0, scanSteps(count); i < steps; i++ {
+		ks, next := rc.DataStore.Scan(cursor)
+		keys = append(keys, ks...)
+		cursor = next
+
+		if cursor == 0 || len(keys) >= count {
+			break
+		}
+	}
+
+	// the filters only apply once the step is taken, so a call may return
+	// no keys even though the iteration is not complete
+	found := keys[:0]
+	for _, k := range keys {
+		if match != nil && !util.GlobMatch(match, k, false) {
+			continue
+		}
+
+		v, ok := lookupKey(rc, k)
+		if !ok || (typ != "" && v.Type() != typ) {
+			continue
+		}
+
+		found = append(found, k)
+	}
+
+	writeScanReply(w, cursor, found)
+	return w.Bytes()
+}
+
+// CollectionScanCommand implements HSCAN, SSCAN and ZSCAN which only
+// differ by the type of the key they iterate
+type CollectionScanCommand struct {
+	BaseCommand
+	typ string
+}
+
+func NewCollectionScanCommand(typ string, args [][]byte, flags []*Flag) *CollectionScanCommand {
+	return &CollectionScanCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+		typ,
+	}
+}
+
+func (cc *CollectionScanCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("scanning collection...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	cursor, _ := strconv.ParseUint(string(cc.args[1]), 10, 64)
+	match, count, _, noValues := scanOptions(cc.flags)
+
+	v, ok := lookupKey(rc, cc.args[0])
+	if !ok {
+		writeScanReply(w, 0, nil)
+		return w.Bytes()
+	}
+
+	s, isScanner := v.Value().(data.Scanner)
+	if v.Type() != cc.typ || !isScanner {
+		w.Error(customerror.WrongTypeError{})
+		return w.Bytes()
+	}
+
+	var elems [][]byte
+	for i, n, steps := 0, 0, scanSteps(count); i < steps; i++ {
+		cursor = s.Scan(cursor, func(elem, val []byte) {
+			n++
+			if match != nil && !util.GlobMatch(match, elem, false) {
+				return
+			}
+
+			elems = append(elems, elem)
+			if val != nil && !noValues {
+				elems = append(elems, val)
+			}
+		})
+
+		if cursor == 0 || n >= count {
+			break
+		}
+	}
+
+	writeScanReply(w, cursor, elems)
+	return w.Bytes()
+}
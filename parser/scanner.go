@@ -2,276 +2,578 @@ package parser
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
 	"io"
 	"log"
+	"math"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/JanitSri/codecrafters-build-your-own-redis/customerror"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/data"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/util"
+)
+
+const (
+	// longest inline request or multibulk/bulk length line accepted, the
+	// same as PROTO_INLINE_MAX_SIZE in Redis
+	maxInlineSize = 64 * 1024
+
+	// bulk strings above this size grow as their payload arrives, so a
+	// bogus length does not allocate memory up front
+	maxBulkPrealloc = 1024 * 1024
 )
 
 type RedisScanner struct {
-	scanner bufio.Scanner
-	cmdCh   chan<- Command
+	reader *bufio.Reader
+	cmdCh  chan<- Command
+	config *data.RedisConfig
+
+	// bytes of the request being read, checked against
+	// client-query-buffer-limit
+	queryLen int64
 }
 
-func NewRedisScanner(rw io.ReadWriter, cmdCh chan<- Command) *RedisScanner {
+func NewRedisScanner(rw io.ReadWriter, cmdCh chan<- Command, config *data.RedisConfig) *RedisScanner {
 	return &RedisScanner{
-		scanner: *bufio.NewScanner(rw),
-		cmdCh:   cmdCh,
+		reader: bufio.NewReaderSize(rw, 16*1024),
+		cmdCh:  cmdCh,
+		config: config,
 	}
 }
 
 func (rs *RedisScanner) Scan() {
-	for rs.scanner.Scan() {
-		t := rs.scanner.Text()
-		cmd := rs.parseCmd(t, 0)
-		if cmd != nil {
-			rs.cmdCh <- cmd
+	for {
+		args, err := rs.readRequest()
+		if err != nil {
+			var pe protocolError
+			switch {
+			case errors.As(err, &pe):
+				// the rest of the stream can't be trusted once a request is
+				// malformed, the client gets the error and is disconnected
+				rs.cmdCh <- NewErrorCommand(pe.err)
+			case errors.Is(err, errQueryBufferLimit):
+				log.Println("closing client that reached max query buffer length")
+			case !errors.Is(err, io.EOF):
+				log.Printf("error reading request: %v\n", err)
+			}
+			break
+		}
+
+		if len(args) == 0 {
+			continue
 		}
+
+		rs.cmdCh <- rs.handleCommand(args)
 	}
 	close(rs.cmdCh)
 }
 
-func (rs *RedisScanner) parseCmd(s string, np int) Command {
-	rt := string(s[0])
+// protocolError wraps a malformed request so Scan can tell it apart from
+// an I/O error on the underlying connection
+type protocolError struct {
+	err error
+}
 
-	var cmd Command
-	switch rt {
-	case ARRAY:
-		cmd = rs.handleArrays(s)
-	case BULK_STRING:
-		cmd = rs.handleBulkString(s, np)
-	default:
-		cmd = rs.handleCommand(s, np)
-	}
+func (e protocolError) Error() string {
+	return e.err.Error()
+}
 
-	return cmd
+func newProtocolError(reason string) protocolError {
+	return protocolError{customerror.ProtocolError{Reason: reason}}
 }
 
-func (rs *RedisScanner) handleArrays(s string) Command {
-	if len(s) < 2 {
-		return NewErrorCommand(customerror.InvalidCharacterError{})
-	}
+var errQueryBufferLimit = errors.New("query buffer limit reached")
 
-	n, err := strconv.Atoi(string(s[1:]))
+func (rs *RedisScanner) readRequest() ([][]byte, error) {
+	rs.queryLen = 0
+
+	b, err := rs.reader.Peek(1)
 	if err != nil {
-		return NewErrorCommand(customerror.InvalidCharacterError{})
+		return nil, err
 	}
 
-	if !rs.scanner.Scan() {
-		return NewErrorCommand(customerror.InvalidRedisCommandError{})
+	if string(b) == ARRAY {
+		return rs.readArray()
 	}
 
-	t := rs.scanner.Text()
-	return rs.parseCmd(t, n)
+	return rs.readInline()
 }
 
-func (rs *RedisScanner) handleBulkString(s string, np int) Command {
-	if len(s) < 2 {
-		return NewErrorCommand(customerror.InvalidCharacterError{})
-	}
+// readLine reads up to and including the next CRLF and returns the line
+// without the terminator, tooBig is the protocol error reported for lines
+// longer than maxInlineSize
+func (rs *RedisScanner) readLine(tooBig string) ([]byte, error) {
+	var l []byte
+	for {
+		s, err := rs.reader.ReadSlice('\n')
+		l = append(l, s...)
+		if len(l) > maxInlineSize {
+			return nil, newProtocolError(tooBig)
+		}
 
-	n, err := strconv.Atoi(string(s[1:]))
-	if err != nil {
-		return NewErrorCommand(customerror.InvalidCharacterError{})
-	}
-	if n == 0 {
-		log.Println("empty string")
-		return nil
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return nil, err
+		}
 	}
 
-	if !rs.scanner.Scan() {
-		return NewErrorCommand(customerror.InvalidRedisCommandError{})
-	}
+	rs.queryLen += int64(len(l))
 
-	t := rs.scanner.Text()
-	return rs.parseCmd(t, np-1)
+	l = bytes.TrimSuffix(l, []byte("\n"))
+	l = bytes.TrimSuffix(l, []byte("\r"))
+	return l, nil
 }
 
-func (rs *RedisScanner) handleCommand(cmdString string, np int) Command {
-	var cmd Command
+// readInline reads a request sent as a single line of space separated
+// arguments, as typed into telnet or netcat
+func (rs *RedisScanner) readInline() ([][]byte, error) {
+	l, err := rs.readLine("too big inline request")
+	if err != nil {
+		return nil, err
+	}
 
-	switch strings.ToUpper(cmdString) {
-	case PING:
-		cmd = rs.parsePingCmd()
-	case ECHO:
-		cmd = rs.parseEchoCmd(np)
-	case SET:
-		cmd = rs.parseSetCmd(np)
-	case GET:
-		cmd = rs.parseGetCmd(np)
-	case CONFIG:
-		cmd = rs.parseConfigCmd(np)
-	case KEYS:
-		cmd = rs.parseKeysCmd(np)
-	case INFO:
-		cmd = rs.parseInfoCmd(np)
-	default:
-		return NewErrorCommand(customerror.InvalidRedisCommandError{})
+	args, ok := splitArgs(l)
+	if !ok {
+		return nil, newProtocolError("unbalanced quotes in request")
 	}
 
-	return cmd
+	return args, nil
 }
 
-func (rs *RedisScanner) skipLen() error {
+// splitArgs splits an inline request the same way redis-cli does, arguments
+// may be wrapped in double quotes, which support the \n \r \t \b \a and
+// \xHH escapes, or in single quotes, which only support \'
+func splitArgs(l []byte) ([][]byte, bool) {
+	args := [][]byte{}
+
 	i := 0
-	for i < 2 && rs.scanner.Scan() {
-		i++
+	for {
+		for i < len(l) && isSpace(l[i]) {
+			i++
+		}
+		if i == len(l) {
+			return args, true
+		}
+
+		inDq, inSq := false, false
+		cur := []byte{}
+		for done := false; !done; {
+			if inDq {
+				if i == len(l) {
+					return nil, false
+				}
+
+				switch {
+				case l[i] == '\\' && i+3 < len(l) && l[i+1] == 'x' && isHexDigit(l[i+2]) && isHexDigit(l[i+3]):
+					cur = append(cur, hexDigitToInt(l[i+2])*16+hexDigitToInt(l[i+3]))
+					i += 3
+				case l[i] == '\\' && i+1 < len(l):
+					i++
+					switch l[i] {
+					case 'n':
+						cur = append(cur, '\n')
+					case 'r':
+						cur = append(cur, '\r')
+					case 't':
+						cur = append(cur, '\t')
+					case 'b':
+						cur = append(cur, '\b')
+					case 'a':
+						cur = append(cur, '\a')
+					default:
+						cur = append(cur, l[i])
+					}
+				case l[i] == '"':
+					// the closing quote must be followed by a space or
+					// nothing at all
+					if i+1 < len(l) && !isSpace(l[i+1]) {
+						return nil, false
+					}
+					done = true
+				default:
+					cur = append(cur, l[i])
+				}
+			} else if inSq {
+				if i == len(l) {
+					return nil, false
+				}
+
+				switch {
+				case l[i] == '\\' && i+1 < len(l) && l[i+1] == '\'':
+					i++
+					cur = append(cur, '\'')
+				case l[i] == '\'':
+					if i+1 < len(l) && !isSpace(l[i+1]) {
+						return nil, false
+					}
+					done = true
+				default:
+					cur = append(cur, l[i])
+				}
+			} else {
+				if i == len(l) {
+					break
+				}
+
+				switch l[i] {
+				case ' ', '\n', '\r', '\t', '\v', '\f':
+					done = true
+				case '"':
+					inDq = true
+				case '\'':
+					inSq = true
+				default:
+					cur = append(cur, l[i])
+				}
+			}
+
+			if i < len(l) {
+				i++
+			}
+		}
+
+		args = append(args, cur)
 	}
-	if i != 2 {
-		return customerror.InvalidNumberOfArgumentsError{}
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case ' ', '\n', '\r', '\t', '\v', '\f':
+		return true
 	}
 
-	return nil
+	return false
 }
 
-func (rs *RedisScanner) parsePingCmd() Command {
-	return NewPingCommand()
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
 }
 
-func (rs *RedisScanner) parseEchoCmd(np int) Command {
-	if np < 1 {
-		return NewErrorCommand(customerror.InvalidNumberOfArgumentsError{})
+func hexDigitToInt(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
 	}
+}
 
-	err := rs.skipLen()
+func (rs *RedisScanner) readArray() ([][]byte, error) {
+	l, err := rs.readLine("too big mbulk count string")
 	if err != nil {
-		return NewErrorCommand(err)
+		return nil, err
 	}
-	s := rs.scanner.Text()
 
-	return NewEchoCommand([]string{s})
-}
+	n, err := strconv.ParseInt(string(l[1:]), 10, 64)
+	if err != nil || n > rs.config.ProtoMaxMultibulkLen() {
+		return nil, newProtocolError("invalid multibulk length")
+	}
+
+	if n <= 0 {
+		return nil, nil
+	}
 
-func (rs *RedisScanner) parseSetCmd(np int) Command {
-	if np < 2 {
-		return NewErrorCommand(customerror.InvalidNumberOfArgumentsError{})
+	args := make([][]byte, 0, min(n, 1024))
+	for range n {
+		a, err := rs.readBulkString()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, a)
 	}
 
-	err := rs.skipLen()
+	return args, nil
+}
+
+// readBulkString reads a `$<len>` header followed by exactly len bytes of
+// payload, the payload is never split on line terminators
+func (rs *RedisScanner) readBulkString() ([]byte, error) {
+	l, err := rs.readLine("too big bulk count string")
 	if err != nil {
-		return NewErrorCommand(err)
+		return nil, err
+	}
+
+	if len(l) == 0 || string(l[0]) != BULK_STRING {
+		var got string
+		if len(l) > 0 {
+			got = string(l[0])
+		}
+		return nil, newProtocolError(fmt.Sprintf("expected '$', got '%s'", got))
+	}
+
+	n, err := strconv.ParseInt(string(l[1:]), 10, 64)
+	if err != nil || n < 0 || n > rs.config.ProtoMaxBulkLen() {
+		return nil, newProtocolError("invalid bulk length")
 	}
-	k := rs.scanner.Text()
-	np -= 1
 
-	err = rs.skipLen()
+	rs.queryLen += n
+	if rs.queryLen > rs.config.ClientQueryBufferLimit() {
+		return nil, errQueryBufferLimit
+	}
+
+	b, err := rs.readN(int(n) + len(REDIS_TERMINATOR))
 	if err != nil {
-		return NewErrorCommand(err)
+		return nil, err
 	}
-	v := rs.scanner.Text()
-	np -= 1
 
-	args := []string{k, v}
-	flags := []*Flag{}
+	if string(b[n:]) != REDIS_TERMINATOR {
+		return nil, newProtocolError("invalid bulk terminator")
+	}
 
-	for i := np; i > 0; i-- {
-		err := rs.skipLen()
-		if err != nil {
-			return NewErrorCommand(err)
+	return b[:n], nil
+}
+
+// readN reads exactly n bytes, memory is only committed as the bytes arrive
+func (rs *RedisScanner) readN(n int) ([]byte, error) {
+	b := make([]byte, 0, min(n, maxBulkPrealloc))
+	for len(b) < n {
+		if len(b) == cap(b) {
+			b = slices.Grow(b, min(n-len(b), cap(b)))
 		}
-		f := rs.scanner.Text()
-		switch strings.ToUpper(f) {
-		case PX:
-			err := rs.skipLen()
-			if err != nil {
-				return NewErrorCommand(err)
+
+		m, err := rs.reader.Read(b[len(b):min(cap(b), n)])
+		b = b[:len(b)+m]
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, io.ErrUnexpectedEOF
 			}
-			flags = append(flags, NewFlag(f, rs.scanner.Text()))
-			i -= 1
-		default:
-			return NewErrorCommand(customerror.InvalidCommandFlagError{Cmd: SET, Flag: f})
+			return nil, err
 		}
 	}
 
-	return NewSetCommand(args, flags)
+	return b, nil
 }
 
-func (rs *RedisScanner) parseGetCmd(np int) Command {
-	if np < 1 {
-		return NewErrorCommand(customerror.InvalidNumberOfArgumentsError{})
+func (rs *RedisScanner) handleCommand(args [][]byte) Command {
+	cs, ok := lookupCommand(args[0])
+	if !ok {
+		return NewErrorCommand(customerror.UnknownCommandError{Name: string(args[0]), Args: args[1:]})
 	}
 
-	err := rs.skipLen()
-	if err != nil {
-		return NewErrorCommand(err)
+	if len(cs.Subcommands) > 0 && len(args) > 1 {
+		sc, ok := cs.subcommand(args[1])
+		if !ok {
+			return NewErrorCommand(customerror.UnknownSubcommandError{Cmd: cs.Name, Subcommand: string(args[1])})
+		}
+		cs = sc
 	}
-	k := rs.scanner.Text()
 
-	args := []string{k}
-	flags := []*Flag{}
+	if !cs.checkArity(len(args)) {
+		return NewErrorCommand(customerror.WrongNumberOfArgumentsError{Cmd: cs.FullName()})
+	}
+
+	cmd := cs.parse(args)
+	if _, ok := cmd.(*ErrorCommand); ok {
+		return cmd
+	}
 
-	return NewGetCommand(args, flags)
+	return &call{cs, args, cmd}
 }
 
-func (rs *RedisScanner) parseConfigCmd(np int) Command {
-	if np < 1 {
-		return NewErrorCommand(customerror.InvalidNumberOfArgumentsError{})
-	}
+func parsePingCmd(args [][]byte) Command {
+	return NewPingCommand()
+}
 
-	args := []string{}
+func parseEchoCmd(args [][]byte) Command {
+	return NewEchoCommand([][]byte{args[1]})
+}
+
+// https://redis.io/docs/latest/commands/set/
+func parseSetCmd(args [][]byte) Command {
+	k := args[1]
+	v := args[2]
 	flags := []*Flag{}
 
-	for i := np; i > 0; i-- {
-		err := rs.skipLen()
-		if err != nil {
-			return NewErrorCommand(err)
-		}
-		f := rs.scanner.Text()
-		i -= 1
-		switch strings.ToUpper(f) {
+	var condition, expire string
+	get := false
+	for i := 3; i < len(args); i++ {
+		f := strings.ToUpper(string(args[i]))
+		switch f {
+		case NX, XX:
+			if condition != "" {
+				return NewErrorCommand(customerror.SyntaxError{})
+			}
+			condition = f
+			flags = append(flags, NewFlag(f, ""))
 		case GET:
-			for i > 0 {
-				err := rs.skipLen()
-				if err != nil {
-					return NewErrorCommand(err)
-				}
-				flags = append(flags, NewFlag(f, rs.scanner.Text()))
-				i -= 1
+			if get {
+				return NewErrorCommand(customerror.SyntaxError{})
+			}
+			get = true
+			flags = append(flags, NewFlag(f, ""))
+		case KEEPTTL:
+			if expire != "" {
+				return NewErrorCommand(customerror.SyntaxError{})
 			}
+			expire = f
+			flags = append(flags, NewFlag(f, ""))
+		case EX, PX, EXAT, PXAT:
+			if expire != "" || i+1 >= len(args) {
+				return NewErrorCommand(customerror.SyntaxError{})
+			}
+			expire = f
+			flags = append(flags, NewFlag(f, string(args[i+1])))
+			i++
 		default:
-			NewErrorCommand(customerror.InvalidCommandFlagError{Cmd: CONFIG, Flag: f})
+			return NewErrorCommand(customerror.SyntaxError{})
 		}
 	}
 
-	return NewConfigCommand(args, flags)
+	for _, f := range flags {
+		switch f.name {
+		case EX, PX, EXAT, PXAT:
+			if err := checkExpireTime(f.name, f.value, "set"); err != nil {
+				return NewErrorCommand(err)
+			}
+		}
+	}
+
+	return NewSetCommand([][]byte{k, v}, flags)
 }
 
-func (rs *RedisScanner) parseKeysCmd(np int) Command {
-	if np < 1 {
-		return NewErrorCommand(customerror.InvalidNumberOfArgumentsError{})
+// checkExpireTime validates an EX, PX, EXAT or PXAT value, it must be a
+// positive integer that still fits in milliseconds once converted
+func checkExpireTime(unit, value, cmd string) error {
+	n, ok := util.ParseStrictInt([]byte(value))
+	if !ok {
+		return customerror.NotIntegerError{}
 	}
 
-	err := rs.skipLen()
-	if err != nil {
-		return NewErrorCommand(err)
+	if n <= 0 {
+		return customerror.InvalidExpireTimeError{Cmd: cmd}
 	}
-	k := rs.scanner.Text()
 
-	args := []string{k}
-	flags := []*Flag{}
+	if (unit == EX || unit == EXAT) && n > math.MaxInt64/1000 {
+		return customerror.InvalidExpireTimeError{Cmd: cmd}
+	}
 
-	return NewKeysCommand(args, flags)
+	return nil
 }
 
-func (rs *RedisScanner) parseInfoCmd(np int) Command {
-	if np < 1 {
-		return NewErrorCommand(customerror.InvalidNumberOfArgumentsError{})
+// expireAt converts a validated expire option into an absolute time
+func expireAt(unit, value string, now time.Time) time.Time {
+	n, _ := strconv.ParseInt(value, 10, 64)
+
+	switch unit {
+	case EX:
+		return now.Add(time.Duration(n) * time.Second)
+	case PX:
+		return now.Add(time.Duration(n) * time.Millisecond)
+	case EXAT:
+		return time.Unix(n, 0)
+	default:
+		return time.UnixMilli(n)
 	}
+}
+
+func parseGetCmd(args [][]byte) Command {
+	return NewGetCommand([][]byte{args[1]}, []*Flag{})
+}
 
-	args := []string{}
+func parseConfigGetCmd(args [][]byte) Command {
 	flags := []*Flag{}
+	for _, a := range args[2:] {
+		flags = append(flags, NewFlag(GET, string(a)))
+	}
 
-	if np == 1 {
-		err := rs.skipLen()
-		if err != nil {
-			return NewErrorCommand(err)
+	return NewConfigCommand([][]byte{}, flags)
+}
+
+func parseKeysCmd(args [][]byte) Command {
+	return NewKeysCommand([][]byte{args[1]}, []*Flag{})
+}
+
+func parseInfoCmd(args [][]byte) Command {
+	return NewInfoCommand(args[1:], []*Flag{})
+}
+
+func parseHelloCmd(args [][]byte) Command {
+	if len(args) < 2 {
+		return NewHelloCommand(0, nil, nil, nil)
+	}
+
+	protover, err := strconv.Atoi(string(args[1]))
+	if err != nil || protover <= 0 {
+		return NewErrorCommand(customerror.InvalidProtocolVersionError{})
+	}
+
+	var username, password, name []byte
+	for i := 2; i < len(args); i++ {
+		f := string(args[i])
+		switch {
+		case strings.EqualFold(f, AUTH) && i+2 < len(args):
+			username = args[i+1]
+			password = args[i+2]
+			i += 2
+		case strings.EqualFold(f, SETNAME) && i+1 < len(args):
+			name = args[i+1]
+			if !isValidClientName(name) {
+				return NewErrorCommand(customerror.InvalidClientNameError{})
+			}
+			i++
+		default:
+			return NewErrorCommand(customerror.SyntaxError{})
+		}
+	}
+
+	return NewHelloCommand(protover, username, password, name)
+}
+
+func parseCommandCmd(args [][]byte) Command {
+	return NewCommandInfoCommand(nil, nil)
+}
+
+func parseCommandCountCmd(args [][]byte) Command {
+	return NewCommandCountCommand()
+}
+
+func parseCommandInfoCmd(args [][]byte) Command {
+	return NewCommandInfoCommand(args[2:], nil)
+}
+
+func parseCommandDocsCmd(args [][]byte) Command {
+	return NewCommandDocsCommand(args[2:], nil)
+}
+
+func parseCommandListCmd(args [][]byte) Command {
+	if len(args) == 2 {
+		return NewCommandListCommand(nil, nil)
+	}
+
+	if len(args) != 5 || !strings.EqualFold(string(args[2]), FILTERBY) {
+		return NewErrorCommand(customerror.SyntaxError{})
+	}
+
+	f := strings.ToUpper(string(args[3]))
+	switch f {
+	case MODULE, ACLCAT, PATTERN:
+	default:
+		return NewErrorCommand(customerror.SyntaxError{})
+	}
+
+	return NewCommandListCommand(nil, []*Flag{NewFlag(f, string(args[4]))})
+}
+
+func parseCommandGetKeysCmd(args [][]byte) Command {
+	return NewCommandGetKeysCommand(args[2:], nil)
+}
+
+// client names may only contain printable characters without spaces
+func isValidClientName(name []byte) bool {
+	for _, c := range name {
+		if c < '!' || c > '~' {
+			return false
 		}
-		a := rs.scanner.Text()
-		np -= 1
-		args = append(args, a)
 	}
 
-	return NewInfoCommand(args, flags)
+	return true
 }
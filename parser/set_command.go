@@ -0,0 +1,828 @@
+package parser
+
+import (
+	"log"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JanitSri/codecrafters-build-your-own-redis/customerror"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/data"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/util"
+)
+
+func parseSAddCmd(args [][]byte) Command {
+	return NewSAddCommand(args[1:], nil)
+}
+
+func parseSRemCmd(args [][]byte) Command {
+	return NewSRemCommand(args[1:], nil)
+}
+
+func parseSMembersCmd(args [][]byte) Command {
+	return NewSMembersCommand([][]byte{args[1]}, nil)
+}
+
+func parseSIsMemberCmd(args [][]byte) Command {
+	return NewSIsMemberCommand(args[1:3], nil)
+}
+
+func parseSMIsMemberCmd(args [][]byte) Command {
+	return NewSMIsMemberCommand(args[1:], nil)
+}
+
+func parseSCardCmd(args [][]byte) Command {
+	return NewSCardCommand([][]byte{args[1]}, nil)
+}
+
+// https://redis.io/docs/latest/commands/spop/
+func parseSPopCmd(args [][]byte) Command {
+	if len(args) > 3 {
+		return NewErrorCommand(customerror.SyntaxError{})
+	}
+
+	var flags []*Flag
+	if len(args) == 3 {
+		n, ok := util.ParseStrictInt(args[2])
+		if !ok || n < 0 {
+			return NewErrorCommand(customerror.PositiveValueError{})
+		}
+		flags = append(flags, NewFlag(COUNT, string(args[2])))
+	}
+
+	return NewSPopCommand([][]byte{args[1]}, flags)
+}
+
+// https://redis.io/docs/latest/commands/srandmember/
+func parseSRandMemberCmd(args [][]byte) Command {
+	if len(args) > 3 {
+		return NewErrorCommand(customerror.SyntaxError{})
+	}
+
+	var flags []*Flag
+	if len(args) == 3 {
+		n, ok := util.ParseStrictInt(args[2])
+		if !ok {
+			return NewErrorCommand(customerror.NotIntegerError{})
+		}
+		if n == math.MinInt64 {
+			return NewErrorCommand(customerror.ValueOutOfRangeError{})
+		}
+		flags = append(flags, NewFlag(COUNT, string(args[2])))
+	}
+
+	return NewSRandMemberCommand([][]byte{args[1]}, flags)
+}
+
+func parseSMoveCmd(args [][]byte) Command {
+	return NewSMoveCommand(args[1:4], nil)
+}
+
+func parseSInterCmd(args [][]byte) Command {
+	return NewSetOpCommand(args[1:], []*Flag{NewFlag(INTER, "")})
+}
+
+func parseSInterStoreCmd(args [][]byte) Command {
+	return NewSetOpCommand(args[1:], []*Flag{NewFlag(INTER, ""), NewFlag(STORE, "")})
+}
+
+func parseSUnionCmd(args [][]byte) Command {
+	return NewSetOpCommand(args[1:], []*Flag{NewFlag(UNION, "")})
+}
+
+func parseSUnionStoreCmd(args [][]byte) Command {
+	return NewSetOpCommand(args[1:], []*Flag{NewFlag(UNION, ""), NewFlag(STORE, "")})
+}
+
+func parseSDiffCmd(args [][]byte) Command {
+	return NewSetOpCommand(args[1:], []*Flag{NewFlag(DIFF, "")})
+}
+
+func parseSDiffStoreCmd(args [][]byte) Command {
+	return NewSetOpCommand(args[1:], []*Flag{NewFlag(DIFF, ""), NewFlag(STORE, "")})
+}
+
+// https://redis.io/docs/latest/commands/sintercard/
+func parseSInterCardCmd(args [][]byte) Command {
+	numKeys, ok := util.ParseStrictInt(args[1])
+	if !ok || numKeys <= 0 {
+		return NewErrorCommand(customerror.NumKeysError{})
+	}
+
+	if numKeys > int64(len(args)-2) {
+		return NewErrorCommand(customerror.NumKeysTooManyError{})
+	}
+
+	keys := args[2 : 2+numKeys]
+	rest := args[2+numKeys:]
+	flags := []*Flag{NewFlag(LIMIT, "0")}
+	if len(rest) > 0 {
+		if len(rest) != 2 || strings.ToUpper(string(rest[0])) != LIMIT {
+			return NewErrorCommand(customerror.SyntaxError{})
+		}
+
+		n, ok := util.ParseStrictInt(rest[1])
+		if !ok || n < 0 {
+			return NewErrorCommand(customerror.NegativeOptionError{Option: LIMIT})
+		}
+		flags[0].value = string(rest[1])
+	}
+
+	return NewSInterCardCommand(keys, flags)
+}
+
+// lookupSet returns the set stored at key, exists is false when the key is
+// missing or expired
+func lookupSet(rc *data.RedisContext, key []byte) (*data.Set, bool, error) {
+	v, ok := lookupKey(rc, key)
+	if !ok {
+		return nil, false, nil
+	}
+
+	s, isSet := v.Value().(*data.Set)
+	if !isSet {
+		return nil, true, customerror.WrongTypeError{}
+	}
+
+	return s, true, nil
+}
+
+// lookupOrCreateSet returns the set stored at key, an empty set is stored
+// first when the key is missing
+func lookupOrCreateSet(rc *data.RedisContext, key []byte) (*data.Set, error) {
+	s, exists, err := lookupSet(rc, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		s = data.NewSet()
+		rc.DataStore.Set(key, data.NewRedisValue(s, time.Time{}))
+	}
+
+	return s, nil
+}
+
+// lookupSets returns the sets stored at keys, nil for the keys that are
+// missing. Every key is checked so a key of another type is an error even
+// when a previous one is missing
+func lookupSets(rc *data.RedisContext, keys [][]byte) ([]*data.Set, error) {
+	sets := make([]*data.Set, len(keys))
+	for i, k := range keys {
+		s, _, err := lookupSet(rc, k)
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = s
+	}
+
+	return sets, nil
+}
+
+// addSetMember stores the member and reports whether it is new. An intset
+// or a compact set is converted once it outgrows the set-max-* limits
+func addSetMember(rc *data.RedisContext, s *data.Set, m []byte) bool {
+	cfg := rc.DataStore.Config()
+	isNew := s.Add(m)
+
+	switch s.Encoding() {
+	case data.ENCODING_INTSET:
+		if int64(s.Len()) > cfg.SetMaxIntsetEntries() {
+			s.Convert(data.ENCODING_HASHTABLE)
+		}
+	case data.ENCODING_LISTPACK:
+		if int64(s.Len()) > cfg.SetMaxListpackEntries() || int64(len(m)) > cfg.SetMaxListpackValue() {
+			s.Convert(data.ENCODING_HASHTABLE)
+		}
+	}
+
+	return isNew
+}
+
+// setMembersChanged is called once a command removed members of a set, a
+// set left without members is deleted
+func setMembersChanged(rc *data.RedisContext, key []byte, s *data.Set) {
+	if s.Len() == 0 {
+		rc.DataStore.Delete(key)
+	}
+
+	signalModifiedKey(rc, key)
+}
+
+// writeSet replies with every member of the set, as a set in RESP3
+func writeSet(w *ReplyWriter, s *data.Set) {
+	w.SetLen(s.Len())
+	s.Iterate(func(m []byte) bool {
+		w.BulkString(m)
+		return true
+	})
+}
+
+type SAddCommand struct {
+	BaseCommand
+}
+
+func NewSAddCommand(args [][]byte, flags []*Flag) *SAddCommand {
+	return &SAddCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (sc *SAddCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("adding set members...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := sc.args[0]
+
+	s, err := lookupOrCreateSet(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	added := 0
+	for _, m := range sc.args[1:] {
+		if addSetMember(rc, s, m) {
+			added++
+		}
+	}
+
+	if added > 0 {
+		signalModifiedKey(rc, key)
+	}
+
+	w.Integer(int64(added))
+	return w.Bytes()
+}
+
+type SRemCommand struct {
+	BaseCommand
+}
+
+func NewSRemCommand(args [][]byte, flags []*Flag) *SRemCommand {
+	return &SRemCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (sc *SRemCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("removing set members...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := sc.args[0]
+	s, exists, err := lookupSet(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	removed := 0
+	for _, m := range sc.args[1:] {
+		if s.Remove(m) {
+			removed++
+		}
+	}
+
+	if removed > 0 {
+		setMembersChanged(rc, key, s)
+	}
+
+	w.Integer(int64(removed))
+	return w.Bytes()
+}
+
+type SMembersCommand struct {
+	BaseCommand
+}
+
+func NewSMembersCommand(args [][]byte, flags []*Flag) *SMembersCommand {
+	return &SMembersCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (sc *SMembersCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting set members...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	s, exists, err := lookupSet(rc, sc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.SetLen(0)
+		return w.Bytes()
+	}
+
+	writeSet(w, s)
+	return w.Bytes()
+}
+
+type SIsMemberCommand struct {
+	BaseCommand
+}
+
+func NewSIsMemberCommand(args [][]byte, flags []*Flag) *SIsMemberCommand {
+	return &SIsMemberCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (sc *SIsMemberCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("checking set member...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	s, exists, err := lookupSet(rc, sc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if exists && s.Contains(sc.args[1]) {
+		w.Integer(1)
+		return w.Bytes()
+	}
+
+	w.Integer(0)
+	return w.Bytes()
+}
+
+type SMIsMemberCommand struct {
+	BaseCommand
+}
+
+func NewSMIsMemberCommand(args [][]byte, flags []*Flag) *SMIsMemberCommand {
+	return &SMIsMemberCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (sc *SMIsMemberCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("checking set members...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	s, exists, err := lookupSet(rc, sc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	members := sc.args[1:]
+	w.ArrayLen(len(members))
+	for _, m := range members {
+		if exists && s.Contains(m) {
+			w.Integer(1)
+		} else {
+			w.Integer(0)
+		}
+	}
+
+	return w.Bytes()
+}
+
+type SCardCommand struct {
+	BaseCommand
+}
+
+func NewSCardCommand(args [][]byte, flags []*Flag) *SCardCommand {
+	return &SCardCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (sc *SCardCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting set cardinality...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	s, exists, err := lookupSet(rc, sc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	w.Integer(int64(s.Len()))
+	return w.Bytes()
+}
+
+// SPopCommand removes a single random member without the COUNT flag,
+// otherwise count distinct members replied as a set
+type SPopCommand struct {
+	BaseCommand
+}
+
+func NewSPopCommand(args [][]byte, flags []*Flag) *SPopCommand {
+	return &SPopCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (sc *SPopCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("popping set members...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := sc.args[0]
+	hasCount := len(sc.flags) > 0
+
+	var count int64
+	if hasCount {
+		count, _ = strconv.ParseInt(sc.flags[0].value, 10, 64)
+
+		// like Redis a count of 0 replies before the key is looked up
+		if count == 0 {
+			w.SetLen(0)
+			return w.Bytes()
+		}
+	}
+
+	s, exists, err := lookupSet(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		if hasCount {
+			w.SetLen(0)
+		} else {
+			w.Null()
+		}
+		return w.Bytes()
+	}
+
+	if !hasCount {
+		w.BulkString(s.Pop())
+		setMembersChanged(rc, key, s)
+		return w.Bytes()
+	}
+
+	// the whole set is popped by deleting the key
+	if count >= int64(s.Len()) {
+		writeSet(w, s)
+		rc.DataStore.Delete(key)
+		signalModifiedKey(rc, key)
+		return w.Bytes()
+	}
+
+	w.SetLen(int(count))
+	for range count {
+		w.BulkString(s.Pop())
+	}
+	setMembersChanged(rc, key, s)
+
+	return w.Bytes()
+}
+
+// SRandMemberCommand returns a single random member without the COUNT
+// flag, otherwise count distinct members or, when count is negative, that
+// many members which may repeat
+type SRandMemberCommand struct {
+	BaseCommand
+}
+
+func NewSRandMemberCommand(args [][]byte, flags []*Flag) *SRandMemberCommand {
+	return &SRandMemberCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (sc *SRandMemberCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting random set members...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	hasCount := len(sc.flags) > 0
+
+	s, exists, err := lookupSet(rc, sc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		if hasCount {
+			w.ArrayLen(0)
+		} else {
+			w.Null()
+		}
+		return w.Bytes()
+	}
+
+	if !hasCount {
+		w.BulkString(s.Random())
+		return w.Bytes()
+	}
+
+	count, _ := strconv.ParseInt(sc.flags[0].value, 10, 64)
+
+	// a negative count allows the same member more than once
+	if count < 0 {
+		w.ArrayLen(int(-count))
+		for range -count {
+			w.BulkString(s.Random())
+		}
+		return w.Bytes()
+	}
+
+	if count >= int64(s.Len()) {
+		w.ArrayLen(s.Len())
+		s.Iterate(func(m []byte) bool {
+			w.BulkString(m)
+			return true
+		})
+		return w.Bytes()
+	}
+
+	// when most members are wanted picking at random would keep finding
+	// the same ones, a partial shuffle of every member is cheaper
+	w.ArrayLen(int(count))
+	if count*3 > int64(s.Len()) {
+		members := make([][]byte, 0, s.Len())
+		s.Iterate(func(m []byte) bool {
+			members = append(members, m)
+			return true
+		})
+
+		for i := range int(count) {
+			j := i + rand.IntN(len(members)-i)
+			members[i], members[j] = members[j], members[i]
+			w.BulkString(members[i])
+		}
+		return w.Bytes()
+	}
+
+	picked := make(map[string]struct{}, count)
+	for int64(len(picked)) < count {
+		m := s.Random()
+		if _, ok := picked[string(m)]; ok {
+			continue
+		}
+		picked[string(m)] = struct{}{}
+		w.BulkString(m)
+	}
+
+	return w.Bytes()
+}
+
+type SMoveCommand struct {
+	BaseCommand
+}
+
+func NewSMoveCommand(args [][]byte, flags []*Flag) *SMoveCommand {
+	return &SMoveCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (sc *SMoveCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("moving set member...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	src, dst, m := sc.args[0], sc.args[1], sc.args[2]
+
+	ss, exists, err := lookupSet(rc, src)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	ds, exists, err := lookupSet(rc, dst)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	// moving a member to the set it is in changes nothing
+	if string(src) == string(dst) {
+		if ss.Contains(m) {
+			w.Integer(1)
+		} else {
+			w.Integer(0)
+		}
+		return w.Bytes()
+	}
+
+	if !ss.Remove(m) {
+		w.Integer(0)
+		return w.Bytes()
+	}
+	setMembersChanged(rc, src, ss)
+
+	if !exists {
+		ds = data.NewSet()
+		rc.DataStore.Set(dst, data.NewRedisValue(ds, time.Time{}))
+	}
+	addSetMember(rc, ds, m)
+	signalModifiedKey(rc, dst)
+
+	w.Integer(1)
+	return w.Bytes()
+}
+
+// setOperation computes the intersection, union or difference of the sets,
+// a nil set is a missing key and counts as an empty set
+func setOperation(rc *data.RedisContext, op string, sets []*data.Set) *data.Set {
+	res := data.NewSet()
+
+	switch op {
+	case INTER:
+		if slices.Contains(sets, nil) {
+			return res
+		}
+
+		// the smallest set is walked and its members looked up in the
+		// others
+		sets = slices.Clone(sets)
+		slices.SortFunc(sets, func(a, b *data.Set) int {
+			return a.Len() - b.Len()
+		})
+
+		sets[0].Iterate(func(m []byte) bool {
+			for _, s := range sets[1:] {
+				if !s.Contains(m) {
+					return true
+				}
+			}
+			addSetMember(rc, res, m)
+			return true
+		})
+	case UNION:
+		for _, s := range sets {
+			if s == nil {
+				continue
+			}
+
+			s.Iterate(func(m []byte) bool {
+				addSetMember(rc, res, m)
+				return true
+			})
+		}
+	case DIFF:
+		if sets[0] == nil {
+			return res
+		}
+
+		sets[0].Iterate(func(m []byte) bool {
+			for _, s := range sets[1:] {
+				if s != nil && s.Contains(m) {
+					return true
+				}
+			}
+			addSetMember(rc, res, m)
+			return true
+		})
+	}
+
+	return res
+}
+
+// SetOpCommand implements SINTER, SUNION and SDIFF, the operation is the
+// first flag. With the STORE flag the first argument is the destination
+// key and the result is stored there instead of being replied
+type SetOpCommand struct {
+	BaseCommand
+}
+
+func NewSetOpCommand(args [][]byte, flags []*Flag) *SetOpCommand {
+	return &SetOpCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (sc *SetOpCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("computing set operation...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	op := sc.flags[0].name
+	store := len(sc.flags) > 1
+
+	keys := sc.args
+	if store {
+		keys = sc.args[1:]
+	}
+
+	sets, err := lookupSets(rc, keys)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	res := setOperation(rc, op, sets)
+	if !store {
+		writeSet(w, res)
+		return w.Bytes()
+	}
+
+	// an empty result deletes the destination
+	dest := sc.args[0]
+	if res.Len() == 0 {
+		if rc.DataStore.Delete(dest) {
+			signalModifiedKey(rc, dest)
+		}
+	} else {
+		rc.DataStore.Set(dest, data.NewRedisValue(res, time.Time{}))
+		signalModifiedKey(rc, dest)
+	}
+
+	w.Integer(int64(res.Len()))
+	return w.Bytes()
+}
+
+// SInterCardCommand counts the members of the intersection without
+// building it, it stops once the LIMIT flag is reached unless it is 0
+type SInterCardCommand struct {
+	BaseCommand
+}
+
+func NewSInterCardCommand(args [][]byte, flags []*Flag) *SInterCardCommand {
+	return &SInterCardCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (sc *SInterCardCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("counting set intersection...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	limit, _ := strconv.ParseInt(sc.flags[0].value, 10, 64)
+
+	sets, err := lookupSets(rc, sc.args)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if slices.Contains(sets, nil) {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	slices.SortFunc(sets, func(a, b *data.Set) int {
+		return a.Len() - b.Len()
+	})
+
+	var n int64
+	sets[0].Iterate(func(m []byte) bool {
+		for _, s := range sets[1:] {
+			if !s.Contains(m) {
+				return true
+			}
+		}
+		n++
+		return limit == 0 || n < limit
+	})
+
+	w.Integer(n)
+	return w.Bytes()
+}
@@ -0,0 +1,927 @@
+package parser
+
+import (
+	"bytes"
+	"log"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JanitSri/codecrafters-build-your-own-redis/customerror"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/data"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/util"
+)
+
+func parseIncrCmd(args [][]byte) Command {
+	return NewIncrByCommand([][]byte{args[1], []byte("1")}, nil)
+}
+
+func parseDecrCmd(args [][]byte) Command {
+	return NewIncrByCommand([][]byte{args[1], []byte("-1")}, nil)
+}
+
+func parseIncrByCmd(args [][]byte) Command {
+	if _, ok := util.ParseStrictInt(args[2]); !ok {
+		return NewErrorCommand(customerror.NotIntegerError{})
+	}
+
+	return NewIncrByCommand([][]byte{args[1], args[2]}, nil)
+}
+
+func parseDecrByCmd(args [][]byte) Command {
+	n, ok := util.ParseStrictInt(args[2])
+	if !ok {
+		return NewErrorCommand(customerror.NotIntegerError{})
+	}
+
+	if n == math.MinInt64 {
+		return NewErrorCommand(customerror.DecrOverflowError{})
+	}
+
+	return NewIncrByCommand([][]byte{args[1], strconv.AppendInt(nil, -n, 10)}, nil)
+}
+
+func parseIncrByFloatCmd(args [][]byte) Command {
+	if _, ok := parseLongDouble(args[2]); !ok {
+		return NewErrorCommand(customerror.NotFloatError{})
+	}
+
+	return NewIncrByFloatCommand([][]byte{args[1], args[2]}, nil)
+}
+
+// parseFloat accepts what strtold accepts in Redis except NaN, surrounding
+// spaces are rejected
+func parseFloat(b []byte) (float64, bool) {
+	s := string(b)
+	if s == "" || strings.TrimSpace(s) != s {
+		return 0, false
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return f, !math.IsNaN(f)
+}
+
+// the mantissa of a C long double, INCRBYFLOAT and HINCRBYFLOAT do their
+// arithmetic with it so an increment is not lost on a large value
+const longDoublePrec = 64
+
+// parseLongDouble is parseFloat with the precision of a long double
+func parseLongDouble(b []byte) (*big.Float, bool) {
+	s := string(b)
+	if s == "" || strings.TrimSpace(s) != s {
+		return nil, false
+	}
+
+	f, _, err := big.ParseFloat(s, 10, longDoublePrec, big.ToNearestEven)
+	if err != nil {
+		return nil, false
+	}
+
+	return f, true
+}
+
+// incrLongDouble adds incr to f, it reports false when either is infinite
+// since the result would be infinite or NaN
+func incrLongDouble(f, incr *big.Float) (*big.Float, bool) {
+	if f.IsInf() || incr.IsInf() {
+		return nil, false
+	}
+
+	return new(big.Float).SetPrec(longDoublePrec).Add(f, incr), true
+}
+
+// formatLongDouble writes a float the way INCRBYFLOAT stores it, like
+// Redis it prints 17 decimals without an exponent and then trims the
+// trailing zeros
+func formatLongDouble(f *big.Float) []byte {
+	b := f.Append(nil, 'f', 17)
+	b = bytes.TrimRight(b, "0")
+	return bytes.TrimSuffix(b, []byte("."))
+}
+
+// lookupString returns the live string value of a key, exists is false when
+// the key is missing or expired
+func lookupString(rc *data.RedisContext, key []byte) (v *data.RedisValue, exists bool, err error) {
+	v, ok := lookupKey(rc, key)
+	if !ok {
+		return nil, false, nil
+	}
+
+	if _, isString := v.Bytes(); !isString {
+		return nil, false, customerror.WrongTypeError{}
+	}
+
+	return v, true, nil
+}
+
+// IncrByCommand implements INCR, DECR, INCRBY and DECRBY, the parse
+// functions turn each of them into an increment
+type IncrByCommand struct {
+	BaseCommand
+}
+
+func NewIncrByCommand(args [][]byte, flags []*Flag) *IncrByCommand {
+	return &IncrByCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+// https://redis.io/docs/latest/commands/incrby/
+func (ic *IncrByCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("incrementing...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := ic.args[0]
+	incr, _ := util.ParseStrictInt(ic.args[1])
+
+	old, exists, err := lookupString(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	var n int64
+	var expiry time.Time
+	if exists {
+		b, _ := old.Bytes()
+		v, ok := util.ParseStrictInt(b)
+		if !ok {
+			w.Error(customerror.NotIntegerError{})
+			return w.Bytes()
+		}
+		n = v
+		expiry = old.Expiry()
+	}
+
+	if (incr < 0 && n < 0 && incr < math.MinInt64-n) || (incr > 0 && n > 0 && incr > math.MaxInt64-n) {
+		w.Error(customerror.IncrOverflowError{})
+		return w.Bytes()
+	}
+	n += incr
+
+	rc.DataStore.Set(key, data.NewRedisValue(n, expiry))
+	signalModifiedKey(rc, key)
+
+	w.Integer(n)
+	return w.Bytes()
+}
+
+type IncrByFloatCommand struct {
+	BaseCommand
+}
+
+func NewIncrByFloatCommand(args [][]byte, flags []*Flag) *IncrByFloatCommand {
+	return &IncrByFloatCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+// https://redis.io/docs/latest/commands/incrbyfloat/
+func (ic *IncrByFloatCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("incrementing by float...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := ic.args[0]
+	incr, _ := parseLongDouble(ic.args[1])
+
+	old, exists, err := lookupString(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	f := new(big.Float).SetPrec(longDoublePrec)
+	var expiry time.Time
+	if exists {
+		b, _ := old.Bytes()
+		v, ok := parseLongDouble(b)
+		if !ok {
+			w.Error(customerror.NotFloatError{})
+			return w.Bytes()
+		}
+		f = v
+		expiry = old.Expiry()
+	}
+
+	f, ok := incrLongDouble(f, incr)
+	if !ok {
+		w.Error(customerror.NaNOrInfinityError{})
+		return w.Bytes()
+	}
+
+	b := formatLongDouble(f)
+	rc.DataStore.Set(key, data.NewStringValue(b, expiry))
+	signalModifiedKey(rc, key)
+
+	w.BulkString(b)
+	return w.Bytes()
+}
+
+func parseAppendCmd(args [][]byte) Command {
+	return NewAppendCommand([][]byte{args[1], args[2]}, nil)
+}
+
+func parseStrlenCmd(args [][]byte) Command {
+	return NewStrlenCommand([][]byte{args[1]}, nil)
+}
+
+func parseGetRangeCmd(args [][]byte) Command {
+	for _, a := range args[2:] {
+		if _, ok := util.ParseStrictInt(a); !ok {
+			return NewErrorCommand(customerror.NotIntegerError{})
+		}
+	}
+
+	return NewGetRangeCommand([][]byte{args[1], args[2], args[3]}, nil)
+}
+
+func parseSetRangeCmd(args [][]byte) Command {
+	offset, ok := util.ParseStrictInt(args[2])
+	if !ok {
+		return NewErrorCommand(customerror.NotIntegerError{})
+	}
+
+	if offset < 0 {
+		return NewErrorCommand(customerror.OffsetOutOfRangeError{})
+	}
+
+	return NewSetRangeCommand([][]byte{args[1], args[2], args[3]}, nil)
+}
+
+func parseGetDelCmd(args [][]byte) Command {
+	return NewGetDelCommand([][]byte{args[1]}, nil)
+}
+
+func parseGetExCmd(args [][]byte) Command {
+	flags := []*Flag{}
+	for i := 2; i < len(args); i++ {
+		f := strings.ToUpper(string(args[i]))
+		switch f {
+		case PERSIST:
+			flags = append(flags, NewFlag(f, ""))
+		case EX, PX, EXAT, PXAT:
+			if i+1 >= len(args) {
+				return NewErrorCommand(customerror.SyntaxError{})
+			}
+			flags = append(flags, NewFlag(f, string(args[i+1])))
+			i++
+		default:
+			return NewErrorCommand(customerror.SyntaxError{})
+		}
+	}
+
+	if len(flags) > 1 {
+		return NewErrorCommand(customerror.SyntaxError{})
+	}
+
+	for _, f := range flags {
+		if f.name == PERSIST {
+			continue
+		}
+		if err := checkExpireTime(f.name, f.value, "getex"); err != nil {
+			return NewErrorCommand(err)
+		}
+	}
+
+	return NewGetExCommand([][]byte{args[1]}, flags)
+}
+
+// GETSET is SET with the GET option, it also discards the TTL
+func parseGetSetCmd(args [][]byte) Command {
+	return NewSetCommand([][]byte{args[1], args[2]}, []*Flag{NewFlag(GET, "")})
+}
+
+func parseSetExCmd(args [][]byte) Command {
+	return parseSetExpireCmd(args, EX, "setex")
+}
+
+func parsePSetExCmd(args [][]byte) Command {
+	return parseSetExpireCmd(args, PX, "psetex")
+}
+
+func parseSetExpireCmd(args [][]byte, unit, cmd string) Command {
+	if err := checkExpireTime(unit, string(args[2]), cmd); err != nil {
+		return NewErrorCommand(err)
+	}
+
+	return NewSetCommand([][]byte{args[1], args[3]}, []*Flag{NewFlag(unit, string(args[2]))})
+}
+
+func parseMGetCmd(args [][]byte) Command {
+	return NewMGetCommand(args[1:], nil)
+}
+
+func parseMSetCmd(args [][]byte) Command {
+	if len(args)%2 == 0 {
+		return NewErrorCommand(customerror.WrongNumberOfArgumentsError{Cmd: string(args[0])})
+	}
+
+	return NewMSetCommand(args[1:], nil)
+}
+
+func parseMSetNxCmd(args [][]byte) Command {
+	if len(args)%2 == 0 {
+		return NewErrorCommand(customerror.WrongNumberOfArgumentsError{Cmd: string(args[0])})
+	}
+
+	return NewMSetCommand(args[1:], []*Flag{NewFlag(NX, "")})
+}
+
+// SETNX is MSETNX with a single key
+func parseSetNxCmd(args [][]byte) Command {
+	return NewMSetCommand([][]byte{args[1], args[2]}, []*Flag{NewFlag(NX, "")})
+}
+
+// checkStringLength keeps strings grown by APPEND and SETRANGE within the
+// size a client would be allowed to send
+func checkStringLength(rc *data.RedisContext, n int64) error {
+	if n > rc.DataStore.Config().ProtoMaxBulkLen() {
+		return customerror.StringTooLongError{}
+	}
+
+	return nil
+}
+
+type AppendCommand struct {
+	BaseCommand
+}
+
+func NewAppendCommand(args [][]byte, flags []*Flag) *AppendCommand {
+	return &AppendCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (ac *AppendCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("appending...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := ac.args[0]
+
+	old, exists, err := lookupString(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	var b []byte
+	var expiry time.Time
+	if exists {
+		b, _ = old.Bytes()
+		expiry = old.Expiry()
+	}
+
+	if err := checkStringLength(rc, int64(len(b)+len(ac.args[1]))); err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	// the stored bytes may be shared with a reply, always copy
+	nb := make([]byte, 0, len(b)+len(ac.args[1]))
+	nb = append(append(nb, b...), ac.args[1]...)
+
+	rc.DataStore.Set(key, data.NewStringValue(nb, expiry))
+	signalModifiedKey(rc, key)
+
+	w.Integer(int64(len(nb)))
+	return w.Bytes()
+}
+
+type StrlenCommand struct {
+	BaseCommand
+}
+
+func NewStrlenCommand(args [][]byte, flags []*Flag) *StrlenCommand {
+	return &StrlenCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (sc *StrlenCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting string length...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	v, exists, err := lookupString(rc, sc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	var n int
+	if exists {
+		b, _ := v.Bytes()
+		n = len(b)
+	}
+
+	w.Integer(int64(n))
+	return w.Bytes()
+}
+
+type GetRangeCommand struct {
+	BaseCommand
+}
+
+func NewGetRangeCommand(args [][]byte, flags []*Flag) *GetRangeCommand {
+	return &GetRangeCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+// https://redis.io/docs/latest/commands/getrange/
+func (gc *GetRangeCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting range...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	v, exists, err := lookupString(rc, gc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	var b []byte
+	if exists {
+		b, _ = v.Bytes()
+	}
+
+	start, _ := util.ParseStrictInt(gc.args[1])
+	end, _ := util.ParseStrictInt(gc.args[2])
+
+	from, to, ok := normalizeRange(start, end, int64(len(b)))
+	if !ok {
+		w.BulkString([]byte{})
+		return w.Bytes()
+	}
+
+	w.BulkString(b[from : to+1])
+	return w.Bytes()
+}
+
+// normalizeRange turns inclusive start and end offsets, which count from
+// the end when negative, into valid indexes of a sequence of length n. ok
+// is false when the range is empty
+func normalizeRange(start, end, n int64) (int64, int64, bool) {
+	if start < 0 && end < 0 && start > end {
+		return 0, 0, false
+	}
+
+	if start < 0 {
+		start = n + start
+	}
+	if end < 0 {
+		end = n + end
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end < 0 {
+		end = 0
+	}
+	if end >= n {
+		end = n - 1
+	}
+
+	if n == 0 || start > end {
+		return 0, 0, false
+	}
+
+	return start, end, true
+}
+
+type SetRangeCommand struct {
+	BaseCommand
+}
+
+func NewSetRangeCommand(args [][]byte, flags []*Flag) *SetRangeCommand {
+	return &SetRangeCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+// https://redis.io/docs/latest/commands/setrange/
+func (sc *SetRangeCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("setting range...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := sc.args[0]
+	offset, _ := util.ParseStrictInt(sc.args[1])
+	value := sc.args[2]
+
+	old, exists, err := lookupString(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	var b []byte
+	var expiry time.Time
+	if exists {
+		b, _ = old.Bytes()
+		expiry = old.Expiry()
+	}
+
+	// nothing to write, the key is left untouched
+	if len(value) == 0 {
+		w.Integer(int64(len(b)))
+		return w.Bytes()
+	}
+
+	if err := checkStringLength(rc, offset+int64(len(value))); err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	n := max(int64(len(b)), offset+int64(len(value)))
+	nb := make([]byte, n)
+	copy(nb, b)
+	copy(nb[offset:], value)
+
+	rc.DataStore.Set(key, data.NewStringValue(nb, expiry))
+	signalModifiedKey(rc, key)
+
+	w.Integer(n)
+	return w.Bytes()
+}
+
+type GetDelCommand struct {
+	BaseCommand
+}
+
+func NewGetDelCommand(args [][]byte, flags []*Flag) *GetDelCommand {
+	return &GetDelCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (gc *GetDelCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting and deleting...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := gc.args[0]
+
+	v, exists, err := lookupString(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.Null()
+		return w.Bytes()
+	}
+
+	b, _ := v.Bytes()
+	rc.DataStore.Delete(key)
+	signalModifiedKey(rc, key)
+
+	w.BulkString(b)
+	return w.Bytes()
+}
+
+type GetExCommand struct {
+	BaseCommand
+}
+
+func NewGetExCommand(args [][]byte, flags []*Flag) *GetExCommand {
+	return &GetExCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+// https://redis.io/docs/latest/commands/getex/
+func (gc *GetExCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting and setting expiry...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := gc.args[0]
+
+	v, exists, err := lookupString(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.Null()
+		return w.Bytes()
+	}
+
+	b, _ := v.Bytes()
+	for _, f := range gc.flags {
+		switch f.name {
+		case PERSIST:
+			if !v.Expiry().IsZero() {
+				rc.DataStore.SetExpiry(key, time.Time{})
+				signalModifiedKey(rc, key)
+			}
+		default:
+			// an expiry in the past deletes the key right away
+			now := time.Now()
+			if t := expireAt(f.name, f.value, now); now.After(t) {
+				rc.DataStore.Delete(key)
+			} else {
+				rc.DataStore.SetExpiry(key, t)
+			}
+			signalModifiedKey(rc, key)
+		}
+	}
+
+	w.BulkString(b)
+	return w.Bytes()
+}
+
+type MGetCommand struct {
+	BaseCommand
+}
+
+func NewMGetCommand(args [][]byte, flags []*Flag) *MGetCommand {
+	return &MGetCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (mc *MGetCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting multiple keys...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	w.ArrayLen(len(mc.args))
+	for _, k := range mc.args {
+		// keys holding another type are reported as missing
+		v, exists, err := lookupString(rc, k)
+		if err != nil || !exists {
+			w.Null()
+			continue
+		}
+
+		b, _ := v.Bytes()
+		w.BulkString(b)
+	}
+
+	return w.Bytes()
+}
+
+// MSetCommand implements MSET, and MSETNX or SETNX when the NX flag is set
+type MSetCommand struct {
+	BaseCommand
+}
+
+func NewMSetCommand(args [][]byte, flags []*Flag) *MSetCommand {
+	return &MSetCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (mc *MSetCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("setting multiple keys...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	nx := len(mc.flags) > 0 && mc.flags[0].name == NX
+	if nx {
+		for i := 0; i < len(mc.args); i += 2 {
+			if _, ok := lookupKey(rc, mc.args[i]); ok {
+				w.Integer(0)
+				return w.Bytes()
+			}
+		}
+	}
+
+	for i := 0; i < len(mc.args); i += 2 {
+		rc.DataStore.Set(mc.args[i], data.NewStringValue(mc.args[i+1], time.Time{}))
+		signalModifiedKey(rc, mc.args[i])
+	}
+
+	if nx {
+		w.Integer(1)
+	} else {
+		w.OK()
+	}
+	return w.Bytes()
+}
+
+func parseLcsCmd(args [][]byte) Command {
+	flags := []*Flag{}
+	var length, idx bool
+	for i := 3; i < len(args); i++ {
+		f := strings.ToUpper(string(args[i]))
+		switch f {
+		case LEN:
+			length = true
+			flags = append(flags, NewFlag(f, ""))
+		case IDX:
+			idx = true
+			flags = append(flags, NewFlag(f, ""))
+		case WITHMATCHLEN:
+			flags = append(flags, NewFlag(f, ""))
+		case MINMATCHLEN:
+			if i+1 >= len(args) {
+				return NewErrorCommand(customerror.SyntaxError{})
+			}
+			if _, ok := util.ParseStrictInt(args[i+1]); !ok {
+				return NewErrorCommand(customerror.NotIntegerError{})
+			}
+			flags = append(flags, NewFlag(f, string(args[i+1])))
+			i++
+		default:
+			return NewErrorCommand(customerror.SyntaxError{})
+		}
+	}
+
+	if length && idx {
+		return NewErrorCommand(customerror.LCSLenAndIdxError{})
+	}
+
+	return NewLcsCommand([][]byte{args[1], args[2]}, flags)
+}
+
+type LcsCommand struct {
+	BaseCommand
+}
+
+func NewLcsCommand(args [][]byte, flags []*Flag) *LcsCommand {
+	return &LcsCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+// https://redis.io/docs/latest/commands/lcs/
+func (lc *LcsCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("finding longest common subsequence...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+
+	var length, idx, withMatchLen bool
+	var minMatchLen int64
+	for _, f := range lc.flags {
+		switch f.name {
+		case LEN:
+			length = true
+		case IDX:
+			idx = true
+		case WITHMATCHLEN:
+			withMatchLen = true
+		case MINMATCHLEN:
+			minMatchLen, _ = util.ParseStrictInt([]byte(f.value))
+			minMatchLen = max(minMatchLen, 0)
+		}
+	}
+
+	var strs [2][]byte
+	for i, k := range lc.args {
+		v, exists, err := lookupString(rc, k)
+		if err != nil {
+			w.Error(customerror.LCSNotStringError{})
+			return w.Bytes()
+		}
+		if exists {
+			strs[i], _ = v.Bytes()
+		}
+	}
+	a, b := strs[0], strs[1]
+
+	// the table holds a uint32 for every pair of prefixes
+	if uint64(len(a)+1) >= math.MaxUint32/4/uint64(len(b)+1) {
+		w.Error(customerror.LCSTooLongError{})
+		return w.Bytes()
+	}
+
+	// dp[i][j] is the length of the LCS of a[:i] and b[:j]
+	cols := len(b) + 1
+	dp := make([]uint32, (len(a)+1)*cols)
+	lcs := func(i, j int) uint32 {
+		return dp[i*cols+j]
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				dp[i*cols+j] = lcs(i-1, j-1) + 1
+			} else {
+				dp[i*cols+j] = max(lcs(i-1, j), lcs(i, j-1))
+			}
+		}
+	}
+
+	n := lcs(len(a), len(b))
+	if length {
+		w.Integer(int64(n))
+		return w.Bytes()
+	}
+
+	// walk the table back from the end, collecting the common bytes and
+	// the ranges where they are contiguous in both strings
+	result := make([]byte, n)
+	matches := NewReplyWriter(rc.Client.Protocol())
+	count := 0
+
+	k := int(n)
+	aStart, aEnd, bStart, bEnd := len(a), 0, 0, 0
+	for i, j := len(a), len(b); i > 0 && j > 0; {
+		emit := false
+		if a[i-1] == b[j-1] {
+			result[k-1] = a[i-1]
+
+			if aStart == len(a) {
+				aStart, aEnd = i-1, i-1
+				bStart, bEnd = j-1, j-1
+			} else if aStart == i && bStart == j {
+				// the range is contiguous, extend it backward
+				aStart--
+				bStart--
+			} else {
+				emit = true
+			}
+
+			// nothing can match before the first byte of either string
+			if aStart == 0 || bStart == 0 {
+				emit = true
+			}
+			k--
+			i--
+			j--
+		} else {
+			if lcs(i-1, j) > lcs(i, j-1) {
+				i--
+			} else {
+				j--
+			}
+			if aStart != len(a) {
+				emit = true
+			}
+		}
+
+		if emit {
+			matchLen := int64(aEnd - aStart + 1)
+			if idx && (minMatchLen == 0 || matchLen >= minMatchLen) {
+				if withMatchLen {
+					matches.ArrayLen(3)
+				} else {
+					matches.ArrayLen(2)
+				}
+				matches.ArrayLen(2)
+				matches.Integer(int64(aStart))
+				matches.Integer(int64(aEnd))
+				matches.ArrayLen(2)
+				matches.Integer(int64(bStart))
+				matches.Integer(int64(bEnd))
+				if withMatchLen {
+					matches.Integer(matchLen)
+				}
+				count++
+			}
+			aStart = len(a)
+		}
+	}
+
+	if !idx {
+		w.BulkString(result)
+		return w.Bytes()
+	}
+
+	w.MapLen(2)
+	w.BulkStringString("matches")
+	w.ArrayLen(count)
+	w.Append(matches)
+	w.BulkStringString("len")
+	w.Integer(int64(n))
+	return w.Bytes()
+}
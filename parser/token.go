@@ -16,6 +16,7 @@ const (
 	ATTRIBUTES      = "|"
 	SETS            = "~"
 	PUSH            = ">"
+	NULL            = "_"
 
 	// Redis Commands
 	PING        = "PING"
@@ -26,12 +27,166 @@ const (
 	KEYS        = "KEYS"
 	INFO        = "INFO"
 	REPLICATION = "REPLICATION"
+	STATS       = "STATS"
+	HELLO       = "HELLO"
+	COMMAND     = "COMMAND"
+	CLIENT      = "CLIENT"
+	INCR        = "INCR"
+	DECR        = "DECR"
+	INCRBY      = "INCRBY"
+	DECRBY      = "DECRBY"
+	INCRBYFLOAT = "INCRBYFLOAT"
+
+	// COMMAND SUBCOMMANDS
+	COUNT   = "COUNT"
+	DOCS    = "DOCS"
+	LIST    = "LIST"
+	GETKEYS = "GETKEYS"
+
+	// CLIENT SUBCOMMANDS
+	TRACKING = "TRACKING"
+
+	// CLIENT TRACKING FLAGS
+	ON       = "ON"
+	OFF      = "OFF"
+	REDIRECT = "REDIRECT"
+	PREFIX   = "PREFIX"
+	BCAST    = "BCAST"
+	OPTIN    = "OPTIN"
+	OPTOUT   = "OPTOUT"
+	NOLOOP   = "NOLOOP"
+	YES      = "YES"
+	NO       = "NO"
+
+	TRACKING_CHANNEL = "__redis__:invalidate"
+
+	// COMMAND LIST FILTERS
+	FILTERBY = "FILTERBY"
+	MODULE   = "MODULE"
+	ACLCAT   = "ACLCAT"
+	PATTERN  = "PATTERN"
+
+	// COMMAND FLAGS
+	FLAG_WRITE       = "write"
+	FLAG_READONLY    = "readonly"
+	FLAG_DENYOOM     = "denyoom"
+	FLAG_ADMIN       = "admin"
+	FLAG_PUBSUB      = "pubsub"
+	FLAG_NOSCRIPT    = "noscript"
+	FLAG_BLOCKING    = "blocking"
+	FLAG_LOADING     = "loading"
+	FLAG_STALE       = "stale"
+	FLAG_FAST        = "fast"
+	FLAG_NO_AUTH     = "no_auth"
+	FLAG_ALLOW_BUSY  = "allow_busy"
+	FLAG_MOVABLEKEYS = "movablekeys"
+
+	// ACL CATEGORIES
+	ACL_KEYSPACE   = "@keyspace"
+	ACL_READ       = "@read"
+	ACL_WRITE      = "@write"
+	ACL_STRING     = "@string"
+	ACL_ADMIN      = "@admin"
+	ACL_FAST       = "@fast"
+	ACL_SLOW       = "@slow"
+	ACL_DANGEROUS  = "@dangerous"
+	ACL_CONNECTION = "@connection"
+	ACL_PUBSUB     = "@pubsub"
+	ACL_BITMAP     = "@bitmap"
+	ACL_LIST       = "@list"
+	ACL_HASH       = "@hash"
+	ACL_SET        = "@set"
+	ACL_SORTEDSET  = "@sortedset"
+	ACL_BLOCKING   = "@blocking"
 
 	// SET COMMAND FLAGS
-	PX = "PX"
+	EX      = "EX"
+	PX      = "PX"
+	EXAT    = "EXAT"
+	PXAT    = "PXAT"
+	NX      = "NX"
+	XX      = "XX"
+	KEEPTTL = "KEEPTTL"
+
+	// SCAN COMMAND FLAGS
+	MATCH    = "MATCH"
+	TYPE     = "TYPE"
+	NOVALUES = "NOVALUES"
+	NOSCORES = "NOSCORES"
+
+	// HASH COMMAND FLAGS
+	WITHVALUES = "WITHVALUES"
+	FIELDS     = "FIELDS"
+	FNX        = "FNX"
+	FXX        = "FXX"
+
+	// SET TYPE COMMAND FLAGS
+	INTER = "INTER"
+	UNION = "UNION"
+	STORE = "STORE"
+	LIMIT = "LIMIT"
+
+	// SORTED SET COMMAND FLAGS
+	CH         = "CH"
+	BYSCORE    = "BYSCORE"
+	BYLEX      = "BYLEX"
+	REV        = "REV"
+	WITHSCORES = "WITHSCORES"
+	WITHSCORE  = "WITHSCORE"
+
+	// LIST COMMAND FLAGS
+	LEFT   = "LEFT"
+	RIGHT  = "RIGHT"
+	BEFORE = "BEFORE"
+	AFTER  = "AFTER"
+	RANK   = "RANK"
+	MAXLEN = "MAXLEN"
+
+	// EXPIRE COMMAND FLAGS
+	GT = "GT"
+	LT = "LT"
+
+	// GETEX COMMAND FLAGS
+	PERSIST = "PERSIST"
+
+	// BITMAP COMMAND FLAGS
+	BYTE     = "BYTE"
+	BIT      = "BIT"
+	AND      = "AND"
+	OR       = "OR"
+	XOR      = "XOR"
+	NOT      = "NOT"
+	DIFF     = "DIFF"
+	DIFF1    = "DIFF1"
+	ANDOR    = "ANDOR"
+	ONE      = "ONE"
+	OVERFLOW = "OVERFLOW"
+	WRAP     = "WRAP"
+	SAT      = "SAT"
+	FAIL     = "FAIL"
+
+	// COPY COMMAND FLAGS
+	DB      = "DB"
+	REPLACE = "REPLACE"
+
+	// LCS COMMAND FLAGS
+	LEN          = "LEN"
+	IDX          = "IDX"
+	MINMATCHLEN  = "MINMATCHLEN"
+	WITHMATCHLEN = "WITHMATCHLEN"
+
+	// HELLO COMMAND FLAGS
+	AUTH    = "AUTH"
+	SETNAME = "SETNAME"
+
+	SERVER_NAME    = "redis"
+	SERVER_VERSION = "7.4.0"
+	DEFAULT_USER   = "default"
 
 	REDIS_TERMINATOR = "\r\n"
 	PONG             = SIMPLE_STRING + "PONG" + REDIS_TERMINATOR
 	OK               = SIMPLE_STRING + "OK" + REDIS_TERMINATOR
 	NULL_BULK_STRING = BULK_STRING + "-1" + REDIS_TERMINATOR
+	NULL_ARRAY       = ARRAY + "-1" + REDIS_TERMINATOR
+	RESP3_NULL       = NULL + REDIS_TERMINATOR
 )
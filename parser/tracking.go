@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"github.com/JanitSri/codecrafters-build-your-own-redis/data"
+)
+
+// rememberKeys records the keys read by a readonly command so the client
+// is told when they change, only used by the default tracking mode
+//
+// https://redis.io/docs/latest/develop/reference/client-side-caching/
+func rememberKeys(rc *data.RedisContext, cs *CommandSpec, args [][]byte) {
+	t := rc.Client.Tracking()
+	if !t.Enabled || t.Bcast {
+		return
+	}
+
+	caching := rc.Client.Caching()
+	if (t.OptIn && !caching) || (t.OptOut && caching) {
+		return
+	}
+
+	for _, p := range cs.keyPositions(args) {
+		rc.Tracking.RememberKey(args[p], rc.Client.Id())
+	}
+}
+
+// signalModifiedKey invalidates the key for every client that may have it
+// cached and wakes up the clients blocked on it, every command that
+// modifies a key must call it
+func signalModifiedKey(rc *data.RedisContext, key []byte) {
+	rc.Blocking.SignalReady(key)
+
+	var origin int64
+	if rc.Client != nil {
+		origin = rc.Client.Id()
+	}
+
+	for _, id := range rc.Tracking.PrefixSubscribers(key) {
+		sendInvalidation(rc, id, origin, true, [][]byte{key})
+	}
+
+	for _, id := range rc.Tracking.TakeKey(key) {
+		sendInvalidation(rc, id, origin, false, [][]byte{key})
+	}
+}
+
+// sendInvalidation delivers an invalidate message to the client, or to the
+// client it redirects to. RESP3 clients get a push message, RESP2 clients
+// can only be reached through a redirect client subscribed to the
+// __redis__:invalidate channel
+func sendInvalidation(rc *data.RedisContext, id, origin int64, bcast bool, keys [][]byte) {
+	c, ok := rc.Clients.Get(id)
+	if !ok {
+		return
+	}
+
+	t := c.Tracking()
+	if !t.Enabled || t.Bcast != bcast || (t.NoLoop && id == origin) {
+		return
+	}
+
+	target := c
+	if t.Redirect != 0 {
+		target, ok = rc.Clients.Get(t.Redirect)
+		if !ok {
+			sendRedirectBroken(c, t.Redirect)
+			return
+		}
+	}
+
+	w := NewReplyWriter(target.Protocol())
+	switch {
+	case target.Protocol() == data.RESP3:
+		w.PushLen(2)
+		w.BulkStringString("invalidate")
+	case t.Redirect != 0 && target.IsSubscribed([]byte(TRACKING_CHANNEL)):
+		w.PushLen(3)
+		w.BulkStringString("message")
+		w.BulkStringString(TRACKING_CHANNEL)
+	default:
+		return
+	}
+
+	if keys == nil {
+		w.NullArray()
+	} else {
+		w.ArrayLen(len(keys))
+		for _, k := range keys {
+			w.BulkString(k)
+		}
+	}
+
+	target.Push(w.Bytes())
+}
+
+// sendRedirectBroken tells a RESP3 client, once, that the client it
+// redirects its invalidations to has disconnected
+func sendRedirectBroken(c *data.RedisClient, redirect int64) {
+	if c.RedirectBroken() {
+		return
+	}
+	c.SetRedirectBroken()
+
+	if c.Protocol() != data.RESP3 {
+		return
+	}
+
+	w := NewReplyWriter(data.RESP3)
+	w.PushLen(2)
+	w.BulkStringString("tracking-redir-broken")
+	w.Integer(redirect)
+	c.Push(w.Bytes())
+}
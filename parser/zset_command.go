@@ -0,0 +1,872 @@
+package parser
+
+import (
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JanitSri/codecrafters-build-your-own-redis/customerror"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/data"
+	"github.com/JanitSri/codecrafters-build-your-own-redis/util"
+)
+
+// https://redis.io/docs/latest/commands/zadd/
+func parseZAddCmd(args [][]byte) Command {
+	flags := []*Flag{}
+	opts := map[string]bool{}
+
+	i := 2
+	for ; i < len(args); i++ {
+		f := strings.ToUpper(string(args[i]))
+		if f != NX && f != XX && f != GT && f != LT && f != CH && f != INCR {
+			break
+		}
+		flags = append(flags, NewFlag(f, ""))
+		opts[f] = true
+	}
+
+	pairs := args[i:]
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		return NewErrorCommand(customerror.SyntaxError{})
+	}
+
+	if opts[NX] && opts[XX] {
+		return NewErrorCommand(customerror.XXAndNXError{})
+	}
+
+	if (opts[GT] && opts[NX]) || (opts[LT] && opts[NX]) || (opts[GT] && opts[LT]) {
+		return NewErrorCommand(customerror.GTLTAndNXError{})
+	}
+
+	if opts[INCR] && len(pairs) > 2 {
+		return NewErrorCommand(customerror.IncrPairError{})
+	}
+
+	for j := 0; j < len(pairs); j += 2 {
+		if _, ok := parseFloat(pairs[j]); !ok {
+			return NewErrorCommand(customerror.NotFloatError{})
+		}
+	}
+
+	return NewZAddCommand(append([][]byte{args[1]}, pairs...), flags)
+}
+
+func parseZIncrByCmd(args [][]byte) Command {
+	if _, ok := parseFloat(args[2]); !ok {
+		return NewErrorCommand(customerror.NotFloatError{})
+	}
+
+	return NewZAddCommand(args[1:4], []*Flag{NewFlag(INCR, "")})
+}
+
+func parseZRemCmd(args [][]byte) Command {
+	return NewZRemCommand(args[1:], nil)
+}
+
+func parseZScoreCmd(args [][]byte) Command {
+	return NewZScoreCommand(args[1:3], nil)
+}
+
+func parseZMScoreCmd(args [][]byte) Command {
+	return NewZMScoreCommand(args[1:], nil)
+}
+
+func parseZCardCmd(args [][]byte) Command {
+	return NewZCardCommand([][]byte{args[1]}, nil)
+}
+
+func parseZCountCmd(args [][]byte) Command {
+	if _, ok := parseScoreRange(args[2], args[3]); !ok {
+		return NewErrorCommand(customerror.MinMaxNotFloatError{})
+	}
+
+	return NewZCountCommand(args[1:4], []*Flag{NewFlag(BYSCORE, "")})
+}
+
+func parseZLexCountCmd(args [][]byte) Command {
+	if _, ok := parseLexRange(args[2], args[3]); !ok {
+		return NewErrorCommand(customerror.MinMaxNotLexError{})
+	}
+
+	return NewZCountCommand(args[1:4], []*Flag{NewFlag(BYLEX, "")})
+}
+
+func parseZRankCmd(args [][]byte) Command {
+	return parseZRankGenericCmd(args, false)
+}
+
+func parseZRevRankCmd(args [][]byte) Command {
+	return parseZRankGenericCmd(args, true)
+}
+
+// https://redis.io/docs/latest/commands/zrank/
+func parseZRankGenericCmd(args [][]byte, rev bool) Command {
+	if len(args) > 4 {
+		return NewErrorCommand(customerror.SyntaxError{})
+	}
+
+	flags := []*Flag{}
+	if rev {
+		flags = append(flags, NewFlag(REV, ""))
+	}
+
+	if len(args) == 4 {
+		if strings.ToUpper(string(args[3])) != WITHSCORE {
+			return NewErrorCommand(customerror.SyntaxError{})
+		}
+		flags = append(flags, NewFlag(WITHSCORE, ""))
+	}
+
+	return NewZRankCommand(args[1:3], flags)
+}
+
+func parseZRangeCmd(args [][]byte) Command {
+	return parseZRangeGenericCmd(args, "", false)
+}
+
+func parseZRevRangeCmd(args [][]byte) Command {
+	return parseZRangeGenericCmd(args, RANK, true)
+}
+
+func parseZRangeByScoreCmd(args [][]byte) Command {
+	return parseZRangeGenericCmd(args, BYSCORE, false)
+}
+
+func parseZRevRangeByScoreCmd(args [][]byte) Command {
+	return parseZRangeGenericCmd(args, BYSCORE, true)
+}
+
+func parseZRangeByLexCmd(args [][]byte) Command {
+	return parseZRangeGenericCmd(args, BYLEX, false)
+}
+
+func parseZRevRangeByLexCmd(args [][]byte) Command {
+	return parseZRangeGenericCmd(args, BYLEX, true)
+}
+
+// parseZRangeGenericCmd parses ZRANGE and the older commands it replaces,
+// by and rev are what those commands imply, ZRANGE passes an empty by and
+// takes them as options instead
+//
+// https://redis.io/docs/latest/commands/zrange/
+func parseZRangeGenericCmd(args [][]byte, by string, rev bool) Command {
+	auto := by == ""
+	withScores := false
+	var limit []*Flag
+
+	for i := 4; i < len(args); i++ {
+		f := strings.ToUpper(string(args[i]))
+		switch {
+		case f == WITHSCORES:
+			withScores = true
+		case f == LIMIT && len(args)-i-1 >= 2:
+			if !areIntegers(args[i+1 : i+3]) {
+				return NewErrorCommand(customerror.NotIntegerError{})
+			}
+			limit = []*Flag{NewFlag(LIMIT, string(args[i+1])), NewFlag(COUNT, string(args[i+2]))}
+			i += 2
+		case f == REV && auto && !rev:
+			rev = true
+		case (f == BYSCORE || f == BYLEX) && by == "":
+			by = f
+		default:
+			return NewErrorCommand(customerror.SyntaxError{})
+		}
+	}
+
+	if by == "" {
+		by = RANK
+	}
+
+	if limit != nil && by == RANK {
+		return NewErrorCommand(customerror.ZRangeLimitError{})
+	}
+
+	if withScores && by == BYLEX {
+		return NewErrorCommand(customerror.ZRangeWithScoresError{})
+	}
+
+	// a reversed range of scores or members is given as max then min
+	min, max := args[2], args[3]
+	if rev && by != RANK {
+		min, max = max, min
+	}
+
+	switch by {
+	case RANK:
+		if !areIntegers([][]byte{min, max}) {
+			return NewErrorCommand(customerror.NotIntegerError{})
+		}
+	case BYSCORE:
+		if _, ok := parseScoreRange(min, max); !ok {
+			return NewErrorCommand(customerror.MinMaxNotFloatError{})
+		}
+	case BYLEX:
+		if _, ok := parseLexRange(min, max); !ok {
+			return NewErrorCommand(customerror.MinMaxNotLexError{})
+		}
+	}
+
+	flags := []*Flag{NewFlag(by, "")}
+	if rev {
+		flags = append(flags, NewFlag(REV, ""))
+	}
+	if withScores {
+		flags = append(flags, NewFlag(WITHSCORES, ""))
+	}
+	flags = append(flags, limit...)
+
+	return NewZRangeCommand([][]byte{args[1], min, max}, flags)
+}
+
+func parseZRemRangeByRankCmd(args [][]byte) Command {
+	if !areIntegers(args[2:4]) {
+		return NewErrorCommand(customerror.NotIntegerError{})
+	}
+
+	return NewZRemRangeCommand(args[1:4], []*Flag{NewFlag(RANK, "")})
+}
+
+func parseZRemRangeByScoreCmd(args [][]byte) Command {
+	if _, ok := parseScoreRange(args[2], args[3]); !ok {
+		return NewErrorCommand(customerror.MinMaxNotFloatError{})
+	}
+
+	return NewZRemRangeCommand(args[1:4], []*Flag{NewFlag(BYSCORE, "")})
+}
+
+func parseZRemRangeByLexCmd(args [][]byte) Command {
+	if _, ok := parseLexRange(args[2], args[3]); !ok {
+		return NewErrorCommand(customerror.MinMaxNotLexError{})
+	}
+
+	return NewZRemRangeCommand(args[1:4], []*Flag{NewFlag(BYLEX, "")})
+}
+
+// parseScoreRange parses the min and max of a range of scores, an end
+// starting with ( is exclusive
+func parseScoreRange(min, max []byte) (data.ScoreRange, bool) {
+	var r data.ScoreRange
+	var ok bool
+	if r.Min, r.MinEx, ok = parseScoreBound(min); !ok {
+		return r, false
+	}
+
+	r.Max, r.MaxEx, ok = parseScoreBound(max)
+	return r, ok
+}
+
+func parseScoreBound(b []byte) (float64, bool, bool) {
+	exclusive := len(b) > 0 && b[0] == '('
+	if exclusive {
+		b = b[1:]
+	}
+
+	f, ok := parseFloat(b)
+	return f, exclusive, ok
+}
+
+// parseLexRange parses the min and max of a range of members, an end is
+// either - or +, or a member starting with ( when it is exclusive or [
+// when it is inclusive
+func parseLexRange(min, max []byte) (data.LexRange, bool) {
+	var r data.LexRange
+	var ok bool
+	if r.Min, ok = parseLexBound(min); !ok {
+		return r, false
+	}
+
+	r.Max, ok = parseLexBound(max)
+	return r, ok
+}
+
+func parseLexBound(b []byte) (data.LexBound, bool) {
+	if len(b) == 0 {
+		return data.LexBound{}, false
+	}
+
+	switch b[0] {
+	case '-', '+':
+		if len(b) != 1 {
+			return data.LexBound{}, false
+		}
+
+		inf := 1
+		if b[0] == '-' {
+			inf = -1
+		}
+		return data.LexBound{Exclusive: true, Inf: inf}, true
+	case '(', '[':
+		return data.LexBound{Value: b[1:], Exclusive: b[0] == '('}, true
+	}
+
+	return data.LexBound{}, false
+}
+
+// lookupZSet returns the sorted set stored at key, exists is false when the
+// key is missing or expired
+func lookupZSet(rc *data.RedisContext, key []byte) (*data.ZSet, bool, error) {
+	v, ok := lookupKey(rc, key)
+	if !ok {
+		return nil, false, nil
+	}
+
+	z, isZSet := v.Value().(*data.ZSet)
+	if !isZSet {
+		return nil, true, customerror.WrongTypeError{}
+	}
+
+	return z, true, nil
+}
+
+// zsetMembersChanged is called once a command removed members of a sorted
+// set, a sorted set left without members is deleted
+func zsetMembersChanged(rc *data.RedisContext, key []byte, z *data.ZSet) {
+	if z.Len() == 0 {
+		rc.DataStore.Delete(key)
+	}
+
+	signalModifiedKey(rc, key)
+}
+
+// ZAddCommand implements ZADD, and ZINCRBY which is ZADD with the INCR flag
+type ZAddCommand struct {
+	BaseCommand
+}
+
+func NewZAddCommand(args [][]byte, flags []*Flag) *ZAddCommand {
+	return &ZAddCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (zc *ZAddCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("adding sorted set members...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := zc.args[0]
+
+	var nx, xx, gt, lt, ch, incr bool
+	for _, f := range zc.flags {
+		switch f.name {
+		case NX:
+			nx = true
+		case XX:
+			xx = true
+		case GT:
+			gt = true
+		case LT:
+			lt = true
+		case CH:
+			ch = true
+		case INCR:
+			incr = true
+		}
+	}
+
+	z, exists, err := lookupZSet(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	// XX never creates the key
+	if !exists && xx {
+		if incr {
+			w.Null()
+		} else {
+			w.Integer(0)
+		}
+		return w.Bytes()
+	}
+
+	if !exists {
+		z = data.NewZSet()
+		rc.DataStore.Set(key, data.NewRedisValue(z, time.Time{}))
+	}
+
+	var added, updated int
+	var score float64
+	processed := false
+	for i := 1; i < len(zc.args); i += 2 {
+		score, _ = parseFloat(zc.args[i])
+		m := zc.args[i+1]
+
+		cur, ok := z.Score(m)
+		if !ok {
+			if xx {
+				continue
+			}
+
+			z.Add(m, score)
+			added++
+			processed = true
+			continue
+		}
+
+		if nx {
+			continue
+		}
+
+		if incr {
+			score += cur
+			if math.IsNaN(score) {
+				w.Error(customerror.ScoreNaNError{})
+				return w.Bytes()
+			}
+		}
+
+		if (gt && score <= cur) || (lt && score >= cur) {
+			continue
+		}
+
+		processed = true
+		if score != cur {
+			z.Add(m, score)
+			updated++
+		}
+	}
+
+	if added+updated > 0 {
+		signalModifiedKey(rc, key)
+	}
+
+	if incr {
+		if processed {
+			w.Double(score)
+		} else {
+			w.Null()
+		}
+		return w.Bytes()
+	}
+
+	if ch {
+		added += updated
+	}
+	w.Integer(int64(added))
+	return w.Bytes()
+}
+
+type ZRemCommand struct {
+	BaseCommand
+}
+
+func NewZRemCommand(args [][]byte, flags []*Flag) *ZRemCommand {
+	return &ZRemCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (zc *ZRemCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("removing sorted set members...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := zc.args[0]
+	z, exists, err := lookupZSet(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	removed := 0
+	for _, m := range zc.args[1:] {
+		if z.Remove(m) {
+			removed++
+		}
+	}
+
+	if removed > 0 {
+		zsetMembersChanged(rc, key, z)
+	}
+
+	w.Integer(int64(removed))
+	return w.Bytes()
+}
+
+type ZScoreCommand struct {
+	BaseCommand
+}
+
+func NewZScoreCommand(args [][]byte, flags []*Flag) *ZScoreCommand {
+	return &ZScoreCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (zc *ZScoreCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting sorted set score...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	z, exists, err := lookupZSet(rc, zc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if exists {
+		if score, ok := z.Score(zc.args[1]); ok {
+			w.Double(score)
+			return w.Bytes()
+		}
+	}
+
+	w.Null()
+	return w.Bytes()
+}
+
+type ZMScoreCommand struct {
+	BaseCommand
+}
+
+func NewZMScoreCommand(args [][]byte, flags []*Flag) *ZMScoreCommand {
+	return &ZMScoreCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (zc *ZMScoreCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting sorted set scores...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	z, exists, err := lookupZSet(rc, zc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	members := zc.args[1:]
+	w.ArrayLen(len(members))
+	for _, m := range members {
+		if !exists {
+			w.Null()
+			continue
+		}
+
+		score, ok := z.Score(m)
+		if !ok {
+			w.Null()
+			continue
+		}
+		w.Double(score)
+	}
+
+	return w.Bytes()
+}
+
+type ZCardCommand struct {
+	BaseCommand
+}
+
+func NewZCardCommand(args [][]byte, flags []*Flag) *ZCardCommand {
+	return &ZCardCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (zc *ZCardCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting sorted set cardinality...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	z, exists, err := lookupZSet(rc, zc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	w.Integer(int64(z.Len()))
+	return w.Bytes()
+}
+
+// ZCountCommand implements ZCOUNT with the BYSCORE flag and ZLEXCOUNT with
+// the BYLEX flag
+type ZCountCommand struct {
+	BaseCommand
+}
+
+func NewZCountCommand(args [][]byte, flags []*Flag) *ZCountCommand {
+	return &ZCountCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (zc *ZCountCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("counting sorted set range...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	z, exists, err := lookupZSet(rc, zc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	var n int
+	if zc.flags[0].name == BYSCORE {
+		r, _ := parseScoreRange(zc.args[1], zc.args[2])
+		n = z.CountByScore(r)
+	} else {
+		r, _ := parseLexRange(zc.args[1], zc.args[2])
+		n = z.CountByLex(r)
+	}
+
+	w.Integer(int64(n))
+	return w.Bytes()
+}
+
+// ZRankCommand implements ZRANK, and ZREVRANK when the REV flag is set
+type ZRankCommand struct {
+	BaseCommand
+}
+
+func NewZRankCommand(args [][]byte, flags []*Flag) *ZRankCommand {
+	return &ZRankCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (zc *ZRankCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting sorted set rank...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	var rev, withScore bool
+	for _, f := range zc.flags {
+		switch f.name {
+		case REV:
+			rev = true
+		case WITHSCORE:
+			withScore = true
+		}
+	}
+
+	z, exists, err := lookupZSet(rc, zc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	var rank int
+	found := false
+	if exists {
+		rank, found = z.Rank(zc.args[1], rev)
+	}
+
+	if !found {
+		if withScore {
+			w.NullArray()
+		} else {
+			w.Null()
+		}
+		return w.Bytes()
+	}
+
+	if !withScore {
+		w.Integer(int64(rank))
+		return w.Bytes()
+	}
+
+	score, _ := z.Score(zc.args[1])
+	w.ArrayLen(2)
+	w.Integer(int64(rank))
+	w.Double(score)
+	return w.Bytes()
+}
+
+// ZRangeCommand implements ZRANGE and the commands it replaces, the first
+// flag is the type of range, RANK, BYSCORE or BYLEX. The LIMIT flag holds
+// the offset and the COUNT flag that follows it the count
+type ZRangeCommand struct {
+	BaseCommand
+}
+
+func NewZRangeCommand(args [][]byte, flags []*Flag) *ZRangeCommand {
+	return &ZRangeCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (zc *ZRangeCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("getting sorted set range...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	by := zc.flags[0].name
+	var rev, withScores bool
+	var offset, count int64 = 0, -1
+	for _, f := range zc.flags[1:] {
+		switch f.name {
+		case REV:
+			rev = true
+		case WITHSCORES:
+			withScores = true
+		case LIMIT:
+			offset, _ = strconv.ParseInt(f.value, 10, 64)
+		case COUNT:
+			count, _ = strconv.ParseInt(f.value, 10, 64)
+		}
+	}
+
+	z, exists, err := lookupZSet(rc, zc.args[0])
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.ArrayLen(0)
+		return w.Bytes()
+	}
+
+	var members [][]byte
+	var scores []float64
+	collect := func(member []byte, score float64) bool {
+		members = append(members, member)
+		scores = append(scores, score)
+		return true
+	}
+
+	// LIMIT skips offset members and then stops after count of them, a
+	// negative count has no limit and a negative offset matches nothing
+	limited := func(member []byte, score float64) bool {
+		if offset > 0 {
+			offset--
+			return true
+		}
+		if count == 0 {
+			return false
+		}
+		count--
+		return collect(member, score)
+	}
+
+	switch by {
+	case RANK:
+		start, _ := util.ParseStrictInt(zc.args[1])
+		stop, _ := util.ParseStrictInt(zc.args[2])
+		if from, to, ok := listRange(start, stop, z.Len()); ok {
+			z.RangeByRank(from, to, rev, collect)
+		}
+	case BYSCORE:
+		r, _ := parseScoreRange(zc.args[1], zc.args[2])
+		if offset >= 0 {
+			z.RangeByScore(r, rev, limited)
+		}
+	case BYLEX:
+		r, _ := parseLexRange(zc.args[1], zc.args[2])
+		if offset >= 0 {
+			z.RangeByLex(r, rev, limited)
+		}
+	}
+
+	// RESP3 replies each member with its score as a pair
+	n := len(members)
+	if withScores && w.Protocol() != data.RESP3 {
+		n *= 2
+	}
+	w.ArrayLen(n)
+
+	for i, m := range members {
+		if withScores && w.Protocol() == data.RESP3 {
+			w.ArrayLen(2)
+		}
+		w.BulkString(m)
+		if withScores {
+			w.Double(scores[i])
+		}
+	}
+
+	return w.Bytes()
+}
+
+// ZRemRangeCommand implements ZREMRANGEBYRANK, ZREMRANGEBYSCORE and
+// ZREMRANGEBYLEX, the flag is the type of range
+type ZRemRangeCommand struct {
+	BaseCommand
+}
+
+func NewZRemRangeCommand(args [][]byte, flags []*Flag) *ZRemRangeCommand {
+	return &ZRemRangeCommand{
+		BaseCommand{
+			args,
+			flags,
+		},
+	}
+}
+
+func (zc *ZRemRangeCommand) Execute(rc *data.RedisContext) []byte {
+	log.Println("removing sorted set range...")
+
+	w := NewReplyWriter(rc.Client.Protocol())
+	key := zc.args[0]
+	z, exists, err := lookupZSet(rc, key)
+	if err != nil {
+		w.Error(err)
+		return w.Bytes()
+	}
+
+	if !exists {
+		w.Integer(0)
+		return w.Bytes()
+	}
+
+	var removed int
+	switch zc.flags[0].name {
+	case RANK:
+		start, _ := util.ParseStrictInt(zc.args[1])
+		stop, _ := util.ParseStrictInt(zc.args[2])
+		if from, to, ok := listRange(start, stop, z.Len()); ok {
+			removed = z.RemoveRangeByRank(from, to)
+		}
+	case BYSCORE:
+		r, _ := parseScoreRange(zc.args[1], zc.args[2])
+		removed = z.RemoveRangeByScore(r)
+	case BYLEX:
+		r, _ := parseLexRange(zc.args[1], zc.args[2])
+		removed = z.RemoveRangeByLex(r)
+	}
+
+	if removed > 0 {
+		zsetMembersChanged(rc, key, z)
+	}
+
+	w.Integer(int64(removed))
+	return w.Bytes()
+}
@@ -1,16 +1,19 @@
 package redis
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
 	"net"
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/JanitSri/codecrafters-build-your-own-redis/data"
 	"github.com/JanitSri/codecrafters-build-your-own-redis/parser"
@@ -35,6 +38,12 @@ type RedisServer struct {
 	id string
 	ServerConfig
 	RedisContext *data.RedisContext
+
+	// open connections, they are only closed from here when the server
+	// shuts down so scanners blocked on a read return
+	mu           sync.Mutex
+	conns        map[net.Conn]struct{}
+	shuttingDown bool
 }
 
 func NewRedisServer(sc ServerConfig, rc data.RedisConfig, ri *data.RedisInfo) *RedisServer {
@@ -45,6 +54,7 @@ func NewRedisServer(sc ServerConfig, rc data.RedisConfig, ri *data.RedisInfo) *R
 		ServerConfig: sc,
 		RedisContext: data.NewRedisContext(ri, rs),
 		id:           id,
+		conns:        map[net.Conn]struct{}{},
 	}
 }
 
@@ -56,6 +66,8 @@ func (rs *RedisServer) Run(ctx context.Context) {
 
 	doneChan := make(chan any)
 
+	go rs.serverCron(ctx)
+
 	go func(ln net.Listener) {
 		defer close(doneChan)
 
@@ -80,28 +92,111 @@ func (rs *RedisServer) Run(ctx context.Context) {
 
 	ln.Close()
 
+	// disconnecting the clients releases the ones in blocking commands and
+	// closing the connections stops the scanners waiting for requests
+	for _, c := range rs.RedisContext.Clients.All() {
+		c.Close()
+	}
+
+	rs.mu.Lock()
+	rs.shuttingDown = true
+	for conn := range rs.conns {
+		conn.Close()
+	}
+	rs.mu.Unlock()
+
 	<-doneChan
 }
 
+// serverCron runs the background tasks hz times per second until the
+// server shuts down, hz is read again on every tick
+func (rs *RedisServer) serverCron(ctx context.Context) {
+	ae := parser.NewActiveExpire()
+	for {
+		hz := rs.RedisContext.DataStore.Config().Hz()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second / time.Duration(hz)):
+			ae.Cycle(rs.RedisContext)
+		}
+	}
+}
+
+// pendingCommands is how many parsed requests may queue up ahead of the one
+// being executed, pipelined replies are batched while requests are queued
+const pendingCommands = 128
+
+// maxPendingReplies is how many bytes of replies may be batched before they
+// are written even though more requests are queued, the output buffer is
+// sized so a batch never spills into partial writes
+const (
+	maxPendingReplies = 16 * 1024
+	replyBufferSize   = 4 * maxPendingReplies
+)
+
 func (rs *RedisServer) handleConnections(conn net.Conn) {
 	defer conn.Close()
 	log.Printf("%s handling connection from %s\n", rs.id, conn.RemoteAddr().String())
-	c := make(chan parser.Command, 10)
-	sc := parser.NewRedisScanner(conn, c)
+	rs.addConn(conn)
+	defer rs.removeConn(conn)
+	c := make(chan parser.Command, pendingCommands)
+	sc := parser.NewRedisScanner(conn, c, rs.RedisContext.DataStore.Config())
+	cl := data.NewRedisClient()
+	rs.RedisContext.Clients.Add(cl)
+	defer rs.RedisContext.RemoveClient(cl)
+	rc := rs.RedisContext.ForClient(cl)
 
 	var wg sync.WaitGroup
 	wg.Add(2)
 
+	// the client is marked closed once the scanner stops so a blocking
+	// command it sent stops waiting, the connection itself stays open until
+	// every queued request is answered and the replies are flushed
 	go func() {
 		defer wg.Done()
 		sc.Scan()
+		cl.Close()
 	}()
 
 	go func() {
 		defer wg.Done()
-		for cmd := range c {
-			b := cmd.Execute(rs.RedisContext)
-			conn.Write(b)
+		w := bufio.NewWriterSize(conn, replyBufferSize)
+		flush := func() {
+			if err := w.Flush(); err != nil {
+				log.Printf("%s error writing to %s: %v\n", rs.id, conn.RemoteAddr().String(), err)
+			}
+		}
+
+		// replies of the requests served before a blocking command are not
+		// held back while it waits
+		cl.SetBeforeBlock(flush)
+
+		for {
+			select {
+			case cmd, ok := <-c:
+				if !ok {
+					return
+				}
+				rc.Lock()
+				b := cmd.Execute(rc)
+				rc.Unlock()
+				w.Write(b)
+			case <-cl.Pushed():
+				// push messages queued by other connections, such as
+				// invalidations, are written between replies
+				for _, p := range cl.TakePushes() {
+					w.Write(p)
+				}
+			}
+
+			// replies stay in the output buffer while there are more
+			// requests to serve, once every queued request has a reply
+			// they are sent with a single write. A client that keeps the
+			// queue full still gets its replies once enough are batched
+			if len(c) == 0 || w.Buffered() > maxPendingReplies {
+				flush()
+			}
 		}
 	}()
 
@@ -109,6 +204,24 @@ func (rs *RedisServer) handleConnections(conn net.Conn) {
 	log.Printf("%s closing connection for %s\n", rs.id, conn.RemoteAddr().String())
 }
 
+// addConn registers a connection, one accepted while the server is
+// shutting down is closed right away
+func (rs *RedisServer) addConn(conn net.Conn) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.shuttingDown {
+		conn.Close()
+		return
+	}
+	rs.conns[conn] = struct{}{}
+}
+
+func (rs *RedisServer) removeConn(conn net.Conn) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	delete(rs.conns, conn)
+}
+
 func (rs *RedisServer) StartupTasks() {
 	rs.displayBanner()
 	rs.loadRDBFile()
@@ -142,29 +255,54 @@ func (rs *RedisServer) displayBanner() {
 func (rs *RedisServer) loadRDBFile() {
 	log.Println("Loading RDB file...")
 
-	dir := strings.TrimSpace(rs.RedisContext.DataStore.GetConfig("dir"))
-	fn := strings.TrimSpace(rs.RedisContext.DataStore.GetConfig("dbfilename"))
+	dir, _ := rs.RedisContext.DataStore.GetConfig("dir")
+	fn, _ := rs.RedisContext.DataStore.GetConfig("dbfilename")
+	dir = strings.TrimSpace(dir)
+	fn = strings.TrimSpace(fn)
 
-	fd, err := os.OpenRoot(dir)
-	if err != nil {
+	if dir == "" || fn == "" {
+		log.Printf("%s no RDB file configured, starting with an empty dataset\n", rs.id)
 		return
 	}
-	defer fd.Close()
 
-	f, err := fd.Open(fn)
-	if err != nil {
+	bd, err := readRDBFile(dir, fn)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("%s %v, starting with an empty dataset\n", rs.id, err)
 		return
 	}
-	defer f.Close()
 
-	bd, err := io.ReadAll(f)
-	if err != nil {
-		return
+	var pairs map[string]*data.RedisValue
+	if err == nil {
+		pairs, err = parser.ParseRBDFile(bd)
 	}
 
-	pairs := parser.ParseRBDFile(bd)
+	if err != nil {
+		if rs.RedisContext.DataStore.Config().RDBErrorPolicy() == data.RDB_ERROR_POLICY_SKIP {
+			log.Printf("%s failed loading %s: %v, skipping it and starting with an empty dataset\n", rs.id, fn, err)
+			return
+		}
+		log.Fatalf("%s failed loading %s: %v\n", rs.id, fn, err)
+	}
 
 	for k, v := range pairs {
-		rs.RedisContext.DataStore.Set(k, v)
+		rs.RedisContext.DataStore.Set([]byte(k), v)
 	}
+
+	log.Printf("%s loaded %d keys from %s\n", rs.id, len(pairs), fn)
+}
+
+func readRDBFile(dir, fn string) ([]byte, error) {
+	fd, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	f, err := fd.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(f)
 }
@@ -0,0 +1,140 @@
+package util
+
+// globMaxNesting bounds the recursion of patterns with many stars, such as
+// a*a*a*a*...b, which would otherwise take exponential time
+const globMaxNesting = 1000
+
+// GlobMatch reports whether s matches the glob-style pattern the way Redis
+// matches patterns in KEYS, SCAN and PSUBSCRIBE. It supports *, ?, [abc],
+// [^abc], [a-z] and \ to escape the next character
+//
+// https://github.com/redis/redis/blob/unstable/src/util.c
+func GlobMatch(pattern, s []byte, nocase bool) bool {
+	skipLongerMatches := false
+	return globMatch(pattern, s, nocase, &skipLongerMatches, 0)
+}
+
+func globMatch(p, s []byte, nocase bool, skipLongerMatches *bool, nesting int) bool {
+	if nesting > globMaxNesting {
+		return false
+	}
+
+	for len(p) > 0 && len(s) > 0 {
+		switch p[0] {
+		case '*':
+			for len(p) > 1 && p[1] == '*' {
+				p = p[1:]
+			}
+			if len(p) == 1 {
+				return true
+			}
+
+			for len(s) > 0 {
+				if globMatch(p[1:], s, nocase, skipLongerMatches, nesting+1) {
+					return true
+				}
+				// the rest of the pattern failed against a suffix of s,
+				// any longer match of this star is bound to fail as well
+				if *skipLongerMatches {
+					return false
+				}
+				s = s[1:]
+			}
+			*skipLongerMatches = true
+			return false
+
+		case '?':
+			s = s[1:]
+
+		case '[':
+			p = p[1:]
+			not := len(p) > 0 && p[0] == '^'
+			if not {
+				p = p[1:]
+			}
+
+			match := false
+			for {
+				if len(p) == 0 {
+					// an unterminated class ends with the pattern
+					break
+				}
+
+				if p[0] == '\\' && len(p) >= 2 {
+					p = p[1:]
+					if p[0] == s[0] {
+						match = true
+					}
+				} else if p[0] == ']' {
+					break
+				} else if len(p) >= 3 && p[1] == '-' {
+					start, end, c := p[0], p[2], s[0]
+					if start > end {
+						start, end = end, start
+					}
+					if nocase {
+						start, end, c = toLower(start), toLower(end), toLower(c)
+					}
+					p = p[2:]
+					if c >= start && c <= end {
+						match = true
+					}
+				} else if equalByte(p[0], s[0], nocase) {
+					match = true
+				}
+				p = p[1:]
+			}
+
+			if not {
+				match = !match
+			}
+			if !match {
+				return false
+			}
+			s = s[1:]
+
+			// an unterminated class has no closing bracket to skip
+			if len(p) == 0 {
+				return len(s) == 0
+			}
+
+		case '\\':
+			if len(p) >= 2 {
+				p = p[1:]
+			}
+			fallthrough
+
+		default:
+			if !equalByte(p[0], s[0], nocase) {
+				return false
+			}
+			s = s[1:]
+		}
+
+		p = p[1:]
+		if len(s) == 0 {
+			for len(p) > 0 && p[0] == '*' {
+				p = p[1:]
+			}
+			break
+		}
+	}
+
+	return len(p) == 0 && len(s) == 0
+}
+
+func equalByte(a, b byte, nocase bool) bool {
+	if nocase {
+		return toLower(a) == toLower(b)
+	}
+
+	return a == b
+}
+
+func toLower(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+
+	return c
+}
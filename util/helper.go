@@ -2,6 +2,7 @@ package util
 
 import (
 	"fmt"
+	"math"
 	"reflect"
 	"strconv"
 	"strings"
@@ -36,7 +37,7 @@ func SerializeFieldName(v any, fieldName string) (string, error) {
 			if err != nil {
 				return "", err
 			}
-			sb.WriteString(fmt.Sprintf("%s:%s", tag, str))
+			sb.WriteString(fmt.Sprintf("%s:%s\r\n", tag, str))
 		}
 	}
 
@@ -69,3 +70,80 @@ func formatFieldValue(field reflect.Value) (string, error) {
 		return "", customerror.UnsupportedFieldTypeError{Kind: field.Kind()}
 	}
 }
+
+// ParseMemory converts a size such as 512mb into bytes, k/m/g are powers of
+// 1000 while kb/mb/gb are powers of 1024 like in redis.conf
+func ParseMemory(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		mul    int64
+	}{
+		{"kb", 1024},
+		{"mb", 1024 * 1024},
+		{"gb", 1024 * 1024 * 1024},
+		{"k", 1000},
+		{"m", 1000 * 1000},
+		{"g", 1000 * 1000 * 1000},
+		{"b", 1},
+	}
+
+	s = strings.ToLower(strings.TrimSpace(s))
+	mul := int64(1)
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			s = strings.TrimSuffix(s, u.suffix)
+			mul = u.mul
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if n > math.MaxInt64/mul || n < math.MinInt64/mul {
+		return 0, &strconv.NumError{Func: "ParseMemory", Num: s, Err: strconv.ErrRange}
+	}
+
+	return n * mul, nil
+}
+
+// ParseStrictInt parses b as a 64 bit integer only when b is exactly the
+// way the integer is written, so "+1", "01" and " 1" are rejected the same
+// way Redis rejects them
+func ParseStrictInt(b []byte) (int64, bool) {
+	if len(b) == 0 || len(b) > 20 {
+		return 0, false
+	}
+
+	n, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil || strconv.FormatInt(n, 10) != string(b) {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// FormatDouble writes a float the way Redis replies with a double, in the
+// shortest form that reads back as the same float. Like %.17g it only
+// switches to an exponent when the value is very large or very small, so
+// 1000000 stays 1000000 rather than 1e+06
+func FormatDouble(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case math.IsNaN(f):
+		return "nan"
+	}
+
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	exp, _ := strconv.Atoi(s[strings.IndexByte(s, 'e')+1:])
+	if exp < -4 || exp >= 17 {
+		return s
+	}
+
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}